@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -27,6 +28,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vote"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/signer/core"
@@ -213,6 +217,105 @@ Generate ownership proof for the selected BLS account from the BLS wallet. The p
 					},
 				},
 			},
+			{
+				Name:      "journal",
+				Usage:     "Inspect and repair the vote journal",
+				ArgsUsage: "",
+				Category:  "BLS ACCOUNT COMMANDS",
+				Description: `
+
+Inspect, verify and repair the vote journal, which records the recent votes of
+the validator to avoid voting against them after a restart. The journal dir is
+"<DATADIR>/voteJournal" unless --vote-journal-path is set.
+
+The node must be stopped before running these commands.`,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "inspect",
+						Usage:     "List the entries of the vote journal",
+						Action:    blsJournalInspect,
+						ArgsUsage: "",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+						},
+						Description: `
+	geth bls journal inspect
+
+Print the source and target of every vote in the vote journal, along with the
+gaps and corrupted segments found.`,
+					},
+					{
+						Name:      "verify",
+						Usage:     "Verify the entries of the vote journal",
+						Action:    blsJournalVerify,
+						ArgsUsage: "",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+							utils.BLSPasswordFileFlag,
+						},
+						Description: `
+	geth bls journal verify
+
+Verify the signature of every vote in the vote journal, check that it's signed by
+an account of the BLS wallet and that no two votes share the same target. Exits
+with an error if any problem is found.`,
+					},
+					{
+						Name:      "truncate",
+						Usage:     "Remove the entries after the given index from the vote journal",
+						Action:    blsJournalTruncate,
+						ArgsUsage: "<index>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+						},
+						Description: `
+	geth bls journal truncate <index>
+
+Remove all the entries after <index> from the vote journal, including corrupted
+ones, so that the node can open the journal again. The entries up to <index>
+must be intact.`,
+					},
+					{
+						Name:      "export",
+						Usage:     "Export the slashing protection history of the vote journal",
+						Action:    blsJournalExport,
+						ArgsUsage: "<file>",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+						},
+						Description: `
+	geth bls journal export <file>
+
+Export the votes of the vote journal into <file> in the slashing protection
+interchange format, to be imported by the node the BLS key is migrated to.`,
+					},
+					{
+						Name:      "import",
+						Usage:     "Import a slashing protection history into the vote journal",
+						Action:    blsJournalImport,
+						ArgsUsage: "<file> [BLS pubkey]",
+						Category:  "BLS ACCOUNT COMMANDS",
+						Flags: []cli.Flag{
+							utils.DataDirFlag,
+							utils.VoteJournalDirFlag,
+						},
+						Description: `
+	geth bls journal import <file> [BLS pubkey]
+
+Import the votes in the slashing protection interchange <file> into the vote
+journal, the node will then refuse to sign any vote conflicting with them. The
+BLS pubkey must be given if the file contains the history of multiple keys.`,
+					},
+				},
+			},
 		},
 	}
 )
@@ -701,3 +804,227 @@ func blsAccountGenerateProof(ctx *cli.Context) error {
 
 	return nil
 }
+
+// blsJournalDir returns the vote journal dir configured from the command line,
+// creating it if create is set.
+func blsJournalDir(ctx *cli.Context, create bool) (string, *gethConfig) {
+	cfg := gethConfig{Node: defaultNodeConfig()}
+	// Load config file.
+	if file := ctx.String(configFileFlag.Name); file != "" {
+		if err := loadConfig(file, &cfg); err != nil {
+			utils.Fatalf("%v", err)
+		}
+	}
+	utils.SetNodeConfig(ctx, &cfg.Node)
+
+	journalDir := cfg.Node.ResolvePath(cfg.Node.VoteJournalDir)
+	if create {
+		if err := os.MkdirAll(journalDir, 0700); err != nil {
+			utils.Fatalf("Failed to create vote journal dir: %v.", err)
+		}
+	} else if _, err := os.Stat(journalDir); err != nil {
+		utils.Fatalf("Vote journal not exists: %v.", err)
+	}
+	return journalDir, &cfg
+}
+
+// blsJournalInspect prints the entries of the vote journal.
+func blsJournalInspect(ctx *cli.Context) error {
+	journalDir, _ := blsJournalDir(ctx, false)
+	report, err := vote.ScanVoteJournal(journalDir)
+	if err != nil {
+		utils.Fatalf("Scan vote journal failed: %v.", err)
+	}
+	fmt.Printf("Showing %d entries of vote journal %s\n", au.BrightYellow(len(report.Entries)), journalDir)
+	for _, entry := range report.Entries {
+		if entry.Err != nil {
+			fmt.Printf("%s %s\n", au.BrightBlue(fmt.Sprintf("[%d]", entry.Index)).Bold(), au.BrightRed(fmt.Sprintf("corrupted: %v", entry.Err)))
+			continue
+		}
+		data := entry.Vote.Data
+		fmt.Printf("%s source %d %s target %d %s voter %s\n", au.BrightBlue(fmt.Sprintf("[%d]", entry.Index)).Bold(),
+			data.SourceNumber, data.SourceHash.Hex(), data.TargetNumber, data.TargetHash.Hex(), bytesutil.Trunc(entry.Vote.VoteAddress[:]))
+	}
+	printJournalIssues(report.Issues)
+	return nil
+}
+
+// blsJournalVerify verifies the votes of the vote journal against the BLS wallet.
+func blsJournalVerify(ctx *cli.Context) error {
+	journalDir, cfg := blsJournalDir(ctx, false)
+	report, err := vote.ScanVoteJournal(journalDir)
+	if err != nil {
+		utils.Fatalf("Scan vote journal failed: %v.", err)
+	}
+
+	walletDir := filepath.Join(cfg.Node.DataDir, BLSWalletPath)
+	dirExists, err := wallet.Exists(walletDir)
+	if err != nil || !dirExists {
+		utils.Fatalf("BLS wallet not exists.")
+	}
+	walletPassword := utils.GetPassPhraseWithList("Enter the password for your BLS wallet.", false, 0, utils.MakePasswordListFromPath(ctx.String(utils.BLSPasswordFileFlag.Name)))
+	w, err := wallet.OpenWallet(context.Background(), &wallet.Config{
+		WalletDir:      walletDir,
+		WalletPassword: walletPassword,
+	})
+	if err != nil {
+		utils.Fatalf("Open BLS wallet failed: %v.", err)
+	}
+	km, err := w.InitializeKeymanager(context.Background(), iface.InitKeymanagerConfig{ListenForChanges: false})
+	if err != nil {
+		utils.Fatalf("Initialize key manager failed: %v.", err)
+	}
+	pubKeys, err := km.FetchValidatingPublicKeys(context.Background())
+	if err != nil {
+		utils.Fatalf("Could not fetch BLS public keys: %v.", err)
+	}
+	owned := make(map[types.BLSPublicKey]bool, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		owned[pubKey] = true
+	}
+
+	issues := report.Issues
+	targets := make(map[uint64]*vote.JournalEntry)
+	for _, entry := range report.Entries {
+		if entry.Err != nil {
+			continue
+		}
+		if err := entry.Vote.Verify(); err != nil {
+			issues = append(issues, fmt.Sprintf("entry %d has an invalid signature: %v", entry.Index, err))
+		}
+		if !owned[entry.Vote.VoteAddress] {
+			issues = append(issues, fmt.Sprintf("entry %d is signed by %#x which is not in the BLS wallet", entry.Index, entry.Vote.VoteAddress))
+		}
+		if prev, ok := targets[entry.Vote.Data.TargetNumber]; ok && prev.Vote.Data.Hash() != entry.Vote.Data.Hash() {
+			issues = append(issues, fmt.Sprintf("entries %d and %d vote for different targets at %d", prev.Index, entry.Index, entry.Vote.Data.TargetNumber))
+		}
+		targets[entry.Vote.Data.TargetNumber] = entry
+	}
+	printJournalIssues(issues)
+	if len(issues) > 0 {
+		utils.Fatalf("Vote journal verification failed with %d issues.", len(issues))
+	}
+	fmt.Printf("Verified %d entries of vote journal %s\n", len(report.Entries), journalDir)
+	return nil
+}
+
+// blsJournalTruncate removes the entries after the given index from the vote journal.
+func blsJournalTruncate(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("Index to truncate to must be given as argument.")
+	}
+	index, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		utils.Fatalf("Invalid index %s: %v.", ctx.Args().First(), err)
+	}
+	journalDir, _ := blsJournalDir(ctx, false)
+
+	resp, err := prompt.ValidatePrompt(os.Stdin, fmt.Sprintf("Are you sure you want to remove the entries after %d from %s? Y/N", index, journalDir), prompt.ValidateYesOrNo)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(resp, "n") {
+		return nil
+	}
+	removed, err := vote.TruncateVoteJournal(journalDir, index)
+	if err != nil {
+		utils.Fatalf("Truncate vote journal failed: %v.", err)
+	}
+	fmt.Printf("Removed %d entries from vote journal %s\n", removed, journalDir)
+	return nil
+}
+
+func printJournalIssues(issues []string) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Println("")
+	fmt.Printf("Found %d issues\n", au.BrightRed(len(issues)))
+	for _, issue := range issues {
+		fmt.Printf("%s %s\n", au.BrightRed("-"), issue)
+	}
+}
+
+// blsJournalGenesis returns the genesis hash of the local chain, which the
+// slashing protection interchange is bound to.
+func blsJournalGenesis(ctx *cli.Context) common.Hash {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true, false)
+	defer db.Close()
+
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		utils.Fatalf("Genesis block not found, initialize the node first.")
+	}
+	return genesis
+}
+
+// blsJournalExport exports the votes of the vote journal in the slashing protection interchange format.
+func blsJournalExport(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("Export file must be given as argument.")
+	}
+	journalDir, _ := blsJournalDir(ctx, false)
+	genesis := blsJournalGenesis(ctx)
+
+	journal, err := vote.NewVoteJournal(journalDir)
+	if err != nil {
+		utils.Fatalf("Open vote journal failed: %v.", err)
+	}
+	defer journal.Close()
+	interchange, err := journal.ExportInterchange(genesis)
+	if err != nil {
+		utils.Fatalf("Export vote journal failed: %v.", err)
+	}
+	blob, err := json.MarshalIndent(interchange, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(ctx.Args().First(), blob, 0600); err != nil {
+		utils.Fatalf("Write export file failed: %v.", err)
+	}
+	for _, data := range interchange.Data {
+		fmt.Printf("Exported %d votes of %#x\n", len(data.SignedVotes), bytesutil.Trunc(data.PubKey))
+	}
+	return nil
+}
+
+// blsJournalImport imports a slashing protection interchange into the vote journal.
+func blsJournalImport(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 || ctx.Args().Len() > 2 {
+		utils.Fatalf("Import file and optional BLS pubkey must be given as arguments.")
+	}
+	var pubKey *types.BLSPublicKey
+	if ctx.Args().Len() == 2 {
+		pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(ctx.Args().Get(1), "0x"))
+		if err != nil || len(pubKeyBytes) != types.BLSPublicKeyLength {
+			utils.Fatalf("Invalid BLS pubkey %s.", ctx.Args().Get(1))
+		}
+		pubKey = new(types.BLSPublicKey)
+		copy(pubKey[:], pubKeyBytes)
+	}
+	blob, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Read import file failed: %v.", err)
+	}
+	interchange := new(vote.Interchange)
+	if err := json.Unmarshal(blob, interchange); err != nil {
+		utils.Fatalf("Invalid import file: %v.", err)
+	}
+	journalDir, _ := blsJournalDir(ctx, true)
+	genesis := blsJournalGenesis(ctx)
+
+	journal, err := vote.NewVoteJournal(journalDir)
+	if err != nil {
+		utils.Fatalf("Open vote journal failed: %v.", err)
+	}
+	defer journal.Close()
+	imported, err := journal.ImportInterchange(interchange, genesis, pubKey)
+	if err != nil {
+		utils.Fatalf("Import slashing protection failed: %v.", err)
+	}
+	fmt.Printf("Imported %d votes into vote journal %s\n", imported, journalDir)
+	return nil
+}
@@ -83,6 +83,7 @@ Remove blockchain and state databases`,
 			dbDumpFreezerIndex,
 			dbImportCmd,
 			dbExportCmd,
+			dbExportDiffsCmd,
 			dbMetadataCmd,
 			ancientInspectCmd,
 			// no legacy stored receipts for bsc
@@ -267,6 +268,20 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbExportDiffsCmd = &cli.Command{
+		Action:    exportDiffLayers,
+		Name:      "export-diffs",
+		Usage:     "Exports the persisted diff layers of a block range. If the <dumpfile> has .jsonl suffix, JSON lines are written instead of RLP. If it has .gz suffix, gzip compression will be used.",
+		ArgsUsage: "<from> <to> <dumpfile>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			&utils.DiffFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `Exports the state changes (accounts, storage, codes and destructs) of the canonical
+blocks in [from, to], as persisted by a node running with --persistdiff. Accounts and storage
+slots are keyed by their hashes, the JSON lines only include the addresses and the slot keys
+if the node recorded the preimages (--cache.preimages).`,
+	}
 	dbMetadataCmd = &cli.Command{
 		Action: showMetaData,
 		Name:   "metadata",
@@ -1188,6 +1203,59 @@ func exportChaindata(ctx *cli.Context) error {
 	return utils.ExportChaindata(ctx.Args().Get(1), kind, exporter(db), stop)
 }
 
+func exportDiffLayers(ctx *cli.Context) error {
+	if ctx.NArg() != 3 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	first, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid 'from' block number: %v", err)
+	}
+	last, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid 'to' block number: %v", err)
+	}
+	if first > last {
+		return fmt.Errorf("invalid block range [%d, %d]", first, last)
+	}
+	var (
+		stack, _  = makeConfigNode(ctx)
+		interrupt = make(chan os.Signal, 1)
+		stop      = make(chan struct{})
+	)
+	defer stack.Close()
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	defer close(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info("Interrupted during diff export, stopping at next block")
+		}
+		close(stop)
+	}()
+	db := utils.MakeChainDatabase(ctx, stack, true, false)
+	defer db.Close()
+
+	diffStore, err := stack.OpenDiffDatabase("chaindata", utils.MakeDatabaseHandles(ctx.Int(utils.FDLimitFlag.Name))/5, ctx.String(utils.DiffFlag.Name), "", true)
+	if err != nil {
+		return fmt.Errorf("failed to open diff store: %v", err)
+	}
+	defer diffStore.Close()
+
+	if head := rawdb.ReadHeadBlockHash(db); head != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, head); number != nil && *number < last {
+			log.Warn("Last block beyond head, setting last = head", "head", *number, "last", last)
+			last = *number
+		}
+	}
+	// The preimages are stored along with the state
+	preimages := ethdb.KeyValueReader(db)
+	if stateStore := db.StateStore(); stateStore != nil {
+		preimages = stateStore
+	}
+	return utils.ExportDiffLayers(db, diffStore, preimages, ctx.Args().Get(2), first, last, stop)
+}
+
 func showMetaData(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
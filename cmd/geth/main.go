@@ -65,6 +65,8 @@ var (
 		utils.DirectBroadcastFlag,
 		utils.DisableSnapProtocolFlag,
 		utils.EnableTrustProtocolFlag,
+		utils.EnableDiffProtocolFlag,
+		utils.DiffSyncFlag,
 		utils.PipeCommitFlag,
 		utils.RangeLimitFlag,
 		utils.USBFlag,
@@ -90,11 +92,17 @@ var (
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.TxPoolReannounceTimeFlag,
+		utils.BundlePoolEnabledFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
 		utils.SyncModeFlag,
 		utils.TriesVerifyModeFlag,
+		utils.TriesVerifyQuorumFlag,
+		utils.TriesVerifyPeersFlag,
+		utils.TriesVerifyProofsFlag,
+		utils.TriesVerifyAllowlistFlag,
+		utils.TriesVerifySignatureFlag,
 		// utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
@@ -102,6 +110,8 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.StateHistoryFlag,
+		utils.BodyHistoryFlag,
+		utils.ReceiptHistoryFlag,
 		utils.PathDBSyncFlag,
 		utils.JournalFileFlag,
 		utils.LightServeFlag,       // deprecated
@@ -169,15 +179,22 @@ var (
 		utils.BlockAmountReserved,
 		utils.CheckSnapshotWithMPT,
 		utils.EnableDoubleSignMonitorFlag,
+		utils.DoubleSignMonitorRetentionFlag,
+		utils.DoubleSignEvidenceSubmitterFlag,
 		utils.VotingEnabledFlag,
 		utils.DisableVoteAttestationFlag,
 		utils.EnableMaliciousVoteMonitorFlag,
 		utils.BLSPasswordFileFlag,
 		utils.BLSWalletDirFlag,
+		utils.BLSRemoteSignerFlag,
+		utils.BLSRemoteSignerPubKeyFlag,
 		utils.VoteJournalDirFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
 		utils.BlobExtraReserveFlag,
+		utils.ParliaSnapshotFileFlag,
+		utils.ParliaSnapshotSignerFlag,
+		utils.ParliaSnapshotInsecureFlag,
 	}, utils.NetworkFlags, utils.DatabaseFlags)
 
 	rpcFlags = []cli.Flag{
@@ -270,6 +287,8 @@ func init() {
 		blsCommand,
 		// See verkle.go
 		verkleCommand,
+		// See mevcmd.go
+		mevCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
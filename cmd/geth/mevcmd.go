@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	mevCommand = &cli.Command{
+		Name:  "mev",
+		Usage: "A set of commands for the mev of the validator",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "replay",
+				Usage:     "Replay the recorded bids through the bid simulator",
+				ArgsUsage: "<bids.json>",
+				Action:    replayBids,
+				Flags:     flags.Merge([]cli.Flag{configFileFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth mev replay <bids.json>
+This command replays the recorded bids on top of the local chain and prints the
+best bid of every block along with the outcome of each bid in JSON.
+
+The file holds a JSON array of the recorded bids:
+  [{"arrival": 1200, "simulationTime": 150, "bid": {<args of mev_sendBid>}}, ...]
+where arrival is the time in milliseconds the bid arrives after the parent block
+timestamp and simulationTime is the time in milliseconds its simulation takes.
+The bids are simulated one by one on a virtual clock, so the outcome only depends
+on the chain, the recorded bids and the [Eth.Miner] section of the config file.
+`,
+			},
+		},
+	}
+)
+
+func replayBids(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	blob, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to read bids: %v", err)
+	}
+	var bids []*miner.ReplayBid
+	if err := json.Unmarshal(blob, &bids); err != nil {
+		return fmt.Errorf("failed to decode bids: %v", err)
+	}
+
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	results, err := miner.ReplayBids(chain, &cfg.Eth.Miner, bids)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
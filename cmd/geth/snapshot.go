@@ -18,11 +18,13 @@ package main
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/parlia"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -218,6 +221,42 @@ block is used.
 				Description: `
 The export-preimages command exports hash preimages to a flat file, in exactly
 the expected order for the overlay tree migration.
+`,
+			},
+			{
+				Action:    parliaExport,
+				Name:      "parlia-export",
+				Usage:     "Export the parlia snapshot at a given block",
+				ArgsUsage: "<dumpfile> [<blockHash> | <blockNum>]",
+				Flags: flags.Merge([]cli.Flag{
+					utils.ParliaSnapshotSignKeyFlag,
+				}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot parlia-export <dumpfile> [<blockHash> | <blockNum>]
+exports the parlia consensus snapshot (validators, BLS vote addresses, recents and
+attestation) at the given block, the latest block if none is provided, together with
+its checksum. The checksum is signed if --parlia.snapshotsignkey is specified.
+
+The file can be loaded into another node with 'geth snapshot parlia-import' or at
+startup with --parlia.snapshotfile.
+`,
+			},
+			{
+				Action:    parliaImport,
+				Name:      "parlia-import",
+				Usage:     "Import a parlia snapshot into the database",
+				ArgsUsage: "<dumpfile>",
+				Flags: flags.Merge([]cli.Flag{
+					utils.ParliaSnapshotSignerFlag,
+					utils.ParliaSnapshotInsecureFlag,
+				}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth snapshot parlia-import <dumpfile>
+verifies the checksum of a snapshot file exported by 'geth snapshot parlia-export'
+and its signature by --parlia.snapshotsigner, then stores the snapshot into the
+database. Unsigned files are only imported with --parlia.snapshotinsecure. Only
+snapshots of checkpoint blocks (multiple of 1024) can be imported, use
+--parlia.snapshotfile for the other ones.
 `,
 			},
 		},
@@ -1006,3 +1045,76 @@ func checkAccount(ctx *cli.Context) error {
 	log.Info("Checked the snapshot journalled storage", "time", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// parliaExport exports the parlia snapshot at the given block to a file.
+func parliaExport(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return errors.New("need <dumpfile> [<blockHash> | <blockNum>] args")
+	}
+	var key *ecdsa.PrivateKey
+	if ctx.IsSet(utils.ParliaSnapshotSignKeyFlag.Name) {
+		var err error
+		if key, err = crypto.LoadECDSA(ctx.String(utils.ParliaSnapshotSignKeyFlag.Name)); err != nil {
+			return fmt.Errorf("failed to load sign key: %v", err)
+		}
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	engine, ok := chain.Engine().(*parlia.Parlia)
+	if !ok {
+		return errors.New("the chain is not running parlia")
+	}
+	header := chain.CurrentHeader()
+	if ctx.NArg() > 1 {
+		arg := ctx.Args().Get(1)
+		if hashish(arg) {
+			header = chain.GetHeaderByHash(common.HexToHash(arg))
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return err
+			}
+			header = chain.GetHeaderByNumber(number)
+		}
+	}
+	if header == nil {
+		return errors.New("block not found")
+	}
+	file, err := engine.ExportSnapshot(chain, header, key)
+	if err != nil {
+		return err
+	}
+	if err := parlia.WriteSnapshotFile(ctx.Args().First(), file); err != nil {
+		return err
+	}
+	log.Info("Exported parlia snapshot", "number", header.Number, "hash", header.Hash(), "checksum", file.Checksum, "signer", file.Signer)
+	return nil
+}
+
+// parliaImport verifies a parlia snapshot file and stores it into the database.
+func parliaImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need <dumpfile> arg")
+	}
+	file, err := parlia.ReadSnapshotFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false, false)
+	defer db.Close()
+
+	snap, err := parlia.StoreSnapshotFile(db, file, utils.MakeParliaSnapshotSigner(ctx), ctx.Bool(utils.ParliaSnapshotInsecureFlag.Name))
+	if err != nil {
+		return err
+	}
+	log.Info("Imported parlia snapshot", "number", snap.Number, "hash", snap.Hash, "signer", file.Signer)
+	return nil
+}
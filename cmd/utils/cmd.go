@@ -864,3 +864,78 @@ func ExportChaindata(fn string, kind string, iter ChainDataIterator, interrupt c
 		"elapsed", common.PrettyDuration(time.Since(start)))
 	return nil
 }
+
+// ExportDiffLayers exports the persisted diff layers of the canonical blocks
+// in [first, last] into the specified file. Files with a .jsonl suffix (before
+// an optional .gz) get one JSON encoded diff layer per line, all others get a
+// stream of RLP encoded diff layers. Blocks without a diff layer, e.g. the
+// ones without transactions, are skipped. The JSON diff layers include the
+// addresses and the slot keys found in the preimage store.
+func ExportDiffLayers(db ethdb.Reader, diffStore ethdb.KeyValueReader, preimages ethdb.KeyValueReader, fn string, first, last uint64, interrupt chan struct{}) error {
+	log.Info("Exporting diff layers", "file", fn, "first", first, "last", last)
+
+	// Open the file handle and potentially wrap with a gzip stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var writer io.Writer = fh
+	if strings.HasSuffix(fn, ".gz") {
+		writer = gzip.NewWriter(writer)
+		defer writer.(*gzip.Writer).Close()
+	}
+	var (
+		asJSON   = strings.HasSuffix(strings.TrimSuffix(fn, ".gz"), ".jsonl")
+		preimage = func(hash common.Hash) []byte { return rawdb.ReadPreimage(preimages, hash) }
+		count    int64
+		missing  int64
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	for number := first; number <= last; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("canonical hash of block #%d not found", number)
+		}
+		blob := rawdb.ReadDiffLayerRLP(diffStore, hash)
+		if len(blob) == 0 {
+			missing++
+		} else if asJSON {
+			diff := new(types.DiffLayer)
+			if err := rlp.DecodeBytes(blob, diff); err != nil {
+				return fmt.Errorf("invalid diff layer of block #%d: %v", number, err)
+			}
+			enc, err := diff.MarshalJSONWithPreimages(preimage)
+			if err != nil {
+				return fmt.Errorf("invalid diff layer of block #%d: %v", number, err)
+			}
+			if _, err := writer.Write(append(enc, '\n')); err != nil {
+				return err
+			}
+			count++
+		} else {
+			if _, err := writer.Write(blob); err != nil {
+				return err
+			}
+			count++
+		}
+		// Check interruption emitted by ctrl+c
+		select {
+		case <-interrupt:
+			log.Info("Diff layer exporting interrupted", "file", fn, "number", number,
+				"count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			return nil
+		default:
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting diff layers", "file", fn, "number", number,
+				"count", count, "missing", missing, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Exported diff layers", "file", fn, "count", count, "missing", missing,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/monitor"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -114,6 +115,16 @@ var (
 		Usage:    "Enable trust protocol",
 		Category: flags.FastNodeCategory,
 	}
+	EnableDiffProtocolFlag = &cli.BoolFlag{
+		Name:     "enablediffprotocol",
+		Usage:    "Enable diff protocol to serve the diff layers of the blocks to the peers",
+		Category: flags.FastNodeCategory,
+	}
+	DiffSyncFlag = &cli.BoolFlag{
+		Name:     "diffsync",
+		Usage:    "Import the blocks by applying the diff layers fetched over diff protocol instead of executing them, requires the full verify mode",
+		Category: flags.FastNodeCategory,
+	}
 	PipeCommitFlag = &cli.BoolFlag{
 		Name:     "pipecommit",
 		Usage:    "Enable MPT pipeline commit, it will improve syncing performance. It is an experimental feature(default is false)",
@@ -300,6 +311,34 @@ var (
 		Value:    &defaultVerifyMode,
 		Category: flags.FastNodeCategory,
 	}
+	TriesVerifyQuorumFlag = &cli.IntFlag{
+		Name:     "tries-verify-quorum",
+		Usage:    "Number of distinct verify nodes that must attest the state root of a block in full and insecure verify mode",
+		Value:    ethconfig.Defaults.TriesVerify.Quorum,
+		Category: flags.FastNodeCategory,
+	}
+	TriesVerifyPeersFlag = &cli.IntFlag{
+		Name:     "tries-verify-peers",
+		Usage:    "Number of verify nodes the state root of a block is requested from at first",
+		Value:    ethconfig.Defaults.TriesVerify.Peers,
+		Category: flags.FastNodeCategory,
+	}
+	TriesVerifyProofsFlag = &cli.IntFlag{
+		Name:     "tries-verify-proofs",
+		Usage:    "Number of accounts of each block spot-checked with the state proofs of the trust/2 verify nodes (0 = disabled)",
+		Value:    ethconfig.Defaults.TriesVerify.Proofs,
+		Category: flags.FastNodeCategory,
+	}
+	TriesVerifyAllowlistFlag = &cli.StringFlag{
+		Name:     "tries-verify-allowlist",
+		Usage:    "Comma separated node IDs of the verify nodes allowed to attest the state roots (default = any verify node)",
+		Category: flags.FastNodeCategory,
+	}
+	TriesVerifySignatureFlag = &cli.BoolFlag{
+		Name:     "tries-verify-require-signature",
+		Usage:    "Discard the state roots not signed by the node key of the verify node in full verify mode",
+		Category: flags.FastNodeCategory,
+	}
 	RialtoHash = &cli.StringFlag{
 		Name:     "rialtohash",
 		Usage:    "Manually specify the Rialto Genesis Hash, to trigger builtin network logic",
@@ -383,6 +422,16 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	BodyHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.bodies",
+		Usage:    "Number of recent blocks to keep block bodies for in the ancient store, with --pruneancient the headers are then kept for the whole history (default = 0, entire chain)",
+		Category: flags.StateCategory,
+	}
+	ReceiptHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.receipts",
+		Usage:    "Number of recent blocks to keep receipts for in the ancient store, with --pruneancient the headers are then kept for the whole history (default = 0, entire chain)",
+		Category: flags.StateCategory,
+	}
 	// Transaction pool settings
 	TxPoolLocalsFlag = &cli.StringFlag{
 		Name:     "txpool.locals",
@@ -454,6 +503,11 @@ var (
 		Value:    ethconfig.Defaults.TxPool.ReannounceTime,
 		Category: flags.TxPoolCategory,
 	}
+	BundlePoolEnabledFlag = &cli.BoolFlag{
+		Name:     "bundlepool.enable",
+		Usage:    "Enable the bundle pool and the eth_sendBundle and eth_callBundle APIs",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -1094,6 +1148,19 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.MinerCategory,
 	}
 
+	DoubleSignMonitorRetentionFlag = &cli.Uint64Flag{
+		Name:     "monitor.doublesign.retention",
+		Usage:    "Number of recent heights whose headers, including those of competing forks, are kept by the double sign monitor",
+		Value:    monitor.MaxCacheHeader,
+		Category: flags.MinerCategory,
+	}
+
+	DoubleSignEvidenceSubmitterFlag = &cli.StringFlag{
+		Name:     "monitor.doublesign.submitter",
+		Usage:    "Unlocked local account used to submit the double sign evidence to the SlashIndicator contract (requires --monitor.doublesign)",
+		Category: flags.MinerCategory,
+	}
+
 	VotingEnabledFlag = &cli.BoolFlag{
 		Name:     "vote",
 		Usage:    "Enable voting when mining",
@@ -1124,12 +1191,46 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.AccountCategory,
 	}
 
+	BLSRemoteSignerFlag = &cli.StringFlag{
+		Name:     "blsremotesigner",
+		Usage:    "URL of a Web3Signer compatible remote signer holding the BLS key, used instead of the BLS wallet to sign votes",
+		Category: flags.AccountCategory,
+	}
+
+	BLSRemoteSignerPubKeyFlag = &cli.StringFlag{
+		Name:     "blsremotesigner.pubkey",
+		Usage:    "BLS public key to sign votes with on the remote signer (default = the first key served)",
+		Category: flags.AccountCategory,
+	}
+
 	VoteJournalDirFlag = &flags.DirectoryFlag{
 		Name:     "vote-journal-path",
 		Usage:    "Path for the voteJournal dir in fast finality feature (default = inside the datadir)",
 		Category: flags.FastFinalityCategory,
 	}
 
+	// Parlia snapshot settings
+	ParliaSnapshotFileFlag = &cli.StringFlag{
+		Name:     "parlia.snapshotfile",
+		Usage:    "Parlia snapshot file (exported by 'geth snapshot parlia-export') used to seed the snapshot cache at startup",
+		Category: flags.EthCategory,
+	}
+	ParliaSnapshotSignerFlag = &cli.StringFlag{
+		Name:     "parlia.snapshotsigner",
+		Usage:    "Address which must have signed the parlia snapshot file",
+		Category: flags.EthCategory,
+	}
+	ParliaSnapshotInsecureFlag = &cli.BoolFlag{
+		Name:     "parlia.snapshotinsecure",
+		Usage:    "Accept parlia snapshot files without --parlia.snapshotsigner, unsigned files are trusted blindly (insecure)",
+		Category: flags.EthCategory,
+	}
+	ParliaSnapshotSignKeyFlag = &cli.StringFlag{
+		Name:     "parlia.snapshotsignkey",
+		Usage:    "Private key file used to sign the checksum of the exported parlia snapshot",
+		Category: flags.EthCategory,
+	}
+
 	// Blob setting
 	BlobExtraReserveFlag = &cli.Uint64Flag{
 		Name:     "blob.extra-reserve",
@@ -1430,6 +1531,16 @@ func setMonitors(ctx *cli.Context, cfg *node.Config) {
 	if ctx.Bool(EnableDoubleSignMonitorFlag.Name) {
 		cfg.EnableDoubleSignMonitor = true
 	}
+	if ctx.IsSet(DoubleSignMonitorRetentionFlag.Name) {
+		cfg.DoubleSignMonitorRetention = ctx.Uint64(DoubleSignMonitorRetentionFlag.Name)
+	}
+	if ctx.IsSet(DoubleSignEvidenceSubmitterFlag.Name) {
+		submitter := strings.TrimSpace(ctx.String(DoubleSignEvidenceSubmitterFlag.Name))
+		if !common.IsHexAddress(submitter) {
+			Fatalf("Invalid double sign evidence submitter %q", submitter)
+		}
+		cfg.DoubleSignEvidenceSubmitter = submitter
+	}
 	if ctx.Bool(EnableMaliciousVoteMonitorFlag.Name) {
 		cfg.EnableMaliciousVoteMonitor = true
 	}
@@ -1637,6 +1748,12 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BLSPasswordFileFlag.Name) {
 		cfg.BLSPasswordFile = ctx.String(BLSPasswordFileFlag.Name)
 	}
+	if ctx.IsSet(BLSRemoteSignerFlag.Name) {
+		cfg.BLSRemoteSigner = ctx.String(BLSRemoteSignerFlag.Name)
+	}
+	if ctx.IsSet(BLSRemoteSignerPubKeyFlag.Name) {
+		cfg.BLSRemoteSignerPubKey = ctx.String(BLSRemoteSignerPubKeyFlag.Name)
+	}
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
 		if dbEngine != "leveldb" && dbEngine != "pebble" {
@@ -1868,6 +1985,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	if ctx.IsSet(BundlePoolEnabledFlag.Name) {
+		cfg.BundlePool.Enabled = ctx.Bool(BundlePoolEnabledFlag.Name)
+	}
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1987,6 +2107,19 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		cfg.StateScheme = rawdb.HashScheme
 		log.Warn("Forcing hash state-scheme for archive mode")
 	}
+	if ctx.IsSet(BodyHistoryFlag.Name) {
+		cfg.BodyHistory = ctx.Uint64(BodyHistoryFlag.Name)
+	}
+	if ctx.IsSet(ReceiptHistoryFlag.Name) {
+		cfg.ReceiptHistory = ctx.Uint64(ReceiptHistoryFlag.Name)
+	}
+	if cfg.BodyHistory != 0 || cfg.ReceiptHistory != 0 {
+		// The transactions can't be indexed without the bodies
+		if cfg.BodyHistory != 0 && (cfg.TransactionHistory == 0 || cfg.TransactionHistory > cfg.BodyHistory) {
+			log.Warn("Limiting transaction index to the retained bodies", "history.transactions", cfg.TransactionHistory, "history.bodies", cfg.BodyHistory)
+			cfg.TransactionHistory = cfg.BodyHistory
+		}
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheTrieFlag.Name) / 100
 	}
@@ -2014,6 +2147,39 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			cfg.SyncMode = downloader.FullSync
 		}
 	}
+	if ctx.IsSet(EnableDiffProtocolFlag.Name) {
+		cfg.EnableDiffProtocol = ctx.Bool(EnableDiffProtocolFlag.Name)
+	}
+	if ctx.IsSet(DiffSyncFlag.Name) {
+		cfg.DiffSync = ctx.Bool(DiffSyncFlag.Name)
+		// The state root of the blocks applied from the diff layers can't be
+		// verified locally, so the verify nodes must check it with the diff hash.
+		if cfg.DiffSync && cfg.TriesVerifyMode != core.FullVerify {
+			Fatalf("Flag --%s requires --%s full", DiffSyncFlag.Name, TriesVerifyModeFlag.Name)
+		}
+	}
+	if ctx.IsSet(TriesVerifyQuorumFlag.Name) {
+		cfg.TriesVerify.Quorum = ctx.Int(TriesVerifyQuorumFlag.Name)
+	}
+	if ctx.IsSet(TriesVerifyPeersFlag.Name) {
+		cfg.TriesVerify.Peers = ctx.Int(TriesVerifyPeersFlag.Name)
+	}
+	if ctx.IsSet(TriesVerifyProofsFlag.Name) {
+		cfg.TriesVerify.Proofs = ctx.Int(TriesVerifyProofsFlag.Name)
+	}
+	if ctx.IsSet(TriesVerifyAllowlistFlag.Name) {
+		cfg.TriesVerify.Allowlist = nil
+		for _, entry := range SplitAndTrim(ctx.String(TriesVerifyAllowlistFlag.Name)) {
+			id, err := enode.ParseID(entry)
+			if err != nil {
+				Fatalf("Invalid node ID in %s: %v", TriesVerifyAllowlistFlag.Name, err)
+			}
+			cfg.TriesVerify.Allowlist = append(cfg.TriesVerify.Allowlist, id.String())
+		}
+	}
+	if ctx.IsSet(TriesVerifySignatureFlag.Name) {
+		cfg.TriesVerify.RequireSignature = ctx.Bool(TriesVerifySignatureFlag.Name)
+	}
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheSnapshotFlag.Name) {
 		cfg.SnapshotCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheSnapshotFlag.Name) / 100
 	}
@@ -2178,6 +2344,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		Fatalf("Failed to set KZG library implementation to %s: %v", ctx.String(CryptoKZGFlag.Name), err)
 	}
 
+	// parlia snapshot setting
+	if ctx.IsSet(ParliaSnapshotFileFlag.Name) {
+		cfg.ParliaSnapshotFile = ctx.String(ParliaSnapshotFileFlag.Name)
+	}
+	if signer := MakeParliaSnapshotSigner(ctx); signer != nil {
+		cfg.ParliaSnapshotSigner = signer
+	}
+	if ctx.IsSet(ParliaSnapshotInsecureFlag.Name) {
+		cfg.ParliaSnapshotInsecure = ctx.Bool(ParliaSnapshotInsecureFlag.Name)
+	}
+	if cfg.ParliaSnapshotFile != "" && cfg.ParliaSnapshotSigner == nil && !cfg.ParliaSnapshotInsecure {
+		Fatalf("--%s requires --%s, or --%s to accept unsigned files", ParliaSnapshotFileFlag.Name,
+			ParliaSnapshotSignerFlag.Name, ParliaSnapshotInsecureFlag.Name)
+	}
+
 	// blob setting
 	if ctx.IsSet(OverrideDefaultExtraReserveForBlobRequests.Name) {
 		cfg.BlobExtraReserve = ctx.Uint64(OverrideDefaultExtraReserveForBlobRequests.Name)
@@ -2191,6 +2372,19 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	}
 }
 
+// MakeParliaSnapshotSigner returns the trusted signer of parlia snapshot files, nil if not set.
+func MakeParliaSnapshotSigner(ctx *cli.Context) *common.Address {
+	if !ctx.IsSet(ParliaSnapshotSignerFlag.Name) {
+		return nil
+	}
+	signer := ctx.String(ParliaSnapshotSignerFlag.Name)
+	if !common.IsHexAddress(signer) {
+		Fatalf("Invalid parlia snapshot signer %q", signer)
+	}
+	addr := common.HexToAddress(signer)
+	return &addr
+}
+
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
 // no URLs are set.
 func SetDNSDiscoveryDefaults(cfg *ethconfig.Config, genesis common.Hash) {
@@ -17,6 +17,11 @@
 package parlia
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/willf/bitset"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -86,3 +91,281 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 	}
 	return snap.validators(), nil
 }
+
+const (
+	// maxQueryRange is the maximum number of blocks a single range query may cover.
+	maxQueryRange = 100000
+
+	// maxReportRange is the maximum number of blocks a report replaying the
+	// snapshots may cover, every block costs a header read and a snapshot copy.
+	maxReportRange = 10 * defaultEpochLength
+)
+
+// ValidatorPerformance is the block production record of a single validator.
+type ValidatorPerformance struct {
+	InTurnBlocks      uint64  `json:"inTurnBlocks"`      // blocks produced in-turn
+	OutOfTurnBlocks   uint64  `json:"outOfTurnBlocks"`   // blocks produced out-of-turn
+	MissedTurns       uint64  `json:"missedTurns"`       // in-turn slots produced by another validator
+	AvgBackOffDelay   float64 `json:"avgBackOffDelay"`   // average delay (seconds) actually used for out-of-turn blocks
+	AvgBackOffTime    float64 `json:"avgBackOffTime"`    // average backOffTime (seconds) expected for out-of-turn blocks
+	RecentsViolations uint64  `json:"recentsViolations"` // blocks produced while still in the recents window
+
+	backOffDelaySum    uint64
+	backOffTimeSum     uint64
+	outOfTurnWithDelay uint64
+}
+
+// PerformanceReport is the validators performance over a block range.
+type PerformanceReport struct {
+	From       uint64                                   `json:"from"`
+	To         uint64                                   `json:"to"`
+	Validators map[common.Address]*ValidatorPerformance `json:"validators"`
+}
+
+// addBlock accounts the given block, signed by signer on top of snap.
+func (r *PerformanceReport) addBlock(snap *Snapshot, parent, header *types.Header, signer common.Address, period, backOffTime uint64) {
+	stats := r.validator(signer)
+	if snap.SignRecently(signer) {
+		stats.RecentsViolations++
+	}
+	inturn := snap.inturnValidator()
+	if inturn == signer {
+		stats.InTurnBlocks++
+		return
+	}
+	r.validator(inturn).MissedTurns++
+	stats.OutOfTurnBlocks++
+	stats.backOffTimeSum += backOffTime
+	if header.Time >= parent.Time+period {
+		stats.backOffDelaySum += header.Time - parent.Time - period
+		stats.outOfTurnWithDelay++
+	}
+}
+
+func (r *PerformanceReport) validator(val common.Address) *ValidatorPerformance {
+	stats, ok := r.Validators[val]
+	if !ok {
+		stats = new(ValidatorPerformance)
+		r.Validators[val] = stats
+	}
+	return stats
+}
+
+// finalize computes the averages once all the blocks are accounted.
+func (r *PerformanceReport) finalize() {
+	for _, stats := range r.Validators {
+		if stats.OutOfTurnBlocks > 0 {
+			stats.AvgBackOffTime = float64(stats.backOffTimeSum) / float64(stats.OutOfTurnBlocks)
+		}
+		if stats.outOfTurnWithDelay > 0 {
+			stats.AvgBackOffDelay = float64(stats.backOffDelaySum) / float64(stats.outOfTurnWithDelay)
+		}
+	}
+}
+
+// GetValidatorPerformance reports, for every validator, the blocks produced in-turn
+// and out-of-turn, the in-turn slots missed, the backoff delay used versus the expected
+// backOffTime and the recents window violations over the given block range.
+func (api *API) GetValidatorPerformance(ctx context.Context, from, to rpc.BlockNumber) (*PerformanceReport, error) {
+	start, end, err := api.blockRange(from, to, maxReportRange)
+	if err != nil {
+		return nil, err
+	}
+	if start == 0 {
+		start = 1 // genesis is not signed
+	}
+	parent := api.chain.GetHeaderByNumber(start - 1)
+	if parent == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.parlia.snapshot(api.chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	report := &PerformanceReport{
+		From:       start,
+		To:         end,
+		Validators: make(map[common.Address]*ValidatorPerformance),
+	}
+	for number := start; number <= end; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		signer, err := ecrecover(header, api.parlia.signatures, api.parlia.chainConfig.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		report.addBlock(snap, parent, header, signer, api.parlia.config.Period, api.parlia.backOffTime(snap, header, signer))
+
+		if snap, err = snap.apply([]*types.Header{header}, api.chain, nil, api.parlia.chainConfig, nil); err != nil {
+			return nil, err
+		}
+		parent = header
+	}
+	report.finalize()
+	return report, nil
+}
+
+// blockRange resolves the given block numbers into an ordered range of at most
+// limit blocks.
+func (api *API) blockRange(from, to rpc.BlockNumber, limit uint64) (uint64, uint64, error) {
+	resolve := func(number rpc.BlockNumber) (uint64, error) {
+		if number == rpc.LatestBlockNumber {
+			return api.chain.CurrentHeader().Number.Uint64(), nil
+		}
+		if number < 0 {
+			return 0, fmt.Errorf("unsupported block number %d", number)
+		}
+		return uint64(number), nil
+	}
+	start, err := resolve(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := resolve(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid block range %d > %d", start, end)
+	}
+	if end-start >= limit {
+		return 0, 0, fmt.Errorf("block range too large, max %d blocks", limit)
+	}
+	return start, end, nil
+}
+
+// VoteParticipation is the fast finality voting record of a single validator.
+type VoteParticipation struct {
+	VoteAddress   types.BLSPublicKey `json:"voteAddress"`
+	Eligible      uint64             `json:"eligible"`      // attestations the validator could have been part of
+	Included      uint64             `json:"included"`      // attestations the validator's vote was included in
+	InclusionRate float64            `json:"inclusionRate"` // included / eligible
+}
+
+// VoteParticipationReport is the fast finality voting statistics over a block range.
+type VoteParticipationReport struct {
+	From                uint64                                `json:"from"`
+	To                  uint64                                `json:"to"`
+	Validators          map[common.Address]*VoteParticipation `json:"validators"`
+	FinalityLag         map[uint64]uint64                     `json:"finalityLag"`         // head minus finalized number -> block count
+	NoAttestationBlocks []uint64                              `json:"noAttestationBlocks"` // blocks without vote attestation
+}
+
+// addBlock accounts the attestation of the given header. voteSnap is the snapshot
+// the attestation bitset refers to, headSnap is the snapshot at header.
+func (r *VoteParticipationReport) addBlock(voteSnap, headSnap *Snapshot, header *types.Header, attestation *types.VoteAttestation) {
+	number := header.Number.Uint64()
+	if headSnap.Attestation != nil && number >= headSnap.Attestation.SourceNumber {
+		r.FinalityLag[number-headSnap.Attestation.SourceNumber]++
+	}
+	if attestation == nil {
+		r.NoAttestationBlocks = append(r.NoAttestationBlocks, number)
+		return
+	}
+	validatorsBitSet := bitset.From([]uint64{uint64(attestation.VoteAddressSet)})
+	for index, val := range voteSnap.validators() {
+		stats, ok := r.Validators[val]
+		if !ok {
+			stats = &VoteParticipation{VoteAddress: voteSnap.Validators[val].VoteAddress}
+			r.Validators[val] = stats
+		}
+		stats.Eligible++
+		if validatorsBitSet.Test(uint(index)) {
+			stats.Included++
+		}
+	}
+}
+
+// finalize computes the inclusion rates once all the blocks are accounted.
+func (r *VoteParticipationReport) finalize() {
+	for _, stats := range r.Validators {
+		if stats.Eligible > 0 {
+			stats.InclusionRate = float64(stats.Included) / float64(stats.Eligible)
+		}
+	}
+}
+
+// GetVoteParticipation decodes the vote attestations over the given block range and
+// reports the per validator inclusion rate, the finality lag histogram and the blocks
+// which had no attestation. Blocks before the Luban fork are skipped.
+func (api *API) GetVoteParticipation(ctx context.Context, from, to rpc.BlockNumber) (*VoteParticipationReport, error) {
+	start, end, err := api.blockRange(from, to, maxReportRange)
+	if err != nil {
+		return nil, err
+	}
+	// The attestation of block N is checked against the snapshot at N-2.
+	if start < 2 {
+		start = 2
+	}
+	report := &VoteParticipationReport{
+		From:        start,
+		To:          end,
+		Validators:  make(map[common.Address]*VoteParticipation),
+		FinalityLag: make(map[uint64]uint64),
+	}
+	if start > end {
+		return report, nil
+	}
+	grandParent := api.chain.GetHeaderByNumber(start - 2)
+	parent := api.chain.GetHeaderByNumber(start - 1)
+	if grandParent == nil || parent == nil {
+		return nil, errUnknownBlock
+	}
+	voteSnap, err := api.parlia.snapshot(api.chain, grandParent.Number.Uint64(), grandParent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	parentSnap, err := voteSnap.apply([]*types.Header{parent}, api.chain, nil, api.parlia.chainConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	for number := start; number <= end; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		headSnap, err := parentSnap.apply([]*types.Header{header}, api.chain, nil, api.parlia.chainConfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		if api.parlia.chainConfig.IsLuban(header.Number) {
+			attestation, err := getVoteAttestationFromHeader(header, api.parlia.chainConfig, api.parlia.config)
+			if err != nil {
+				return nil, err
+			}
+			report.addBlock(voteSnap, headSnap, header, attestation)
+		}
+		voteSnap, parentSnap = parentSnap, headSnap
+	}
+	report.finalize()
+	return report, nil
+}
+
+// GetValidatorSetHistory returns the canonical validator set transitions in the given
+// block range. Transitions are recorded as blocks are verified, so the ones the node
+// never processed (e.g. before the history was enabled) are missing.
+func (api *API) GetValidatorSetHistory(from, to rpc.BlockNumber) ([]*ValidatorSetTransition, error) {
+	start, end, err := api.blockRange(from, to, maxQueryRange)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := loadValidatorSetTransitions(api.parlia.db, start, end)
+	if err != nil {
+		return nil, err
+	}
+	canonical := make([]*ValidatorSetTransition, 0, len(transitions))
+	for _, transition := range transitions {
+		if header := api.chain.GetHeaderByNumber(transition.Number); header != nil && header.Hash() == transition.Hash {
+			canonical = append(canonical, transition)
+		}
+	}
+	return canonical, nil
+}
@@ -0,0 +1,120 @@
+package parlia
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestPerformanceReport(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+	snap := newSnapshot(&params.ParliaConfig{Period: 3, Epoch: 200}, nil, 0, common.Hash{}, validators, nil, nil)
+	report := &PerformanceReport{Validators: make(map[common.Address]*ValidatorPerformance)}
+
+	// block 1 is validators[1]'s turn
+	parent := &types.Header{Number: big.NewInt(0), Time: 100}
+	header := &types.Header{Number: big.NewInt(1), Time: 103}
+	report.addBlock(snap, parent, header, validators[1], 3, 0)
+	snap.Recents[1] = validators[1]
+	snap.Number = 1
+
+	// block 2 is validators[2]'s turn, but produced by validators[0] after 2s of backoff
+	parent, header = header, &types.Header{Number: big.NewInt(2), Time: 108}
+	report.addBlock(snap, parent, header, validators[0], 3, 1)
+	snap.Recents[2] = validators[0]
+	snap.Number = 2
+
+	// block 3 is validators[0]'s turn, but it has signed recently
+	parent, header = header, &types.Header{Number: big.NewInt(3), Time: 111}
+	report.addBlock(snap, parent, header, validators[0], 3, 0)
+	report.finalize()
+
+	assert.Equal(t, uint64(1), report.Validators[validators[1]].InTurnBlocks)
+	assert.Equal(t, uint64(1), report.Validators[validators[2]].MissedTurns)
+	assert.Equal(t, uint64(1), report.Validators[validators[0]].OutOfTurnBlocks)
+	assert.Equal(t, float64(2), report.Validators[validators[0]].AvgBackOffDelay)
+	assert.Equal(t, float64(1), report.Validators[validators[0]].AvgBackOffTime)
+	assert.Equal(t, uint64(1), report.Validators[validators[0]].InTurnBlocks)
+	assert.Equal(t, uint64(1), report.Validators[validators[0]].RecentsViolations)
+}
+
+func TestVoteParticipationReport(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+	voteAddrs := []types.BLSPublicKey{{1}, {2}, {3}}
+	voteSnap := newSnapshot(&params.ParliaConfig{Period: 3, Epoch: 200}, nil, 8, common.Hash{}, validators, voteAddrs, nil)
+	headSnap := voteSnap.copy()
+	headSnap.Attestation = &types.VoteData{SourceNumber: 7, TargetNumber: 9}
+	report := &VoteParticipationReport{
+		Validators:  make(map[common.Address]*VoteParticipation),
+		FinalityLag: make(map[uint64]uint64),
+	}
+
+	attestation := &types.VoteAttestation{VoteAddressSet: 0b011, Data: &types.VoteData{SourceNumber: 8, TargetNumber: 9}}
+	report.addBlock(voteSnap, headSnap, &types.Header{Number: big.NewInt(10)}, attestation)
+	report.addBlock(voteSnap, headSnap, &types.Header{Number: big.NewInt(11)}, nil)
+	report.finalize()
+
+	assert.Equal(t, []uint64{11}, report.NoAttestationBlocks)
+	assert.Equal(t, map[uint64]uint64{3: 1, 4: 1}, report.FinalityLag)
+	assert.Equal(t, voteAddrs[0], report.Validators[validators[0]].VoteAddress)
+	assert.Equal(t, float64(1), report.Validators[validators[0]].InclusionRate)
+	assert.Equal(t, float64(1), report.Validators[validators[1]].InclusionRate)
+	assert.Equal(t, uint64(1), report.Validators[validators[2]].Eligible)
+	assert.Equal(t, uint64(0), report.Validators[validators[2]].Included)
+}
+
+func TestValidatorSetHistory(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	oldVals := map[common.Address]*ValidatorInfo{
+		common.HexToAddress("0x1"): {VoteAddress: types.BLSPublicKey{1}},
+		common.HexToAddress("0x2"): {VoteAddress: types.BLSPublicKey{2}},
+	}
+	newVals := map[common.Address]*ValidatorInfo{
+		common.HexToAddress("0x2"): {VoteAddress: types.BLSPublicKey{4}},
+		common.HexToAddress("0x3"): {VoteAddress: types.BLSPublicKey{3}},
+	}
+	for _, number := range []int64{201, 401, 601} {
+		header := &types.Header{Number: big.NewInt(number)}
+		checkpoint := &types.Header{Number: big.NewInt(number - 1)}
+		assert.NoError(t, newValidatorSetTransition(header, checkpoint, oldVals, newVals).store(db))
+	}
+
+	transitions, err := loadValidatorSetTransitions(db, 300, 601)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(transitions))
+	transition := transitions[0]
+	assert.Equal(t, uint64(401), transition.Number)
+	assert.Equal(t, uint64(400), transition.Checkpoint)
+	assert.Equal(t, []common.Address{common.HexToAddress("0x2"), common.HexToAddress("0x3")}, transition.Validators)
+	assert.Equal(t, []common.Address{common.HexToAddress("0x3")}, transition.Added)
+	assert.Equal(t, []common.Address{common.HexToAddress("0x1")}, transition.Removed)
+	assert.Equal(t, []VoteAddressChange{{
+		Validator: common.HexToAddress("0x2"),
+		Old:       types.BLSPublicKey{2},
+		New:       types.BLSPublicKey{4},
+	}}, transition.VoteAddressChanges)
+}
+
+func TestValidatorSetHistoryRange(t *testing.T) {
+	api := &API{}
+	_, err := api.GetValidatorSetHistory(0, maxQueryRange)
+	assert.ErrorContains(t, err, "block range too large")
+	_, err = api.GetValidatorSetHistory(10, 9)
+	assert.ErrorContains(t, err, "invalid block range")
+}
+
+func TestReportRange(t *testing.T) {
+	api := &API{}
+	_, err := api.GetValidatorPerformance(context.Background(), 0, rpc.BlockNumber(maxReportRange))
+	assert.ErrorContains(t, err, "block range too large")
+	_, err = api.GetVoteParticipation(context.Background(), 0, rpc.BlockNumber(maxReportRange))
+	assert.ErrorContains(t, err, "block range too large")
+}
@@ -752,7 +752,11 @@ func (p *Parlia) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
 
-	snap, err := snap.apply(headers, chain, parents, p.chainConfig)
+	snap, err := snap.apply(headers, chain, parents, p.chainConfig, func(transition *ValidatorSetTransition) {
+		if err := transition.store(p.db); err != nil {
+			log.Error("Failed to store validator set transition", "number", transition.Number, "err", err)
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
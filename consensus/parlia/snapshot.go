@@ -221,7 +221,9 @@ func (s *Snapshot) SignRecently(validator common.Address) bool {
 	return false
 }
 
-func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderReader, parents []*types.Header, chainConfig *params.ChainConfig) (*Snapshot, error) {
+// apply creates a new snapshot by applying the given headers to the original one.
+// If onTransition is not nil, it's called for every validator set switch.
+func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderReader, parents []*types.Header, chainConfig *params.ChainConfig, onTransition func(*ValidatorSetTransition)) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -303,6 +305,9 @@ func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainHeaderRea
 					delete(snap.RecentForkHashes, number-uint64(newLimit)-uint64(i))
 				}
 			}
+			if onTransition != nil {
+				onTransition(newValidatorSetTransition(header, checkpointHeader, snap.Validators, newVals))
+			}
 			snap.Validators = newVals
 			if chainConfig.IsLuban(header.Number) {
 				validators := snap.validators()
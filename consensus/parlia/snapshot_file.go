@@ -0,0 +1,160 @@
+package parlia
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// errSnapshotChecksumMismatch is returned if the snapshot doesn't match the checksum of the file.
+	errSnapshotChecksumMismatch = errors.New("parlia snapshot checksum mismatch")
+
+	// errSnapshotUntrustedSigner is returned if the checksum isn't signed by the trusted signer.
+	errSnapshotUntrustedSigner = errors.New("parlia snapshot signed by untrusted signer")
+
+	// errSnapshotNoTrustedSigner is returned if no trusted signer is configured and
+	// unsigned snapshots are not explicitly allowed.
+	errSnapshotNoTrustedSigner = errors.New("parlia snapshot signer not configured")
+)
+
+// SnapshotFile is the portable form of a parlia snapshot, used to bootstrap
+// nodes from a trusted checkpoint without walking back the headers.
+type SnapshotFile struct {
+	Snapshot  json.RawMessage `json:"snapshot"`            // Json encoded Snapshot
+	Checksum  common.Hash     `json:"checksum"`            // Keccak256 hash of Snapshot
+	Signer    common.Address  `json:"signer,omitempty"`    // Account which signed the checksum
+	Signature hexutil.Bytes   `json:"signature,omitempty"` // Signature of the checksum
+}
+
+// ExportSnapshot retrieves the snapshot at the given header and packs it into a
+// snapshot file, the checksum is signed with key if it's not nil.
+func (p *Parlia) ExportSnapshot(chain consensus.ChainHeaderReader, header *types.Header, key *ecdsa.PrivateKey) (*SnapshotFile, error) {
+	snap, err := p.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	file := &SnapshotFile{
+		Snapshot: blob,
+		Checksum: crypto.Keccak256Hash(blob),
+	}
+	if key != nil {
+		if file.Signature, err = crypto.Sign(file.Checksum[:], key); err != nil {
+			return nil, err
+		}
+		file.Signer = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return file, nil
+}
+
+// ImportSnapshot verifies the snapshot file and seeds the snapshot cache with it,
+// see SnapshotFile.Verify for the meaning of trusted and insecure. Checkpoint
+// snapshots are also persisted into the database.
+func (p *Parlia) ImportSnapshot(file *SnapshotFile, trusted *common.Address, insecure bool) (*Snapshot, error) {
+	snap, err := decodeSnapshotFile(file, trusted, insecure)
+	if err != nil {
+		return nil, err
+	}
+	snap.config = p.config
+	snap.sigCache = p.signatures
+	snap.ethAPI = p.ethAPI
+	if snap.Number%checkpointInterval == 0 {
+		if err := snap.store(p.db); err != nil {
+			return nil, err
+		}
+	}
+	p.recentSnaps.Add(snap.Hash, snap)
+	log.Info("Imported parlia snapshot", "number", snap.Number, "hash", snap.Hash, "signer", file.Signer)
+	return snap, nil
+}
+
+// StoreSnapshotFile verifies the snapshot file and persists the snapshot into the
+// database, see SnapshotFile.Verify for the meaning of trusted and insecure. Only
+// checkpoint snapshots can be stored, others would never be loaded from the database.
+func StoreSnapshotFile(db ethdb.Database, file *SnapshotFile, trusted *common.Address, insecure bool) (*Snapshot, error) {
+	snap, err := decodeSnapshotFile(file, trusted, insecure)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Number%checkpointInterval != 0 {
+		return nil, fmt.Errorf("snapshot at block %d is not a checkpoint, must be a multiple of %d", snap.Number, checkpointInterval)
+	}
+	return snap, snap.store(db)
+}
+
+// decodeSnapshotFile verifies the snapshot file and decodes the snapshot it contains.
+func decodeSnapshotFile(file *SnapshotFile, trusted *common.Address, insecure bool) (*Snapshot, error) {
+	if err := file.Verify(trusted, insecure); err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(file.Snapshot, snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// Verify checks the checksum of the snapshot file, which must also be signed by
+// the trusted account. Without a trusted account the file is only accepted if
+// insecure is set, whether it's signed or not.
+func (f *SnapshotFile) Verify(trusted *common.Address, insecure bool) error {
+	if crypto.Keccak256Hash(f.Snapshot) != f.Checksum {
+		return errSnapshotChecksumMismatch
+	}
+	if len(f.Signature) > 0 {
+		pubkey, err := crypto.SigToPub(f.Checksum[:], f.Signature)
+		if err != nil {
+			return err
+		}
+		if crypto.PubkeyToAddress(*pubkey) != f.Signer {
+			return errors.New("invalid parlia snapshot signature, signer mismatch")
+		}
+	}
+	if trusted == nil {
+		if !insecure {
+			return errSnapshotNoTrustedSigner
+		}
+		return nil
+	}
+	if len(f.Signature) == 0 || f.Signer != *trusted {
+		return errSnapshotUntrustedSigner
+	}
+	return nil
+}
+
+// ReadSnapshotFile loads a snapshot file from disk.
+func ReadSnapshotFile(path string) (*SnapshotFile, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := new(SnapshotFile)
+	if err := json.Unmarshal(blob, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// WriteSnapshotFile writes the snapshot file to disk. The file is kept compact
+// since reformatting the snapshot would break its checksum.
+func WriteSnapshotFile(path string, file *SnapshotFile) error {
+	blob, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, blob, 0644)
+}
@@ -2,12 +2,17 @@ package parlia
 
 import (
 	"bytes"
+	"encoding/json"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func TestValidatorSetSort(t *testing.T) {
@@ -21,3 +26,42 @@ func TestValidatorSetSort(t *testing.T) {
 		assert.True(t, bytes.Compare(validators[i][:], validators[i+1][:]) < 0)
 	}
 }
+
+func TestSnapshotFile(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	other := common.HexToAddress("0x1")
+
+	snap := newSnapshot(&params.ParliaConfig{Period: 3, Epoch: 200}, nil, checkpointInterval, common.Hash{1}, []common.Address{signer, other}, nil, nil)
+	blob, err := json.Marshal(snap)
+	assert.NoError(t, err)
+	file := &SnapshotFile{Snapshot: blob, Checksum: crypto.Keccak256Hash(blob)}
+
+	// unsigned files are only accepted if explicitly allowed
+	assert.Equal(t, errSnapshotNoTrustedSigner, file.Verify(nil, false))
+	assert.NoError(t, file.Verify(nil, true))
+	assert.Equal(t, errSnapshotUntrustedSigner, file.Verify(&signer, false))
+	assert.Equal(t, errSnapshotUntrustedSigner, file.Verify(&signer, true))
+
+	file.Signature, _ = crypto.Sign(file.Checksum[:], key)
+	file.Signer = signer
+	assert.NoError(t, file.Verify(&signer, false))
+	assert.Equal(t, errSnapshotUntrustedSigner, file.Verify(&other, false))
+	assert.Equal(t, errSnapshotNoTrustedSigner, file.Verify(nil, false))
+
+	path := filepath.Join(t.TempDir(), "parlia.json")
+	assert.NoError(t, WriteSnapshotFile(path, file))
+	loaded, err := ReadSnapshotFile(path)
+	assert.NoError(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	stored, err := StoreSnapshotFile(db, loaded, &signer, false)
+	assert.NoError(t, err)
+	assert.Equal(t, snap.Validators, stored.Validators)
+	_, err = loadSnapshot(snap.config, nil, db, snap.Hash, nil)
+	assert.NoError(t, err)
+
+	// tampered snapshot
+	loaded.Snapshot = append(json.RawMessage{' '}, blob...)
+	assert.Equal(t, errSnapshotChecksumMismatch, loaded.Verify(&signer, false))
+}
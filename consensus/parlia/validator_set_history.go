@@ -0,0 +1,115 @@
+package parlia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// VoteAddressChange is a BLS vote key rotation of a validator kept across an epoch transition.
+type VoteAddressChange struct {
+	Validator common.Address     `json:"validator"`
+	Old       types.BLSPublicKey `json:"old"`
+	New       types.BLSPublicKey `json:"new"`
+}
+
+// ValidatorSetTransition records how the validator set changed at an epoch switch.
+type ValidatorSetTransition struct {
+	Number             uint64              `json:"number"`     // Block number where the new validator set took effect
+	Hash               common.Hash         `json:"hash"`       // Block hash where the new validator set took effect
+	Checkpoint         uint64              `json:"checkpoint"` // Epoch block number where the new validator set was announced
+	Validators         []common.Address    `json:"validators"`
+	Added              []common.Address    `json:"added"`
+	Removed            []common.Address    `json:"removed"`
+	VoteAddressChanges []VoteAddressChange `json:"voteAddressChanges"`
+}
+
+// newValidatorSetTransition compares the validator sets before and after the given header.
+func newValidatorSetTransition(header, checkpoint *types.Header, oldVals, newVals map[common.Address]*ValidatorInfo) *ValidatorSetTransition {
+	transition := &ValidatorSetTransition{
+		Number:             header.Number.Uint64(),
+		Hash:               header.Hash(),
+		Checkpoint:         checkpoint.Number.Uint64(),
+		Validators:         make([]common.Address, 0, len(newVals)),
+		Added:              []common.Address{},
+		Removed:            []common.Address{},
+		VoteAddressChanges: []VoteAddressChange{},
+	}
+	for val, info := range newVals {
+		transition.Validators = append(transition.Validators, val)
+		old, ok := oldVals[val]
+		if !ok {
+			transition.Added = append(transition.Added, val)
+			continue
+		}
+		if old.VoteAddress != info.VoteAddress {
+			transition.VoteAddressChanges = append(transition.VoteAddressChanges, VoteAddressChange{
+				Validator: val,
+				Old:       old.VoteAddress,
+				New:       info.VoteAddress,
+			})
+		}
+	}
+	for val := range oldVals {
+		if _, ok := newVals[val]; !ok {
+			transition.Removed = append(transition.Removed, val)
+		}
+	}
+	sort.Sort(validatorsAscending(transition.Validators))
+	sort.Sort(validatorsAscending(transition.Added))
+	sort.Sort(validatorsAscending(transition.Removed))
+	sort.Slice(transition.VoteAddressChanges, func(i, j int) bool {
+		return bytes.Compare(transition.VoteAddressChanges[i].Validator[:], transition.VoteAddressChanges[j].Validator[:]) < 0
+	})
+	return transition
+}
+
+// validatorSetTransitionKey = ParliaValidatorSetPrefix + num (uint64 big endian) + hash
+func validatorSetTransitionKey(number uint64, hash common.Hash) []byte {
+	key := make([]byte, len(rawdb.ParliaValidatorSetPrefix)+8+common.HashLength)
+	n := copy(key, rawdb.ParliaValidatorSetPrefix)
+	binary.BigEndian.PutUint64(key[n:], number)
+	copy(key[n+8:], hash[:])
+	return key
+}
+
+// store inserts the transition into the database.
+func (t *ValidatorSetTransition) store(db ethdb.KeyValueWriter) error {
+	blob, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return db.Put(validatorSetTransitionKey(t.Number, t.Hash), blob)
+}
+
+// loadValidatorSetTransitions retrieves the stored transitions in [from, to], including
+// the ones of side chains, ordered by block number.
+func loadValidatorSetTransitions(db ethdb.Iteratee, from, to uint64) ([]*ValidatorSetTransition, error) {
+	start := make([]byte, 8)
+	binary.BigEndian.PutUint64(start, from)
+	it := db.NewIterator(rawdb.ParliaValidatorSetPrefix, start)
+	defer it.Release()
+
+	var transitions []*ValidatorSetTransition
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(rawdb.ParliaValidatorSetPrefix)+8+common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(rawdb.ParliaValidatorSetPrefix):]) > to {
+			break
+		}
+		transition := new(ValidatorSetTransition)
+		if err := json.Unmarshal(it.Value(), transition); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, it.Error()
+}
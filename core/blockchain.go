@@ -298,6 +298,12 @@ type BlockChain struct {
 	diffQueueBuffer            chan *types.DiffLayer
 	diffLayerFreezerBlockLimit uint64
 
+	// untrusted diff layers received from the peers, only set with diff sync
+	untrustedDiffs *lru.Cache[common.Hash, *untrustedDiffLayer]
+	untrustedLock  sync.Mutex                      // Lock keeping the first diff layer received of a block
+	appliedDiffs   *lru.Cache[common.Hash, string] // Peers serving the diff layers applied, until verified
+	dropDiffPeer   func(id string)                 // Disconnects the peers serving invalid diff layers
+
 	wg            sync.WaitGroup
 	quit          chan struct{} // shutdown signal, closed in Stop.
 	stopping      atomic.Bool   // false if chain is running, true when stopped
@@ -558,8 +564,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	}
 
 	if bc.doubleSignMonitor != nil {
-		bc.wg.Add(1)
+		bc.wg.Add(2)
 		go bc.startDoubleSignMonitor()
+		go bc.submitDoubleSignEvidenceLoop()
 	}
 
 	// Rewind the chain in case of an incompatible config upgrade.
@@ -2988,6 +2995,23 @@ func (bc *BlockChain) startDoubleSignMonitor() {
 	}
 }
 
+// submitDoubleSignEvidenceLoop periodically submits the evidence found by the
+// double sign monitor, away from the header verification and chain head paths.
+func (bc *BlockChain) submitDoubleSignEvidenceLoop() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(monitor.EvidenceSubmitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			bc.doubleSignMonitor.SubmitPending()
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
 // skipBlock returns 'true', if the block being imported can be skipped over, meaning
 // that the block does not need to be processed but can be considered already fully 'done'.
 func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
@@ -3113,10 +3137,29 @@ func EnablePersistDiff(limit uint64) BlockChainOption {
 	}
 }
 
-func EnableBlockValidator(chainConfig *params.ChainConfig, engine consensus.Engine, mode VerifyMode, peers verifyPeers) BlockChainOption {
+// EnableDiffSync imports the blocks by applying the diff layers received from the
+// peers if available, instead of executing them. The state root can't be verified
+// locally without tries, so it's only allowed in full verify mode, where the verify
+// nodes check the root along with the diff hash of the applied diff layer.
+func EnableDiffSync(mode VerifyMode) BlockChainOption {
+	return func(bc *BlockChain) (*BlockChain, error) {
+		if !bc.NoTries() {
+			return nil, errors.New("diff sync requires the node running without tries")
+		}
+		if mode != FullVerify {
+			return nil, fmt.Errorf("diff sync requires full verify mode, have %s", mode)
+		}
+		bc.untrustedDiffs = lru.NewCache[common.Hash, *untrustedDiffLayer](untrustedDiffLayerLimit)
+		bc.appliedDiffs = lru.NewCache[common.Hash, string](appliedDiffLayerLimit)
+		bc.processor = &diffProcessor{bc: bc, Processor: bc.processor}
+		return bc, nil
+	}
+}
+
+func EnableBlockValidator(chainConfig *params.ChainConfig, engine consensus.Engine, mode VerifyMode, config RemoteVerifyConfig, peers verifyPeers) BlockChainOption {
 	return func(bc *BlockChain) (*BlockChain, error) {
 		if mode.NeedRemoteVerify() {
-			vm, err := NewVerifyManager(bc, peers, mode == InsecureVerify)
+			vm, err := NewVerifyManager(bc, peers, mode == InsecureVerify, config)
 			if err != nil {
 				return nil, err
 			}
@@ -3127,9 +3170,16 @@ func EnableBlockValidator(chainConfig *params.ChainConfig, engine consensus.Engi
 	}
 }
 
-func EnableDoubleSignChecker(bc *BlockChain) (*BlockChain, error) {
-	bc.doubleSignMonitor = monitor.NewDoubleSignMonitor()
-	return bc, nil
+func EnableDoubleSignChecker(retention uint64) BlockChainOption {
+	return func(bc *BlockChain) (*BlockChain, error) {
+		bc.doubleSignMonitor = monitor.NewDoubleSignMonitor(bc.db, retention)
+		return bc, nil
+	}
+}
+
+// DoubleSignMonitor returns the double sign monitor, nil if it's not enabled.
+func (bc *BlockChain) DoubleSignMonitor() *monitor.DoubleSignMonitor {
+	return bc.doubleSignMonitor
 }
 
 func (bc *BlockChain) GetVerifyResult(blockNumber uint64, blockHash common.Hash, diffHash common.Hash) *VerifyResult {
@@ -3183,6 +3233,15 @@ func (bc *BlockChain) GetVerifyResult(blockNumber uint64, blockHash common.Hash,
 	return &res
 }
 
+// GetRemoteVerifyOutcome returns the outcome of the remote verification of the
+// block, nil if the block is not remotely verified.
+func (bc *BlockChain) GetRemoteVerifyOutcome(blockHash common.Hash) *RemoteVerifyOutcome {
+	if vm := bc.validator.RemoteVerifyManager(); vm != nil {
+		return vm.VerifyOutcome(blockHash)
+	}
+	return nil
+}
+
 func (bc *BlockChain) GetTrustedDiffLayer(blockHash common.Hash) *types.DiffLayer {
 	var diff *types.DiffLayer
 	if cached, ok := bc.diffLayerCache.Get(blockHash); ok {
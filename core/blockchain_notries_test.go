@@ -21,20 +21,27 @@
 package core
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
 )
 
 func newMockVerifyPeer() *mockVerifyPeer {
-	return &mockVerifyPeer{}
+	key, _ := crypto.GenerateKey()
+	return &mockVerifyPeer{key: key}
 }
 
 type requestRoot struct {
@@ -50,13 +57,25 @@ type verifFailedStatus struct {
 
 // mockVerifyPeer is a mocking struct that simulates p2p signals for verification tasks.
 type mockVerifyPeer struct {
-	callback func(*requestRoot)
+	key           *ecdsa.PrivateKey
+	callback      func(*requestRoot)
+	proofCallback func(blockHash common.Hash, queries []AccountProofQuery)
 }
 
 func (peer *mockVerifyPeer) setCallBack(callback func(*requestRoot)) {
 	peer.callback = callback
 }
 
+func (peer *mockVerifyPeer) RequestProof(blockNumber uint64, blockHash common.Hash, queries []AccountProofQuery) error {
+	if peer.proofCallback == nil {
+		return errors.New("proofs not served")
+	}
+	// respond asynchronously, as the task requesting the proofs is handling the
+	// responses as well
+	go peer.proofCallback(blockHash, queries)
+	return nil
+}
+
 func (peer *mockVerifyPeer) RequestRoot(blockNumber uint64, blockHash common.Hash, diffHash common.Hash) error {
 	if peer.callback != nil {
 		peer.callback(&requestRoot{blockNumber, blockHash, diffHash})
@@ -65,7 +84,12 @@ func (peer *mockVerifyPeer) RequestRoot(blockNumber uint64, blockHash common.Has
 }
 
 func (peer *mockVerifyPeer) ID() string {
-	return "mock_peer"
+	return enode.PubkeyToIDV4(&peer.key.PublicKey).String()
+}
+
+func (peer *mockVerifyPeer) sign(res *VerifyResult) []byte {
+	sig, _ := crypto.Sign(res.SigHash().Bytes(), peer.key)
+	return sig
 }
 
 type mockVerifyPeers struct {
@@ -104,13 +128,13 @@ func makeTestBackendWithRemoteValidator(blocks int, mode VerifyMode, failed *ver
 	peers := []VerifyPeer{peer}
 
 	verifier, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{},
-		nil, nil, EnablePersistDiff(100000), EnableBlockValidator(params.TestChainConfig, engine2, LocalVerify, nil))
+		nil, nil, EnablePersistDiff(100000), EnableBlockValidator(params.TestChainConfig, engine2, LocalVerify, DefaultRemoteVerifyConfig, nil))
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	fastnode, err := NewBlockChain(db2, nil, gspec2, nil, engine2, vm.Config{},
-		nil, nil, EnableBlockValidator(params.TestChainConfig, engine2, mode, newMockRemoteVerifyPeer(peers)))
+		nil, nil, EnableBlockValidator(params.TestChainConfig, engine2, mode, DefaultRemoteVerifyConfig, newMockRemoteVerifyPeer(peers)))
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -164,10 +188,15 @@ func makeTestBackendWithRemoteValidator(blocks int, mode VerifyMode, failed *ver
 			}
 			fastnode.validator.RemoteVerifyManager().
 				HandleRootResponse(
-					resp, peer.ID())
+					resp, peer.ID(), peer.sign(resp))
 		}
 	})
 
+	peer.proofCallback = func(blockHash common.Hash, queries []AccountProofQuery) {
+		proofs, _ := verifier.GetStateProofs(blockHash, queries)
+		fastnode.validator.RemoteVerifyManager().HandleProofResponse(blockHash, peer.ID(), proofs)
+	}
+
 	bs, _ := GenerateChain(params.TestChainConfig, verifier.Genesis(), ethash.NewFaker(), db, blocks, generator)
 	if _, err := verifier.InsertChain(bs); err != nil {
 		return nil, nil, nil, err
@@ -224,3 +253,178 @@ func TestFastNode(t *testing.T) {
 		t.Fatalf("blocks insert should be failed at height %d", failed.blockNumber+11)
 	}
 }
+
+func TestVerifyTaskQuorum(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), Root: common.Hash{0x1}}
+	peers := []*mockVerifyPeer{newMockVerifyPeer(), newMockVerifyPeer(), newMockVerifyPeer(), newMockVerifyPeer()}
+	config := RemoteVerifyConfig{Quorum: 2, Peers: 3, Allowlist: []string{peers[0].ID(), peers[1].ID(), "0x" + peers[2].ID()}, RequireSignature: true}
+
+	verifyCh := make(chan *RemoteVerifyOutcome, 1)
+	task := NewVerifyTask(common.Hash{}, &types.DiffLayer{}, header, newMockRemoteVerifyPeer(nil), verifyCh, false, config.sanitize())
+	defer task.Close()
+
+	respond := func(peer *mockVerifyPeer, root common.Hash, signed bool) {
+		res := &VerifyResult{Status: types.StatusFullVerified, BlockNumber: 1, BlockHash: header.Hash(), Root: root}
+		msg := verifyMessage{verifyResult: res, peerId: peer.ID()}
+		if signed {
+			msg.signature = peer.sign(res)
+		}
+		task.messageCh <- msg
+	}
+	expectPending := func(attestations int) {
+		t.Helper()
+		// wait for the task to handle the last message
+		task.messageCh <- verifyMessage{verifyResult: &VerifyResult{}, peerId: "unknown"}
+		select {
+		case <-verifyCh:
+			t.Fatalf("block verified before reaching the quorum")
+		default:
+		}
+		if outcome := task.outcome(); outcome.Status != RemoteVerifyPending || len(outcome.Attestations) != attestations {
+			t.Fatalf("outcome mismatch: have %s with %d attestations, want pending with %d", outcome.Status, len(outcome.Attestations), attestations)
+		}
+	}
+
+	// The unsigned roots, the roots signed by other nodes and the roots of peers out
+	// of the allowlist don't count
+	respond(peers[0], header.Root, false)
+	respond(peers[3], header.Root, true)
+	res := &VerifyResult{Status: types.StatusFullVerified, BlockNumber: 1, BlockHash: header.Hash(), Root: header.Root}
+	task.messageCh <- verifyMessage{verifyResult: res, peerId: peers[1].ID(), signature: peers[2].sign(res)}
+	expectPending(0)
+
+	// The mismatching roots are recorded as dissents
+	respond(peers[1], common.Hash{0x2}, true)
+	expectPending(0)
+
+	// A peer attesting twice counts once
+	respond(peers[2], header.Root, true)
+	respond(peers[2], header.Root, true)
+	expectPending(1)
+
+	// The block is verified once the quorum is reached
+	respond(peers[0], header.Root, true)
+	outcome := <-verifyCh
+	if outcome.Status != RemoteVerifyVerified || len(outcome.Attestations) != 2 || len(outcome.Dissents) != 1 {
+		t.Fatalf("outcome mismatch: have %s with %d attestations and %d dissents", outcome.Status, len(outcome.Attestations), len(outcome.Dissents))
+	}
+	for _, attestation := range outcome.Attestations {
+		res := &VerifyResult{Status: attestation.Status, BlockNumber: 1, BlockHash: header.Hash(), Root: attestation.Root}
+		if signer, err := res.Signer(attestation.Signature); err != nil || signer.String() != attestation.Peer {
+			t.Fatalf("attestation of %s not provable: signer %v, err %v", attestation.Peer, signer, err)
+		}
+	}
+}
+
+func TestVerifyTaskUnsignedRoot(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), Root: common.Hash{0x1}}
+	peer := newMockVerifyPeer()
+
+	// The unsigned roots count unless the signature is required
+	verifyCh := make(chan *RemoteVerifyOutcome, 1)
+	task := NewVerifyTask(common.Hash{}, &types.DiffLayer{}, header, newMockRemoteVerifyPeer(nil), verifyCh, false, DefaultRemoteVerifyConfig.sanitize())
+	defer task.Close()
+
+	res := &VerifyResult{Status: types.StatusFullVerified, BlockNumber: 1, BlockHash: header.Hash(), Root: header.Root}
+	task.messageCh <- verifyMessage{verifyResult: res, peerId: peer.ID()}
+	outcome := <-verifyCh
+	if outcome.Status != RemoteVerifyVerified || len(outcome.Attestations) != 1 || outcome.Attestations[0].Signature != nil {
+		t.Fatalf("outcome mismatch: have %s with %d attestations", outcome.Status, len(outcome.Attestations))
+	}
+}
+
+func TestVerifyTaskProofFallback(t *testing.T) {
+	var (
+		header = &types.Header{Number: big.NewInt(1), Root: common.Hash{0x1}}
+		diff   = &types.DiffLayer{Accounts: []types.DiffAccount{{Account: common.Hash{0x2}}}}
+		config = RemoteVerifyConfig{Quorum: 1, Peers: 1, Proofs: 1}
+		res    = &VerifyResult{Status: types.StatusFullVerified, BlockNumber: 1, BlockHash: header.Hash(), Root: header.Root}
+	)
+	run := func(respond bool, proofs []*AccountProof) (*RemoteVerifyOutcome, bool) {
+		t.Helper()
+		peer := newMockVerifyPeer()
+		asked := make(chan struct{}, 1)
+		peer.proofCallback = func(common.Hash, []AccountProofQuery) { asked <- struct{}{} }
+
+		verifyCh := make(chan *RemoteVerifyOutcome, 1)
+		task := NewVerifyTask(common.Hash{}, diff, header, newMockRemoteVerifyPeer([]VerifyPeer{peer}), verifyCh, false, config)
+		defer task.Close()
+
+		<-asked
+		if respond {
+			task.messageCh <- verifyMessage{verifyResult: res, peerId: peer.ID(), proofResponse: true, proofs: proofs}
+		}
+		task.messageCh <- verifyMessage{verifyResult: res, peerId: peer.ID()}
+		select {
+		case outcome := <-verifyCh:
+			_, bad := task.badPeers[peer.ID()]
+			return outcome, bad
+		case <-time.After(2 * resendInterval):
+			t.Fatalf("block not verified without the proofs")
+			return nil, false
+		}
+	}
+
+	// The spot-check is skipped once the only proof peer returned invalid proofs,
+	// and the peer is marked bad
+	outcome, bad := run(true, []*AccountProof{{Account: common.Hash{0x3}}})
+	if outcome.Status != RemoteVerifyVerified || outcome.ProofSkipped != "no peer left serving valid state proofs" || !bad {
+		t.Fatalf("outcome mismatch: have %s, proofs skipped for %q, bad peer %v", outcome.Status, outcome.ProofSkipped, bad)
+	}
+
+	// The peer without the state is not marked bad
+	outcome, bad = run(true, nil)
+	if outcome.Status != RemoteVerifyVerified || outcome.ProofSkipped != "no peer left serving valid state proofs" || bad {
+		t.Fatalf("outcome mismatch: have %s, proofs skipped for %q, bad peer %v", outcome.Status, outcome.ProofSkipped, bad)
+	}
+
+	// The spot-check is skipped once the proof peer didn't answer in time
+	defer func(timeout time.Duration) { proofTimeout = timeout }(proofTimeout)
+	proofTimeout = 100 * time.Millisecond
+
+	outcome, _ = run(false, nil)
+	if outcome.Status != RemoteVerifyVerified || outcome.ProofSkipped != "no valid state proofs in time" {
+		t.Fatalf("outcome mismatch: have %s, proofs skipped for %q", outcome.Status, outcome.ProofSkipped)
+	}
+}
+
+func TestStateProofSpotCheck(t *testing.T) {
+	verifier, _, blocks, err := makeTestBackendWithRemoteValidator(10, FullVerify, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	var (
+		header = blocks[len(blocks)-1].Header()
+		diff   = verifier.chain.GetTrustedDiffLayer(header.Hash())
+		r      = rand.New(rand.NewSource(1))
+	)
+	queries := sampleProofQueries(diff, len(diff.Accounts), r)
+	if len(queries) != len(diff.Accounts) {
+		t.Fatalf("sampled accounts mismatch: have %d, want %d", len(queries), len(diff.Accounts))
+	}
+	proofs, err := verifier.chain.GetStateProofs(header.Hash(), queries)
+	if err != nil {
+		t.Fatalf("failed to prove state: %v", err)
+	}
+	if err := verifyStateProofs(header.Root, diff, queries, proofs); err != nil {
+		t.Fatalf("failed to verify state proofs: %v", err)
+	}
+
+	// The proofs not matching the root are the fault of the peer
+	if err := verifyStateProofs(header.Root, diff, queries, proofs[1:]); !errors.Is(err, errInvalidStateProof) {
+		t.Fatalf("missing proof: have %v, want %v", err, errInvalidStateProof)
+	}
+	if err := verifyStateProofs(common.Hash{0x1}, diff, queries, proofs); !errors.Is(err, errInvalidStateProof) {
+		t.Fatalf("proofs of other root: have %v, want %v", err, errInvalidStateProof)
+	}
+
+	// The local state differing from the proven one fails the spot-check
+	tampered := *diff
+	tampered.Accounts = append([]types.DiffAccount{}, diff.Accounts...)
+	account, _ := types.FullAccount(tampered.Accounts[0].Blob)
+	account.Nonce++
+	tampered.Accounts[0].Blob = types.SlimAccountRLP(*account)
+	if err := verifyStateProofs(header.Root, &tampered, queries, proofs); !errors.Is(err, errStateProofMismatch) {
+		t.Fatalf("tampered account: have %v, want %v", err, errStateProofMismatch)
+	}
+}
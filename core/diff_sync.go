@@ -0,0 +1,221 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// untrustedDiffLayerLimit is the max number of diff layers received from the
+	// peers kept until their blocks are imported.
+	untrustedDiffLayerLimit = 1024
+
+	// appliedDiffLayerLimit is the max number of blocks imported from the diff
+	// layers, whose peers are kept until the blocks are verified.
+	appliedDiffLayerLimit = 256
+)
+
+var (
+	diffSyncReceivedMeter = metrics.NewRegisteredMeter("chain/diffsync/received", nil)
+	diffSyncRejectedMeter = metrics.NewRegisteredMeter("chain/diffsync/rejected", nil)
+	diffSyncAppliedMeter  = metrics.NewRegisteredMeter("chain/diffsync/applied", nil)
+	diffSyncFallbackMeter = metrics.NewRegisteredMeter("chain/diffsync/fallback", nil)
+	diffSyncReimportMeter = metrics.NewRegisteredMeter("chain/diffsync/reimport", nil)
+)
+
+// untrustedDiffLayer is a diff layer received from a peer, which is only trusted
+// once the block is imported and its state root verified.
+type untrustedDiffLayer struct {
+	diff *types.DiffLayer
+	peer string
+}
+
+// HandleDiffLayer keeps the diff layer received from the peer, so that its block
+// is imported by applying it instead of executing the transactions. The diff layer
+// received first for a block is kept, it's never replaced by the ones of the other
+// peers.
+func (bc *BlockChain) HandleDiffLayer(diff *types.DiffLayer, pid string) error {
+	if bc.untrustedDiffs == nil {
+		return errors.New("diff sync is disabled")
+	}
+	diffSyncReceivedMeter.Mark(1)
+
+	// Skip the diff layers of the imported blocks and of the blocks too far away
+	head := bc.CurrentBlock().Number.Uint64()
+	if diff.Number <= head || diff.Number > head+maxBeyondBlocks {
+		return nil
+	}
+	if bc.HasBlock(diff.BlockHash, diff.Number) {
+		return nil
+	}
+	for _, storage := range diff.Storages {
+		if len(storage.Keys) != len(storage.Vals) {
+			diffSyncRejectedMeter.Mark(1)
+			return fmt.Errorf("invalid storage of account %#x: %d keys, %d values", storage.Account, len(storage.Keys), len(storage.Vals))
+		}
+	}
+	bc.untrustedLock.Lock()
+	defer bc.untrustedLock.Unlock()
+
+	if !bc.untrustedDiffs.Contains(diff.BlockHash) {
+		bc.untrustedDiffs.Add(diff.BlockHash, &untrustedDiffLayer{diff: diff, peer: pid})
+	}
+	return nil
+}
+
+// SetDiffPeerDropper sets the callback disconnecting the peers serving the diff
+// layers failing the remote verification. It must be set ahead of the import.
+func (bc *BlockChain) SetDiffPeerDropper(drop func(id string)) {
+	bc.dropDiffPeer = drop
+}
+
+// appliedDiffPeer returns the peer serving the diff layer the block was imported
+// from, false if the block was executed.
+func (bc *BlockChain) appliedDiffPeer(hash common.Hash) (string, bool) {
+	if bc.appliedDiffs == nil {
+		return "", false
+	}
+	return bc.appliedDiffs.Peek(hash)
+}
+
+// reimportDiffBlock discards the state of the block imported from an invalid diff
+// layer, and imports the block along with its descendants again by executing them
+// on top of the parent state. The peer serving the diff layer is dropped.
+func (bc *BlockChain) reimportDiffBlock(header *types.Header, reason string) {
+	hash := header.Hash()
+	peer, ok := bc.appliedDiffPeer(hash)
+	if !ok {
+		return
+	}
+	diffSyncReimportMeter.Mark(1)
+	log.Warn("Diff layer failed the remote verification", "number", header.Number, "hash", hash, "peer", peer, "reason", reason)
+	if bc.dropDiffPeer != nil {
+		bc.dropDiffPeer(peer)
+	}
+	if !bc.chainmu.TryLock() {
+		return
+	}
+	defer bc.chainmu.Unlock()
+
+	// The descendants are executed as well, as their state builds on the invalid one
+	var blocks types.Blocks
+	for number := header.Number.Uint64(); number <= bc.CurrentBlock().Number.Uint64(); number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 || blocks[0].Hash() != hash {
+		log.Debug("Block imported from invalid diff layer is not canonical", "number", header.Number, "hash", hash)
+		return
+	}
+	for _, block := range blocks {
+		bc.untrustedDiffs.Remove(block.Hash())
+		bc.appliedDiffs.Remove(block.Hash())
+	}
+	// Without tries, the state only lives in the snapshot layers, which are kept
+	// in memory until the block is verified.
+	if err := bc.snaps.Discard(header.Root); err != nil {
+		log.Error("Failed to discard the state of invalid diff layer", "number", header.Number, "hash", hash, "err", err)
+		return
+	}
+	if _, err := bc.insertChain(blocks, true); err != nil {
+		log.Error("Failed to reimport the blocks", "number", header.Number, "hash", hash, "count", len(blocks), "err", err)
+	}
+}
+
+// HasUntrustedDiffLayer returns whether a diff layer of the block was received.
+func (bc *BlockChain) HasUntrustedDiffLayer(blockHash common.Hash) bool {
+	return bc.untrustedDiffs != nil && bc.untrustedDiffs.Contains(blockHash)
+}
+
+// diffProcessor imports the blocks by applying the diff layers received from the
+// peers, and falls back to the wrapped processor for the blocks without one.
+//
+// The consensus engine is not run on the applied blocks, so it's only safe when
+// the state roots are verified by the trusted verify nodes.
+type diffProcessor struct {
+	bc *BlockChain
+	Processor
+}
+
+// Process implements Processor, applying the diff layer of the block if received,
+// or executing the transactions otherwise.
+func (p *diffProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*state.StateDB, types.Receipts, []*types.Log, uint64, error) {
+	if untrusted, ok := p.bc.untrustedDiffs.Get(block.Hash()); ok && !statedb.IsPipeCommit() {
+		p.bc.untrustedDiffs.Remove(block.Hash())
+
+		receipts, logs, usedGas, err := p.applyDiffLayer(block, statedb, untrusted.diff)
+		if err == nil {
+			p.bc.appliedDiffs.Add(block.Hash(), untrusted.peer)
+			diffSyncAppliedMeter.Mark(1)
+			return statedb, receipts, logs, usedGas, nil
+		}
+		diffSyncFallbackMeter.Mark(1)
+		log.Debug("Failed to apply diff layer", "number", block.Number(), "hash", block.Hash(), "peer", untrusted.peer, "err", err)
+	}
+	return p.Processor.Process(block, statedb, cfg)
+}
+
+// applyDiffLayer checks the receipts of the diff layer against the block, and then
+// applies the state changes to the state.
+func (p *diffProcessor) applyDiffLayer(block *types.Block, statedb *state.StateDB, diff *types.DiffLayer) (types.Receipts, []*types.Log, uint64, error) {
+	if diff.Number != block.NumberU64() {
+		return nil, nil, 0, fmt.Errorf("diff layer number mismatch: have %d, want %d", diff.Number, block.NumberU64())
+	}
+	txs := block.Transactions()
+	if len(diff.Receipts) != len(txs) {
+		return nil, nil, 0, fmt.Errorf("receipt count mismatch: have %d, want %d", len(diff.Receipts), len(txs))
+	}
+	receipts := make(types.Receipts, len(diff.Receipts))
+	for i, receipt := range diff.Receipts {
+		receipts[i] = &types.Receipt{
+			Status:            receipt.Status,
+			PostState:         receipt.PostState,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Bloom:             receipt.Bloom,
+			Logs:              receipt.Logs,
+		}
+	}
+	var blobGasPrice *big.Int
+	if block.ExcessBlobGas() != nil {
+		blobGasPrice = eip4844.CalcBlobFee(*block.ExcessBlobGas())
+	}
+	if err := receipts.DeriveFields(p.bc.chainConfig, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), blobGasPrice, txs); err != nil {
+		return nil, nil, 0, err
+	}
+	// The receipts are checked ahead of the validator, to leave the state untouched
+	// for the execution if the diff layer is rejected.
+	var usedGas uint64
+	if len(receipts) > 0 {
+		usedGas = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+	if usedGas != block.GasUsed() {
+		return nil, nil, 0, fmt.Errorf("invalid gas used (remote: %d diff: %d)", block.GasUsed(), usedGas)
+	}
+	if bloom := types.CreateBloom(receipts); bloom != block.Bloom() {
+		return nil, nil, 0, fmt.Errorf("invalid bloom (remote: %x diff: %x)", block.Bloom(), bloom)
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != block.ReceiptHash() {
+		return nil, nil, 0, fmt.Errorf("invalid receipt root hash (remote: %x diff: %x)", block.ReceiptHash(), hash)
+	}
+	if err := statedb.ApplyDiffLayer(diff); err != nil {
+		return nil, nil, 0, err
+	}
+	var logs []*types.Log
+	for _, receipt := range receipts {
+		logs = append(logs, receipt.Logs...)
+	}
+	return receipts, logs, usedGas, nil
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// rejectProcessor is a processor failing the blocks with transactions, to make
+// sure the blocks are imported by applying their diff layers.
+type rejectProcessor struct {
+	Processor
+}
+
+func (p rejectProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*state.StateDB, types.Receipts, []*types.Log, uint64, error) {
+	if len(block.Transactions()) > 0 {
+		return statedb, nil, nil, 0, errors.New("block executed")
+	}
+	return p.Processor.Process(block, statedb, cfg)
+}
+
+// newDiffSyncChain creates a chain without tries importing the blocks from the
+// diff layers of the source chain, which are never executed.
+func newDiffSyncChain(t *testing.T, source *BlockChain, blocks []*types.Block, tamper func(*types.DiffLayer)) *BlockChain {
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.NoTries = true
+	gspec := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  GenesisAlloc{testAddr: {Balance: big.NewInt(100000000000000000)}},
+	}
+	// Commit the genesis ahead, as tries are always enabled to commit the genesis
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db, triedb.NewDatabase(db, nil))
+
+	chain, err := NewBlockChain(db, &cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, EnableDiffSync(FullVerify))
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	processor := chain.processor.(*diffProcessor)
+	processor.Processor = rejectProcessor{processor.Processor}
+
+	for _, block := range blocks {
+		// The blocks without transactions have no diff layer
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		// Pass the diff layers through the wire format like the peers do
+		blob, err := rlp.EncodeToBytes(source.GetTrustedDiffLayer(block.Hash()))
+		if err != nil {
+			t.Fatalf("failed to encode diff layer: %v", err)
+		}
+		diff := new(types.DiffLayer)
+		if err := rlp.DecodeBytes(blob, diff); err != nil {
+			t.Fatalf("failed to decode diff layer: %v", err)
+		}
+		if tamper != nil {
+			tamper(diff)
+		}
+		if err := chain.HandleDiffLayer(diff, "peer"); err != nil {
+			t.Fatalf("failed to handle diff layer: %v", err)
+		}
+	}
+	return chain
+}
+
+func TestDiffSync(t *testing.T) {
+	source, _, blocks, err := makeTestBackendWithRemoteValidator(128, LocalVerify, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer source.close()
+
+	chain := newDiffSyncChain(t, source.chain, blocks, nil)
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks by diff layers: %v", err)
+	}
+	if chain.HasUntrustedDiffLayer(blocks[0].Hash()) {
+		t.Fatal("applied diff layer is not removed")
+	}
+	want, _ := source.chain.State()
+	have, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if have.GetBalance(testAddr).Cmp(want.GetBalance(testAddr)) != 0 || have.GetNonce(testAddr) != want.GetNonce(testAddr) {
+		t.Fatalf("state mismatch: have %v/%d, want %v/%d", have.GetBalance(testAddr), have.GetNonce(testAddr), want.GetBalance(testAddr), want.GetNonce(testAddr))
+	}
+	// The diff layers of the applied blocks must be the same as the source ones,
+	// so that the remote verification of the blocks succeeds.
+	waitDifflayerCached(chain, blocks)
+	for _, block := range blocks {
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		haveHash, _ := CalculateDiffHash(chain.GetTrustedDiffLayer(block.Hash()))
+		wantHash, _ := CalculateDiffHash(source.chain.GetTrustedDiffLayer(block.Hash()))
+		if haveHash != wantHash {
+			t.Fatalf("block %d: diff hash mismatch: have %x, want %x", block.NumberU64(), haveHash, wantHash)
+		}
+	}
+}
+
+func TestDiffSyncRejectInvalidReceipts(t *testing.T) {
+	source, _, blocks, err := makeTestBackendWithRemoteValidator(32, LocalVerify, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer source.close()
+
+	tampered := blocks[9].NumberU64()
+	chain := newDiffSyncChain(t, source.chain, blocks, func(diff *types.DiffLayer) {
+		if diff.Number == tampered {
+			diff.Receipts[0].CumulativeGasUsed++
+		}
+	})
+	defer chain.Stop()
+
+	// The tampered diff layer is rejected, falling back to the execution
+	if n, err := chain.InsertChain(blocks); err == nil || n != 9 {
+		t.Fatalf("import should fail at index 9, have %d: %v", n, err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != tampered-1 {
+		t.Fatalf("head mismatch: have %d, want %d", head, tampered-1)
+	}
+}
+
+func TestDiffSyncRequiresFullVerify(t *testing.T) {
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.NoTries = true
+	gspec := &Genesis{Config: params.TestChainConfig}
+
+	// The roots of the applied diff layers must be checked by the verify nodes
+	// along with the diff hash
+	for _, mode := range []VerifyMode{NoneVerify, InsecureVerify, LocalVerify} {
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db, triedb.NewDatabase(db, nil))
+		if _, err := NewBlockChain(db, &cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, EnableDiffSync(mode)); err == nil {
+			t.Fatalf("diff sync enabled in %s verify mode", mode)
+		}
+	}
+	db := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(db, triedb.NewDatabase(db, nil))
+	chain, err := NewBlockChain(db, &cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil, EnableDiffSync(FullVerify))
+	if err != nil {
+		t.Fatalf("failed to enable diff sync in full verify mode: %v", err)
+	}
+	chain.Stop()
+}
+
+func TestDiffSyncKeepsFirstDiffLayer(t *testing.T) {
+	source, _, blocks, err := makeTestBackendWithRemoteValidator(32, LocalVerify, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer source.close()
+
+	chain := newDiffSyncChain(t, source.chain, blocks, nil)
+	defer chain.Stop()
+
+	// The diff layer of another peer doesn't replace the one received first
+	hash := blocks[9].Hash()
+	tampered := *source.chain.GetTrustedDiffLayer(hash)
+	tampered.Accounts = nil
+	if err := chain.HandleDiffLayer(&tampered, "other"); err != nil {
+		t.Fatalf("failed to handle diff layer: %v", err)
+	}
+	if untrusted, _ := chain.untrustedDiffs.Peek(hash); untrusted.peer != "peer" || len(untrusted.diff.Accounts) == 0 {
+		t.Fatalf("diff layer replaced by the one of peer %q", untrusted.peer)
+	}
+}
+
+func TestDiffSyncReimportInvalidDiff(t *testing.T) {
+	source, _, blocks, err := makeTestBackendWithRemoteValidator(32, LocalVerify, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer source.close()
+
+	// Tamper the state of the last block with transactions, which is undetected
+	// until the remote verification
+	var tampered *types.Block
+	for _, block := range blocks {
+		if len(block.Transactions()) > 0 {
+			tampered = block
+		}
+	}
+	chain := newDiffSyncChain(t, source.chain, blocks, func(diff *types.DiffLayer) {
+		if diff.BlockHash == tampered.Hash() {
+			for i, account := range diff.Accounts {
+				if account.Account == crypto.Keccak256Hash(testAddr.Bytes()) {
+					full, _ := types.FullAccount(account.Blob)
+					full.Nonce += 100
+					diff.Accounts[i].Blob = types.SlimAccountRLP(*full)
+				}
+			}
+		}
+	})
+	defer chain.Stop()
+
+	var dropped []string
+	chain.SetDiffPeerDropper(func(id string) { dropped = append(dropped, id) })
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import blocks by diff layers: %v", err)
+	}
+	want, _ := source.chain.State()
+	if have, _ := chain.State(); have.GetNonce(testAddr) == want.GetNonce(testAddr) {
+		t.Fatal("tampered diff layer not applied")
+	}
+	// The blocks executed from the invalid one on restore the state
+	processor := chain.processor.(*diffProcessor)
+	processor.Processor = processor.Processor.(rejectProcessor).Processor
+
+	chain.reimportDiffBlock(tampered.Header(), "test")
+	if len(dropped) != 1 || dropped[0] != "peer" {
+		t.Fatalf("dropped peers mismatch: %v", dropped)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != blocks[len(blocks)-1].NumberU64() {
+		t.Fatalf("head mismatch: have %d, want %d", head, blocks[len(blocks)-1].NumberU64())
+	}
+	have, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if have.GetNonce(testAddr) != want.GetNonce(testAddr) || have.GetBalance(testAddr).Cmp(want.GetBalance(testAddr)) != 0 {
+		t.Fatalf("state mismatch: have %v/%d, want %v/%d", have.GetBalance(testAddr), have.GetNonce(testAddr), want.GetBalance(testAddr), want.GetNonce(testAddr))
+	}
+	// The executed blocks are not imported from diff layers anymore
+	if _, ok := chain.appliedDiffPeer(tampered.Hash()); ok {
+		t.Fatal("reimported block still imported from diff layer")
+	}
+}
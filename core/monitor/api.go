@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/gopool"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// API is a user facing RPC API to inspect the evidence found by the monitors.
+type API struct {
+	doubleSign *DoubleSignMonitor
+}
+
+// NewAPI creates the monitor RPC service, doubleSign may be nil if the double
+// sign monitor is disabled.
+func NewAPI(doubleSign *DoubleSignMonitor) *API {
+	return &API{doubleSign: doubleSign}
+}
+
+// GetDoubleSignEvidence returns all the double sign evidence found by the node,
+// whatever their submission status.
+func (api *API) GetDoubleSignEvidence() []*DoubleSignEvidence {
+	if api.doubleSign == nil {
+		return nil
+	}
+	return api.doubleSign.Evidence()
+}
+
+// GetPendingDoubleSignEvidence returns the double sign evidence which has not
+// been accepted by the SlashIndicator contract yet.
+func (api *API) GetPendingDoubleSignEvidence() []*DoubleSignEvidence {
+	var pending []*DoubleSignEvidence
+	for _, evidence := range api.GetDoubleSignEvidence() {
+		if !evidence.done() {
+			pending = append(pending, evidence)
+		}
+	}
+	return pending
+}
+
+// MaliciousVoteAPI is a user facing RPC API to query the malicious vote evidence,
+// the evidence is returned in the json format expected by submitFinalityViolationEvidence.
+type MaliciousVoteAPI struct {
+	monitor *MaliciousVoteMonitor
+}
+
+// NewMaliciousVoteAPI creates the malicious vote RPC service.
+func NewMaliciousVoteAPI(monitor *MaliciousVoteMonitor) *MaliciousVoteAPI {
+	return &MaliciousVoteAPI{monitor: monitor}
+}
+
+// GetMaliciousVoteEvidence returns the malicious vote evidence found by the node,
+// restricted to the given BLS vote address if it's specified.
+func (api *MaliciousVoteAPI) GetMaliciousVoteEvidence(voteAddress *hexutil.Bytes) ([]*types.SlashIndicatorFinalityEvidenceWrapper, error) {
+	if voteAddress == nil {
+		return api.monitor.Evidence(nil), nil
+	}
+	if len(*voteAddress) != types.BLSPublicKeyLength {
+		return nil, fmt.Errorf("invalid vote address length %d, want %d", len(*voteAddress), types.BLSPublicKeyLength)
+	}
+	var addr types.BLSPublicKey
+	copy(addr[:], *voteAddress)
+	return api.monitor.Evidence(&addr), nil
+}
+
+// NewMaliciousVoteEvidence send a notification each time a new malicious vote evidence is found.
+func (api *MaliciousVoteAPI) NewMaliciousVoteEvidence(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	gopool.Submit(func() {
+		evidenceCh := make(chan *types.SlashIndicatorFinalityEvidenceWrapper, 16)
+		evidenceSub := api.monitor.SubscribeEvidence(evidenceCh)
+		defer evidenceSub.Unsubscribe()
+
+		for {
+			select {
+			case evidence := <-evidenceCh:
+				notifier.Notify(rpcSub.ID, evidence)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	})
+
+	return rpcSub, nil
+}
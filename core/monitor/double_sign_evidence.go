@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EvidenceStatus is the submission state of a piece of slashing evidence.
+type EvidenceStatus string
+
+const (
+	EvidencePending   EvidenceStatus = "pending"   // detected, not submitted yet
+	EvidenceSent      EvidenceStatus = "sent"      // transaction sent, waiting for its receipt
+	EvidenceSubmitted EvidenceStatus = "submitted" // transaction executed successfully by the SlashIndicator contract
+	EvidenceFailed    EvidenceStatus = "failed"    // gave up after maxEvidenceSubmitAttempts
+)
+
+const (
+	// maxEvidenceSubmitAttempts is the number of submissions tried before an
+	// evidence is marked as failed.
+	maxEvidenceSubmitAttempts = 10
+
+	// evidenceRetryInterval is the minimum delay between two submissions of
+	// the same evidence.
+	evidenceRetryInterval = time.Minute
+
+	// evidenceReceiptTimeout is the time waited for the receipt of a sent
+	// evidence before it's submitted again.
+	evidenceReceiptTimeout = 5 * time.Minute
+
+	// EvidenceSubmitInterval is the interval of sending the pending evidence
+	// and looking up the receipts of the sent one.
+	EvidenceSubmitInterval = 3 * time.Second
+)
+
+// submitDoubleSignEvidenceABI is the SlashIndicator method used to report a double sign.
+const submitDoubleSignEvidenceABI = `[{"type":"function","name":"submitDoubleSignEvidence","inputs":[{"name":"header1","type":"bytes","internalType":"bytes"},{"name":"header2","type":"bytes","internalType":"bytes"}],"outputs":[],"stateMutability":"nonpayable"}]`
+
+var doubleSignABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(submitDoubleSignEvidenceABI))
+	if err != nil {
+		panic(err)
+	}
+	doubleSignABI = parsed
+}
+
+// EvidenceSubmitFn sends a transaction calling the given contract with the given
+// input, and returns the hash of the sent transaction.
+type EvidenceSubmitFn func(to common.Address, data []byte) (common.Hash, error)
+
+// EvidenceReceiptFn returns the receipt of the given transaction, nil if it's
+// not included in the chain yet.
+type EvidenceReceiptFn func(txHash common.Hash) *types.Receipt
+
+// DoubleSignEvidence is a pair of headers signed by the same validator at the
+// same height, together with its submission state.
+type DoubleSignEvidence struct {
+	Number   uint64         `json:"number"`
+	Miner    common.Address `json:"miner"`
+	Hash1    common.Hash    `json:"hash1"`
+	Hash2    common.Hash    `json:"hash2"`
+	Header1  hexutil.Bytes  `json:"header1"` // rlp encoded header
+	Header2  hexutil.Bytes  `json:"header2"` // rlp encoded header
+	Detected uint64         `json:"detected"`
+
+	Status      EvidenceStatus `json:"status"`
+	Attempts    int            `json:"attempts"`
+	LastAttempt uint64         `json:"lastAttempt,omitempty"`
+	LastError   string         `json:"lastError,omitempty"`
+	TxHash      *common.Hash   `json:"txHash,omitempty"`
+}
+
+func newDoubleSignEvidence(h1, h2 *types.Header) (*DoubleSignEvidence, error) {
+	h1Bytes, err := rlp.EncodeToBytes(h1)
+	if err != nil {
+		return nil, err
+	}
+	h2Bytes, err := rlp.EncodeToBytes(h2)
+	if err != nil {
+		return nil, err
+	}
+	return &DoubleSignEvidence{
+		Number:   h1.Number.Uint64(),
+		Miner:    h1.Coinbase,
+		Hash1:    h1.Hash(),
+		Hash2:    h2.Hash(),
+		Header1:  h1Bytes,
+		Header2:  h2Bytes,
+		Detected: uint64(time.Now().Unix()),
+		Status:   EvidencePending,
+	}, nil
+}
+
+// done reports whether the evidence needs no further submission.
+func (e *DoubleSignEvidence) done() bool {
+	return e.Status == EvidenceSubmitted || e.Status == EvidenceFailed
+}
+
+// CallData returns the input of the SlashIndicator.submitDoubleSignEvidence call.
+func (e *DoubleSignEvidence) CallData() ([]byte, error) {
+	return doubleSignABI.Pack("submitDoubleSignEvidence", []byte(e.Header1), []byte(e.Header2))
+}
+
+// doubleSignEvidenceKey = DoubleSignEvidencePrefix + num (uint64 big endian) + hash + hash,
+// the two hashes are ordered so that the key doesn't depend on arrival order.
+func doubleSignEvidenceKey(number uint64, hash1, hash2 common.Hash) []byte {
+	if bytes.Compare(hash1[:], hash2[:]) > 0 {
+		hash1, hash2 = hash2, hash1
+	}
+	key := make([]byte, len(rawdb.DoubleSignEvidencePrefix)+8+2*common.HashLength)
+	n := copy(key, rawdb.DoubleSignEvidencePrefix)
+	binary.BigEndian.PutUint64(key[n:], number)
+	n += 8
+	n += copy(key[n:], hash1[:])
+	copy(key[n:], hash2[:])
+	return key
+}
+
+func (e *DoubleSignEvidence) key() []byte {
+	return doubleSignEvidenceKey(e.Number, e.Hash1, e.Hash2)
+}
+
+// readDoubleSignEvidence retrieves the evidence for the given header pair, nil if not found.
+func readDoubleSignEvidence(db ethdb.KeyValueReader, number uint64, hash1, hash2 common.Hash) *DoubleSignEvidence {
+	blob, err := db.Get(doubleSignEvidenceKey(number, hash1, hash2))
+	if err != nil || len(blob) == 0 {
+		return nil
+	}
+	evidence := new(DoubleSignEvidence)
+	if err := json.Unmarshal(blob, evidence); err != nil {
+		log.Error("Invalid double sign evidence in database", "number", number, "err", err)
+		return nil
+	}
+	return evidence
+}
+
+// writeDoubleSignEvidence stores the evidence into the database.
+func writeDoubleSignEvidence(db ethdb.KeyValueWriter, evidence *DoubleSignEvidence) {
+	blob, err := json.Marshal(evidence)
+	if err != nil {
+		log.Crit("Failed to encode double sign evidence", "err", err)
+	}
+	if err := db.Put(evidence.key(), blob); err != nil {
+		log.Crit("Failed to store double sign evidence", "err", err)
+	}
+}
+
+// readAllDoubleSignEvidence retrieves all the stored evidence, ordered by block number.
+func readAllDoubleSignEvidence(db ethdb.Iteratee) []*DoubleSignEvidence {
+	it := db.NewIterator(rawdb.DoubleSignEvidencePrefix, nil)
+	defer it.Release()
+
+	var list []*DoubleSignEvidence
+	for it.Next() {
+		if len(it.Key()) != len(rawdb.DoubleSignEvidencePrefix)+8+2*common.HashLength {
+			continue
+		}
+		evidence := new(DoubleSignEvidence)
+		if err := json.Unmarshal(it.Value(), evidence); err != nil {
+			log.Error("Invalid double sign evidence in database", "key", hexutil.Encode(it.Key()), "err", err)
+			continue
+		}
+		list = append(list, evidence)
+	}
+	return list
+}
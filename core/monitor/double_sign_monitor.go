@@ -2,28 +2,89 @@ package monitor
 
 import (
 	"bytes"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/prque"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
+	// MaxCacheHeader is the default number of recent heights whose headers are
+	// kept for comparison.
 	MaxCacheHeader = 100
+
+	// maxHeadersPerSigner bounds the headers kept per validator at a single height,
+	// any two of them with the same parent already make an evidence.
+	maxHeadersPerSigner = 8
 )
 
-func NewDoubleSignMonitor() *DoubleSignMonitor {
+// NewDoubleSignMonitor creates a double sign monitor which keeps the headers of the
+// latest retention heights, MaxCacheHeader is used if it's 0. Found evidence is
+// persisted into db so that it survives restarts, the evidence not submitted yet
+// is loaded back into memory here.
+func NewDoubleSignMonitor(db ethdb.KeyValueStore, retention uint64) *DoubleSignMonitor {
+	if retention == 0 {
+		retention = MaxCacheHeader
+	}
+	pending := make(map[string]*DoubleSignEvidence)
+	for _, evidence := range readAllDoubleSignEvidence(db) {
+		if !evidence.done() {
+			pending[string(evidence.key())] = evidence
+		}
+	}
 	return &DoubleSignMonitor{
-		headerNumbers: prque.New[int64, *types.Header](nil),
-		headers:       make(map[uint64]*types.Header, MaxCacheHeader),
+		db:        db,
+		retention: retention,
+		heights:   prque.New[int64, uint64](nil),
+		headers:   make(map[uint64]map[common.Address][]*types.Header),
+		pending:   pending,
 	}
 }
 
+// DoubleSignMonitor detects validators signing multiple headers at the same height.
+// Headers are fed from both the local import path and the block fetcher, so that
+// headers of competing forks which are never imported are compared as well.
 type DoubleSignMonitor struct {
-	headerNumbers *prque.Prque[int64, *types.Header]
-	headers       map[uint64]*types.Header
+	db        ethdb.KeyValueStore
+	retention uint64
+
+	cacheLock sync.Mutex                                    // protects the header cache below
+	heights   *prque.Prque[int64, uint64]                   // cached heights, lowest first
+	headers   map[uint64]map[common.Address][]*types.Header // cached headers indexed by height and signer
+	highest   uint64                                        // highest height seen so far
+
+	lock      sync.Mutex                     // protects the evidence store and the submitter
+	pending   map[string]*DoubleSignEvidence // evidence not submitted yet, indexed by db key
+	submitFn  EvidenceSubmitFn
+	receiptFn EvidenceReceiptFn
+}
+
+// SetSubmitter enables the automatic submission of the found evidence to the
+// SlashIndicator contract. An evidence is only considered submitted once receiptFn
+// returns a successful receipt for its transaction, it's sent again if the
+// transaction fails or isn't included within evidenceReceiptTimeout. The pending
+// evidence, including the one left from a previous run, is submitted by the
+// periodic calls of SubmitPending.
+func (m *DoubleSignMonitor) SetSubmitter(submitFn EvidenceSubmitFn, receiptFn EvidenceReceiptFn) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.submitFn = submitFn
+	m.receiptFn = receiptFn
+}
+
+// Evidence returns all the double sign evidence found so far.
+func (m *DoubleSignMonitor) Evidence() []*DoubleSignEvidence {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return readAllDoubleSignEvidence(m.db)
 }
 
 func (m *DoubleSignMonitor) isDoubleSignHeaders(h1, h2 *types.Header) (bool, error) {
@@ -49,33 +110,64 @@ func (m *DoubleSignMonitor) isDoubleSignHeaders(h1, h2 *types.Header) (bool, err
 	return true, nil
 }
 
-func (m *DoubleSignMonitor) deleteOldHeader() {
-	h, _ := m.headerNumbers.Pop()
-	delete(m.headers, h.Number.Uint64())
-}
-
+// checkHeader caches the header and returns a previously seen header of the same
+// signer conflicting with it, if any.
 func (m *DoubleSignMonitor) checkHeader(h *types.Header) (bool, *types.Header, error) {
-	h2, exist := m.headers[h.Number.Uint64()]
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+
+	number := h.Number.Uint64()
+	if number > m.highest {
+		m.highest = number
+		m.pruneHeaders()
+	}
+	if number+m.retention <= m.highest {
+		return false, nil, nil
+	}
+	signers, exist := m.headers[number]
 	if !exist {
-		if m.headerNumbers.Size() > MaxCacheHeader {
-			m.deleteOldHeader()
+		signers = make(map[common.Address][]*types.Header)
+		m.headers[number] = signers
+		m.heights.Push(number, -int64(number))
+	}
+	hash := h.Hash()
+	seen := signers[h.Coinbase]
+	for _, h2 := range seen {
+		if h2.Hash() == hash {
+			return false, nil, nil
 		}
-		m.headers[h.Number.Uint64()] = h
-		m.headerNumbers.Push(h, -h.Number.Int64())
-		return false, nil, nil
 	}
-
-	isDoubleSign, err := m.isDoubleSignHeaders(h, h2)
-	if err != nil {
-		return false, nil, err
+	if len(seen) < maxHeadersPerSigner {
+		signers[h.Coinbase] = append(seen, h)
 	}
-	if isDoubleSign {
-		return true, h2, nil
+	for _, h2 := range seen {
+		isDoubleSign, err := m.isDoubleSignHeaders(h, h2)
+		if err != nil {
+			return false, nil, err
+		}
+		if isDoubleSign {
+			return true, h2, nil
+		}
 	}
-
 	return false, nil, nil
 }
 
+// pruneHeaders drops the cached heights falling out of the retention window.
+func (m *DoubleSignMonitor) pruneHeaders() {
+	for !m.heights.Empty() {
+		number, _ := m.heights.Peek()
+		if number+m.retention > m.highest {
+			return
+		}
+		m.heights.Pop()
+		delete(m.headers, number)
+	}
+}
+
+// Verify checks the header against the cached headers of the same signer and
+// records an evidence if it's a double sign. It's safe for concurrent use, but the
+// seal of the header must have been verified by the caller since the signer is
+// taken from the coinbase.
 func (m *DoubleSignMonitor) Verify(h *types.Header) {
 	isDoubleSign, h2, err := m.checkHeader(h)
 	if err != nil {
@@ -87,16 +179,126 @@ func (m *DoubleSignMonitor) Verify(h *types.Header) {
 		log.Warn("found a double sign header", "number", h.Number.Uint64(),
 			"first_hash", h.Hash(), "first_miner", h.Coinbase,
 			"second_hash", h2.Hash(), "second_miner", h2.Coinbase)
-		h1Bytes, err := rlp.EncodeToBytes(h)
+		evidence, err := newDoubleSignEvidence(h, h2)
 		if err != nil {
 			log.Error("encode header error", "err", err, "hash", h.Hash())
+		} else {
+			log.Warn("double sign header content",
+				"header1", hexutil.Encode(evidence.Header1),
+				"header2", hexutil.Encode(evidence.Header2))
+			m.addEvidence(evidence)
 		}
-		h2Bytes, err := rlp.EncodeToBytes(h2)
-		if err != nil {
-			log.Error("encode header error", "err", err, "hash", h.Hash())
+	}
+}
+
+// addEvidence stores the evidence unless it has been seen before.
+func (m *DoubleSignMonitor) addEvidence(evidence *DoubleSignEvidence) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if readDoubleSignEvidence(m.db, evidence.Number, evidence.Hash1, evidence.Hash2) != nil {
+		return
+	}
+	writeDoubleSignEvidence(m.db, evidence)
+	m.pending[string(evidence.key())] = evidence
+}
+
+// SubmitPending sends every pending evidence to the SlashIndicator contract if
+// a submitter is configured, and follows up on the evidence already sent.
+// Submitted evidence is never sent again. The transactions are sent and the
+// receipts looked up without holding the lock, so it must not be called
+// concurrently with itself.
+func (m *DoubleSignMonitor) SubmitPending() {
+	m.lock.Lock()
+	if m.submitFn == nil || len(m.pending) == 0 {
+		m.lock.Unlock()
+		return
+	}
+	pending := make(map[string]*DoubleSignEvidence, len(m.pending))
+	for key, evidence := range m.pending {
+		pending[key] = evidence
+	}
+	submitFn, receiptFn := m.submitFn, m.receiptFn
+	m.lock.Unlock()
+
+	now := time.Now()
+	changed := make(map[string]*DoubleSignEvidence)
+	for key, evidence := range pending {
+		if evidence.Status == EvidenceSent && checkReceipt(evidence, receiptFn, now) {
+			changed[key] = evidence
+		}
+		if evidence.Status == EvidencePending &&
+			(evidence.LastAttempt == 0 || !now.Before(time.Unix(int64(evidence.LastAttempt), 0).Add(evidenceRetryInterval))) {
+			trySubmit(evidence, submitFn, now)
+			changed[key] = evidence
+		}
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for key, evidence := range changed {
+		writeDoubleSignEvidence(m.db, evidence)
+		if evidence.done() {
+			delete(m.pending, key)
 		}
-		log.Warn("double sign header content",
-			"header1", hexutil.Encode(h1Bytes),
-			"header2", hexutil.Encode(h2Bytes))
 	}
 }
+
+// trySubmit sends the transaction of a pending evidence.
+func trySubmit(evidence *DoubleSignEvidence, submitFn EvidenceSubmitFn, now time.Time) {
+	evidence.Attempts++
+	evidence.LastAttempt = uint64(now.Unix())
+
+	txHash, err := submit(evidence, submitFn)
+	if err != nil {
+		log.Error("Failed to submit double sign evidence", "number", evidence.Number, "miner", evidence.Miner,
+			"attempts", evidence.Attempts, "err", err)
+		evidence.LastError = err.Error()
+		if evidence.Attempts >= maxEvidenceSubmitAttempts {
+			evidence.Status = EvidenceFailed
+		}
+		return
+	}
+	log.Info("Sent double sign evidence", "number", evidence.Number, "miner", evidence.Miner, "tx", txHash)
+	evidence.LastError = ""
+	evidence.Status = EvidenceSent
+	evidence.TxHash = &txHash
+}
+
+// checkReceipt looks up the receipt of a sent evidence. The evidence is marked
+// submitted if its transaction succeeded, and goes back to pending if the
+// transaction reverted or wasn't included in time. It returns whether the
+// evidence changed.
+func checkReceipt(evidence *DoubleSignEvidence, receiptFn EvidenceReceiptFn, now time.Time) bool {
+	var receipt *types.Receipt
+	if receiptFn != nil {
+		receipt = receiptFn(*evidence.TxHash)
+	}
+	switch {
+	case receipt != nil && receipt.Status == types.ReceiptStatusSuccessful:
+		log.Info("Submitted double sign evidence", "number", evidence.Number, "miner", evidence.Miner, "tx", *evidence.TxHash)
+		evidence.Status = EvidenceSubmitted
+		return true
+	case receipt != nil:
+		evidence.LastError = "evidence transaction reverted"
+	case now.After(time.Unix(int64(evidence.LastAttempt), 0).Add(evidenceReceiptTimeout)):
+		evidence.LastError = "evidence transaction not included in time"
+	default:
+		return false
+	}
+	log.Warn("Double sign evidence not accepted", "number", evidence.Number, "miner", evidence.Miner,
+		"tx", *evidence.TxHash, "attempts", evidence.Attempts, "err", evidence.LastError)
+	evidence.Status = EvidencePending
+	if evidence.Attempts >= maxEvidenceSubmitAttempts {
+		evidence.Status = EvidenceFailed
+	}
+	return true
+}
+
+func submit(evidence *DoubleSignEvidence, submitFn EvidenceSubmitFn) (common.Hash, error) {
+	data, err := evidence.CallData()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitFn(common.HexToAddress(systemcontracts.SlashContract), data)
+}
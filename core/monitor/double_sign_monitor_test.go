@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDoubleSignHeaders(number int64) (*types.Header, *types.Header) {
+	h1 := &types.Header{
+		ParentHash: common.HexToHash("0x01"),
+		Number:     big.NewInt(number),
+		Coinbase:   common.HexToAddress("0x02"),
+		Difficulty: big.NewInt(2),
+		Extra:      []byte("first"),
+	}
+	h2 := types.CopyHeader(h1)
+	h2.Extra = []byte("second")
+	return h1, h2
+}
+
+func TestDoubleSignMonitorSubmit(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	m := NewDoubleSignMonitor(db, 0)
+
+	h1, h2 := newDoubleSignHeaders(100)
+	m.Verify(h1)
+	assert.Empty(t, m.Evidence())
+
+	// without submitter the evidence stays pending
+	m.Verify(h2)
+	evidence := m.Evidence()
+	assert.Equal(t, 1, len(evidence))
+	assert.Equal(t, EvidencePending, evidence[0].Status)
+	assert.Equal(t, uint64(100), evidence[0].Number)
+
+	var (
+		submitted int
+		receipts  = make(map[common.Hash]*types.Receipt)
+	)
+	receiptFn := func(txHash common.Hash) *types.Receipt { return receipts[txHash] }
+	m.SetSubmitter(func(to common.Address, data []byte) (common.Hash, error) {
+		assert.Equal(t, common.HexToAddress(systemcontracts.SlashContract), to)
+		// the transaction is sent without holding the monitor lock
+		assert.Equal(t, 1, len(m.Evidence()))
+		submitted++
+		return common.HexToHash("0xaa"), nil
+	}, receiptFn)
+	m.SubmitPending()
+	assert.Equal(t, 1, submitted)
+
+	// the evidence is only submitted once its transaction succeeded
+	evidence = m.Evidence()
+	assert.Equal(t, EvidenceSent, evidence[0].Status)
+	assert.Equal(t, common.HexToHash("0xaa"), *evidence[0].TxHash)
+
+	receipts[common.HexToHash("0xaa")] = &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	m.SubmitPending()
+	assert.Equal(t, 1, submitted)
+	assert.Equal(t, EvidenceSubmitted, m.Evidence()[0].Status)
+	assert.Empty(t, m.pending)
+
+	// a restarted monitor must neither record nor submit the same evidence twice
+	m = NewDoubleSignMonitor(db, 0)
+	m.SetSubmitter(func(to common.Address, data []byte) (common.Hash, error) {
+		submitted++
+		return common.Hash{}, nil
+	}, receiptFn)
+	m.Verify(h2)
+	m.Verify(h1)
+	m.SubmitPending()
+	assert.Equal(t, 1, submitted)
+	assert.Equal(t, 1, len(m.Evidence()))
+}
+
+func TestDoubleSignMonitorResubmit(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	m := NewDoubleSignMonitor(db, 0)
+
+	var (
+		submitted int
+		receipt   *types.Receipt
+	)
+	m.SetSubmitter(func(to common.Address, data []byte) (common.Hash, error) {
+		submitted++
+		return common.BigToHash(big.NewInt(int64(submitted))), nil
+	}, func(txHash common.Hash) *types.Receipt { return receipt })
+
+	h1, h2 := newDoubleSignHeaders(100)
+	m.Verify(h1)
+	m.Verify(h2)
+	assert.Equal(t, 0, submitted)
+	m.SubmitPending()
+	assert.Equal(t, 1, submitted)
+
+	// a reverted transaction makes the evidence pending again
+	receipt = &types.Receipt{Status: types.ReceiptStatusFailed}
+	m.SubmitPending()
+	evidence := m.Evidence()[0]
+	assert.Equal(t, EvidencePending, evidence.Status)
+	assert.Equal(t, "evidence transaction reverted", evidence.LastError)
+
+	// the pending evidence is loaded back on restart and sent again once the
+	// retry interval passed
+	m = NewDoubleSignMonitor(db, 0)
+	m.SetSubmitter(func(to common.Address, data []byte) (common.Hash, error) {
+		submitted++
+		return common.BigToHash(big.NewInt(int64(submitted))), nil
+	}, func(txHash common.Hash) *types.Receipt { return nil })
+	for _, evidence := range m.pending {
+		evidence.LastAttempt -= uint64(evidenceRetryInterval / time.Second)
+	}
+	m.SubmitPending()
+	assert.Equal(t, 2, submitted)
+	assert.Equal(t, EvidenceSent, m.Evidence()[0].Status)
+
+	// a transaction not included in time is sent again as well
+	for _, evidence := range m.pending {
+		evidence.LastAttempt -= uint64(evidenceReceiptTimeout / time.Second)
+	}
+	m.SubmitPending()
+	assert.Equal(t, 3, submitted)
+	evidence = m.Evidence()[0]
+	assert.Equal(t, EvidenceSent, evidence.Status)
+	assert.Equal(t, 3, evidence.Attempts)
+	assert.Equal(t, common.BigToHash(big.NewInt(3)), *evidence.TxHash)
+}
+
+func TestDoubleSignMonitorSubmitFailure(t *testing.T) {
+	m := NewDoubleSignMonitor(rawdb.NewMemoryDatabase(), 0)
+	m.SetSubmitter(func(to common.Address, data []byte) (common.Hash, error) {
+		return common.Hash{}, errors.New("account locked")
+	}, func(txHash common.Hash) *types.Receipt { return nil })
+	h1, h2 := newDoubleSignHeaders(100)
+	m.Verify(h1)
+	m.Verify(h2)
+	m.SubmitPending()
+
+	evidence := m.Evidence()
+	assert.Equal(t, 1, len(evidence))
+	assert.Equal(t, EvidencePending, evidence[0].Status)
+	assert.Equal(t, 1, evidence[0].Attempts)
+	assert.Equal(t, "account locked", evidence[0].LastError)
+
+	// retries are throttled
+	m.SubmitPending()
+	assert.Equal(t, 1, m.Evidence()[0].Attempts)
+}
+
+func TestDoubleSignMonitorCompetingForks(t *testing.T) {
+	m := NewDoubleSignMonitor(rawdb.NewMemoryDatabase(), 10)
+
+	// headers of other validators or other parents at the same height are no evidence
+	h1, h2 := newDoubleSignHeaders(100)
+	other := types.CopyHeader(h2)
+	other.Coinbase = common.HexToAddress("0x03")
+	fork := types.CopyHeader(h2)
+	fork.ParentHash = common.HexToHash("0x04")
+	m.Verify(h1)
+	m.Verify(h1)
+	m.Verify(other)
+	m.Verify(fork)
+	assert.Empty(t, m.Evidence())
+
+	// the competing header arrives after the chain moved on, but within retention
+	m.Verify(&types.Header{Number: big.NewInt(109)})
+	m.Verify(h2)
+	assert.Equal(t, 1, len(m.Evidence()))
+
+	// headers falling out of retention are forgotten
+	h3, h4 := newDoubleSignHeaders(101)
+	m.Verify(h3)
+	m.Verify(&types.Header{Number: big.NewInt(111)})
+	m.Verify(h4)
+	assert.Equal(t, 1, len(m.Evidence()))
+	assert.Equal(t, 2, m.heights.Size())
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maliciousVoteEvidenceKey = MaliciousVoteEvidencePrefix + vote address + target num + target num,
+// the target numbers are ordered so that the key doesn't depend on arrival order.
+func maliciousVoteEvidenceKey(voteAddress types.BLSPublicKey, target1, target2 uint64) []byte {
+	if target1 > target2 {
+		target1, target2 = target2, target1
+	}
+	key := make([]byte, len(rawdb.MaliciousVoteEvidencePrefix)+types.BLSPublicKeyLength+16)
+	n := copy(key, rawdb.MaliciousVoteEvidencePrefix)
+	n += copy(key[n:], voteAddress[:])
+	binary.BigEndian.PutUint64(key[n:], target1)
+	binary.BigEndian.PutUint64(key[n+8:], target2)
+	return key
+}
+
+// hasMaliciousVoteEvidence reports whether the evidence of the given vote pair is stored.
+func hasMaliciousVoteEvidence(db ethdb.KeyValueReader, voteAddress types.BLSPublicKey, target1, target2 uint64) bool {
+	has, _ := db.Has(maliciousVoteEvidenceKey(voteAddress, target1, target2))
+	return has
+}
+
+// writeMaliciousVoteEvidence stores the evidence in the submitFinalityViolationEvidence json format.
+func writeMaliciousVoteEvidence(db ethdb.KeyValueWriter, voteAddress types.BLSPublicKey, evidence *types.SlashIndicatorFinalityEvidenceWrapper) {
+	blob, err := json.Marshal(evidence)
+	if err != nil {
+		log.Crit("Failed to encode malicious vote evidence", "err", err)
+	}
+	key := maliciousVoteEvidenceKey(voteAddress, evidence.VoteA.TarNum.Uint64(), evidence.VoteB.TarNum.Uint64())
+	if err := db.Put(key, blob); err != nil {
+		log.Crit("Failed to store malicious vote evidence", "err", err)
+	}
+}
+
+// readMaliciousVoteEvidence retrieves the stored evidence, restricted to the given
+// vote address if it's not nil.
+func readMaliciousVoteEvidence(db ethdb.Iteratee, voteAddress *types.BLSPublicKey) []*types.SlashIndicatorFinalityEvidenceWrapper {
+	prefix := rawdb.MaliciousVoteEvidencePrefix
+	if voteAddress != nil {
+		prefix = append(append([]byte{}, prefix...), voteAddress[:]...)
+	}
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var list []*types.SlashIndicatorFinalityEvidenceWrapper
+	for it.Next() {
+		if len(it.Key()) != len(rawdb.MaliciousVoteEvidencePrefix)+types.BLSPublicKeyLength+16 {
+			continue
+		}
+		evidence := new(types.SlashIndicatorFinalityEvidenceWrapper)
+		if err := json.Unmarshal(it.Value(), evidence); err != nil {
+			log.Error("Invalid malicious vote evidence in database", "key", hexutil.Encode(it.Key()), "err", err)
+			continue
+		}
+		list = append(list, evidence)
+	}
+	return list
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	lru "github.com/hashicorp/golang-lru"
@@ -23,17 +25,43 @@ var (
 
 // two purposes
 // 1. monitor whether there are bugs in the voting mechanism, so add metrics to observe it.
-// 2. do malicious vote slashing. TODO
+// 2. do malicious vote slashing, the evidence is persisted and can be submitted
+// to the SlashIndicator contract with submitFinalityViolationEvidence.
 type MaliciousVoteMonitor struct {
+	db       ethdb.KeyValueStore
 	curVotes map[types.BLSPublicKey]*lru.Cache
+
+	evidenceFeed event.Feed
 }
 
-func NewMaliciousVoteMonitor() *MaliciousVoteMonitor {
+func NewMaliciousVoteMonitor(db ethdb.KeyValueStore) *MaliciousVoteMonitor {
 	return &MaliciousVoteMonitor{
+		db:       db,
 		curVotes: make(map[types.BLSPublicKey]*lru.Cache, 21), // mainnet config
 	}
 }
 
+// Evidence returns the stored malicious vote evidence, restricted to the given
+// vote address if it's not nil.
+func (m *MaliciousVoteMonitor) Evidence(voteAddress *types.BLSPublicKey) []*types.SlashIndicatorFinalityEvidenceWrapper {
+	return readMaliciousVoteEvidence(m.db, voteAddress)
+}
+
+// SubscribeEvidence registers a subscription for newly found malicious vote evidence.
+func (m *MaliciousVoteMonitor) SubscribeEvidence(ch chan<- *types.SlashIndicatorFinalityEvidenceWrapper) event.Subscription {
+	return m.evidenceFeed.Subscribe(ch)
+}
+
+// recordEvidence persists the evidence and notifies the subscribers, evidence
+// already known is ignored.
+func (m *MaliciousVoteMonitor) recordEvidence(voteAddress types.BLSPublicKey, evidence *types.SlashIndicatorFinalityEvidenceWrapper) {
+	if hasMaliciousVoteEvidence(m.db, voteAddress, evidence.VoteA.TarNum.Uint64(), evidence.VoteB.TarNum.Uint64()) {
+		return
+	}
+	writeMaliciousVoteEvidence(m.db, voteAddress, evidence)
+	m.evidenceFeed.Send(evidence)
+}
+
 func (m *MaliciousVoteMonitor) ConflictDetect(newVote *types.VoteEnvelope, pendingBlockNumber uint64) bool {
 	// get votes for specified VoteAddress
 	if _, ok := m.curVotes[newVote.VoteAddress]; !ok {
@@ -83,6 +111,7 @@ func (m *MaliciousVoteMonitor) ConflictDetect(newVote *types.VoteEnvelope, pendi
 					} else {
 						log.Warn("MaliciousVote, Marshal evidence failed")
 					}
+					m.recordEvidence(newVote.VoteAddress, evidence)
 				} else {
 					log.Warn("MaliciousVote, construct evidence failed")
 				}
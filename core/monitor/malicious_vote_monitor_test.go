@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -12,7 +13,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 	//log.Root().SetHandler(log.StdoutHandler)
 	// case 1, different voteAddress
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -44,7 +45,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 2, target number not in maliciousVoteSlashScope
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -75,7 +76,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 3, violate rule1
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -106,7 +107,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 4,  violate rule2, vote with smaller range first
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -137,7 +138,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 5,  violate rule2, vote with larger range first
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -168,7 +169,7 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 
 	// case 6, normal case
 	{
-		maliciousVoteMonitor := NewMaliciousVoteMonitor()
+		maliciousVoteMonitor := NewMaliciousVoteMonitor(rawdb.NewMemoryDatabase())
 		pendingBlockNumber := uint64(1000)
 		voteAddrBytes := common.Hex2BytesFixed("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001", types.BLSPublicKeyLength)
 		voteAddress := types.BLSPublicKey{}
@@ -208,3 +209,50 @@ func TestMaliciousVoteMonitor(t *testing.T) {
 		assert.Equal(t, false, maliciousVoteMonitor.ConflictDetect(vote3, pendingBlockNumber))
 	}
 }
+
+func TestMaliciousVoteEvidenceStore(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	maliciousVoteMonitor := NewMaliciousVoteMonitor(db)
+	evidenceCh := make(chan *types.SlashIndicatorFinalityEvidenceWrapper, 1)
+	sub := maliciousVoteMonitor.SubscribeEvidence(evidenceCh)
+	defer sub.Unsubscribe()
+
+	pendingBlockNumber := uint64(1000)
+	voteAddress := types.BLSPublicKey{1}
+	vote1 := &types.VoteEnvelope{
+		VoteAddress: voteAddress,
+		Signature:   types.BLSSignature{1},
+		Data: &types.VoteData{
+			SourceNumber: uint64(0),
+			SourceHash:   common.BytesToHash(common.Hex2Bytes("00")),
+			TargetNumber: pendingBlockNumber - 1,
+			TargetHash:   common.BytesToHash(common.Hex2Bytes("01")),
+		},
+	}
+	vote2 := &types.VoteEnvelope{
+		VoteAddress: voteAddress,
+		Signature:   types.BLSSignature{2},
+		Data: &types.VoteData{
+			SourceNumber: uint64(0),
+			SourceHash:   common.BytesToHash(common.Hex2Bytes("00")),
+			TargetNumber: pendingBlockNumber - 1,
+			TargetHash:   common.BytesToHash(common.Hex2Bytes("02")),
+		},
+	}
+	assert.Equal(t, false, maliciousVoteMonitor.ConflictDetect(vote1, pendingBlockNumber))
+	assert.Equal(t, true, maliciousVoteMonitor.ConflictDetect(vote2, pendingBlockNumber))
+
+	evidence := <-evidenceCh
+	assert.Equal(t, common.Bytes2Hex(voteAddress[:]), evidence.VoteAddr)
+	assert.Equal(t, common.Bytes2Hex(vote1.Signature[:]), evidence.VoteA.Sig)
+	assert.Equal(t, common.Bytes2Hex(vote2.Signature[:]), evidence.VoteB.Sig)
+
+	// the evidence survives a restart and is not reported twice
+	maliciousVoteMonitor = NewMaliciousVoteMonitor(db)
+	assert.Equal(t, 1, len(maliciousVoteMonitor.Evidence(nil)))
+	assert.Equal(t, 1, len(maliciousVoteMonitor.Evidence(&voteAddress)))
+	assert.Equal(t, 0, len(maliciousVoteMonitor.Evidence(&types.BLSPublicKey{2})))
+
+	maliciousVoteMonitor.recordEvidence(voteAddress, evidence)
+	assert.Equal(t, 1, len(maliciousVoteMonitor.Evidence(nil)))
+}
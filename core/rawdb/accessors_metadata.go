@@ -227,6 +227,22 @@ func WriteOffSetOfLastAncientFreezer(db ethdb.KeyValueWriter, offset uint64) {
 	}
 }
 
+// ReadOffSetOfRetainedAncientFreezer return the start of the tables retained by the pruned freezer
+func ReadOffSetOfRetainedAncientFreezer(db ethdb.KeyValueReader) uint64 {
+	offset, _ := db.Get(offSetOfRetainedAncientFreezer)
+	if offset == nil {
+		return 0
+	}
+	return new(big.Int).SetBytes(offset).Uint64()
+}
+
+// WriteOffSetOfRetainedAncientFreezer write the start of the tables retained by the pruned freezer
+func WriteOffSetOfRetainedAncientFreezer(db ethdb.KeyValueWriter, offset uint64) {
+	if err := db.Put(offSetOfRetainedAncientFreezer, new(big.Int).SetUint64(offset).Bytes()); err != nil {
+		log.Crit("Failed to store the retained offset of ancient", "err", err)
+	}
+}
+
 // ReadFrozenOfAncientFreezer return freezer block number
 func ReadFrozenOfAncientFreezer(db ethdb.KeyValueReader) uint64 {
 	fozen, _ := db.Get(frozenOfAncientDBKey)
@@ -16,7 +16,11 @@
 
 package rawdb
 
-import "path/filepath"
+import (
+	"path/filepath"
+
+	"golang.org/x/exp/slices"
+)
 
 // The list of table names of chain freezer.
 const (
@@ -52,6 +56,17 @@ var chainFreezerNoSnappy = map[string]bool{
 
 var additionTables = []string{ChainFreezerBlobSidecarTable}
 
+// prunableTables are the chain freezer tables whose tail can be truncated on
+// its own, e.g. by a retention policy, so they may start later than the rest.
+// Headers, hashes and difficulties are always kept for the whole history.
+var prunableTables = []string{ChainFreezerBodiesTable, ChainFreezerReceiptTable}
+
+// independentTail reports whether the tail of the table doesn't have to be
+// aligned with the rest of the freezer.
+func independentTail(kind string) bool {
+	return slices.Contains(additionTables, kind) || slices.Contains(prunableTables, kind)
+}
+
 const (
 	// stateHistoryTableSize defines the maximum size of freezer data files.
 	stateHistoryTableSize = 2 * 1000 * 1000 * 1000
@@ -74,8 +89,9 @@ var stateFreezerNoSnappy = map[string]bool{
 
 // The list of identifiers of ancient stores.
 var (
-	ChainFreezerName = "chain" // the folder name of chain segment ancient store.
-	StateFreezerName = "state" // the folder name of reverse diff ancient store.
+	ChainFreezerName         = "chain"          // the folder name of chain segment ancient store.
+	StateFreezerName         = "state"          // the folder name of reverse diff ancient store.
+	ChainRetainedFreezerName = "chain_retained" // the folder name of chain segments retained by the pruned ancient store.
 )
 
 // freezers the collections of all builtin freezers.
@@ -29,6 +29,7 @@ import (
 type tableSize struct {
 	name string
 	size common.StorageSize
+	tail uint64 // The number of first stored item in the table
 }
 
 // freezerInfo contains the basic information of the freezer.
@@ -44,6 +45,15 @@ func (info *freezerInfo) count() uint64 {
 	return info.head - info.tail + 1
 }
 
+// tableCount returns the number of stored items in the given table, which is
+// less than the freezer count if the table was pruned on its own.
+func (info *freezerInfo) tableCount(table tableSize) uint64 {
+	if table.tail > info.head {
+		return 0
+	}
+	return info.head - table.tail + 1
+}
+
 // size returns the storage size of the entire freezer.
 func (info *freezerInfo) size() common.StorageSize {
 	var total common.StorageSize
@@ -60,7 +70,11 @@ func inspect(name string, order map[string]bool, reader ethdb.AncientReader) (fr
 		if err != nil {
 			return freezerInfo{}, err
 		}
-		info.sizes = append(info.sizes, tableSize{name: t, size: common.StorageSize(size)})
+		tail, err := reader.TableTail(t)
+		if err != nil {
+			return freezerInfo{}, err
+		}
+		info.sizes = append(info.sizes, tableSize{name: t, size: common.StorageSize(size), tail: tail})
 	}
 	// Retrieve the number of last stored item
 	ancients, err := reader.Ancients()
@@ -317,6 +317,7 @@ func (f *chainFreezer) freeze(db ethdb.KeyValueStore) {
 		if isCancun(env, head.Number, head.Time) {
 			f.tryPruneBlobAncientTable(env, *number)
 		}
+		f.tryPruneHistoryAncientTables(env, *number)
 
 		// Avoid database thrashing with tiny writes
 		if frozen-first < freezerBatchLimit {
@@ -344,6 +345,39 @@ func (f *chainFreezer) tryPruneBlobAncientTable(env *ethdb.FreezerEnv, num uint6
 	log.Debug("Chain freezer prune useless blobs, now ancient data is", "from", expectTail, "to", num, "cost", common.PrettyDuration(time.Since(start)))
 }
 
+// tryPruneHistoryAncientTables truncates the tail of the prunable tables, i.e.
+// bodies and receipts, according to the retention configured in the env. The
+// headers, hashes and difficulties are always kept for the whole history.
+func (f *chainFreezer) tryPruneHistoryAncientTables(env *ethdb.FreezerEnv, num uint64) {
+	if env == nil {
+		return
+	}
+	frozen := f.frozen.Load()
+	for kind, reserve := range map[string]uint64{
+		ChainFreezerBodiesTable:  env.BodyHistory,
+		ChainFreezerReceiptTable: env.ReceiptHistory,
+	} {
+		// It means that the whole history is kept
+		if reserve == 0 || num <= reserve {
+			continue
+		}
+		// Only the frozen items can be pruned, the rest are still in the kvstore
+		expectTail := num - reserve
+		if expectTail > frozen {
+			expectTail = frozen
+		}
+		if expectTail <= f.AncientOffSet() {
+			continue
+		}
+		start := time.Now()
+		if _, err := f.TruncateTableTail(kind, expectTail); err != nil {
+			log.Error("Cannot prune ancient table", "table", kind, "block", num, "expectTail", expectTail, "err", err)
+			continue
+		}
+		log.Debug("Chain freezer prune history, now ancient table is", "table", kind, "from", expectTail, "to", num, "cost", common.PrettyDuration(time.Since(start)))
+	}
+}
+
 func getBlobExtraReserveFromEnv(env *ethdb.FreezerEnv) uint64 {
 	if env == nil {
 		return params.DefaultExtraReserveForBlobRequests
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/leveldb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
@@ -194,6 +195,11 @@ func (db *nofreezedb) Tail() (uint64, error) {
 	return 0, errNotSupported
 }
 
+// TableTail returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) TableTail(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
 // AncientSize returns an error as we don't have a backing chain freezer.
 func (db *nofreezedb) AncientSize(kind string) (uint64, error) {
 	return 0, errNotSupported
@@ -347,6 +353,9 @@ func resolveChainFreezerDir(ancient string) string {
 	if common.FileExist(state) {
 		return chain
 	}
+	if common.FileExist(path.Join(ancient, ChainRetainedFreezerName)) {
+		return chain
+	}
 	if common.FileExist(ancient) {
 		log.Info("Found legacy ancient chain path", "location", ancient)
 		chain = ancient
@@ -368,12 +377,16 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 	}
 
 	if pruneAncientData && !disableFreeze && !readonly {
-		frdb, err := newPrunedFreezer(resolveChainFreezerDir(ancient), db, offset)
+		frdb, err := newPrunedFreezer(resolveChainFreezerDir(ancient), path.Join(ancient, ChainRetainedFreezerName), db, offset)
 		if err != nil {
 			return nil, err
 		}
 
-		go frdb.freeze()
+		frdb.wg.Add(1)
+		go func() {
+			frdb.freeze()
+			frdb.wg.Done()
+		}()
 		if !readonly {
 			WriteAncientType(db, PruneFreezerType)
 		}
@@ -393,8 +406,15 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 		offset = prunedFrozen
 	}
 
+	chain := resolveChainFreezerDir(ancient)
+	// The pruned freezer keeps the retained tables in a store of its own, which
+	// holds all the ancient data left to read.
+	if retained := path.Join(ancient, ChainRetainedFreezerName); readonly && ReadAncientType(db) == PruneFreezerType && common.FileExist(retained) {
+		chain, offset = retained, ReadOffSetOfRetainedAncientFreezer(db)
+	}
+
 	// Create the idle freezer instance
-	frdb, err := newChainFreezer(resolveChainFreezerDir(ancient), namespace, readonly, offset)
+	frdb, err := newChainFreezer(chain, namespace, readonly, offset)
 	if err != nil {
 		printChainMetadata(db)
 		return nil, err
@@ -797,6 +817,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		bloomBits       stat
 		cliqueSnaps     stat
 		parliaSnaps     stat
+		parliaValSets   stat
+		doubleSigns     stat
+		maliciousVotes  stat
 
 		// Les statistic
 		chtTrieNodes   stat
@@ -859,6 +882,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			cliqueSnaps.Add(size)
 		case bytes.HasPrefix(key, ParliaSnapshotPrefix) && len(key) == 7+common.HashLength:
 			parliaSnaps.Add(size)
+		case bytes.HasPrefix(key, ParliaValidatorSetPrefix) && len(key) == len(ParliaValidatorSetPrefix)+8+common.HashLength:
+			parliaValSets.Add(size)
+		case bytes.HasPrefix(key, DoubleSignEvidencePrefix) && len(key) == len(DoubleSignEvidencePrefix)+8+2*common.HashLength:
+			doubleSigns.Add(size)
+		case bytes.HasPrefix(key, MaliciousVoteEvidencePrefix) && len(key) == len(MaliciousVoteEvidencePrefix)+types.BLSPublicKeyLength+16:
+			maliciousVotes.Add(size)
 		case bytes.HasPrefix(key, ChtTablePrefix) ||
 			bytes.HasPrefix(key, ChtIndexTablePrefix) ||
 			bytes.HasPrefix(key, ChtPrefix): // Canonical hash trie
@@ -1002,6 +1031,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
 		{"Key-Value store", "Clique snapshots", cliqueSnaps.Size(), cliqueSnaps.Count()},
 		{"Key-Value store", "Parlia snapshots", parliaSnaps.Size(), parliaSnaps.Count()},
+		{"Key-Value store", "Parlia validator sets", parliaValSets.Size(), parliaValSets.Count()},
+		{"Key-Value store", "Double sign evidence", doubleSigns.Size(), doubleSigns.Count()},
+		{"Key-Value store", "Malicious vote evidence", maliciousVotes.Size(), maliciousVotes.Count()},
 		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
@@ -1011,14 +1043,16 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	if err != nil {
 		return err
 	}
+	var ranges [][]string
 	for _, ancient := range ancients {
 		for _, table := range ancient.sizes {
 			stats = append(stats, []string{
 				fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
 				strings.Title(table.name),
 				table.size.String(),
-				fmt.Sprintf("%d", ancient.count()),
+				fmt.Sprintf("%d", ancient.tableCount(table)),
 			})
+			ranges = append(ranges, ancientRange(ancient, table))
 		}
 		total += ancient.size()
 	}
@@ -1038,8 +1072,9 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 					fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)),
 					strings.Title(table.name),
 					table.size.String(),
-					fmt.Sprintf("%d", ancient.count()),
+					fmt.Sprintf("%d", ancient.tableCount(table)),
 				})
+				ranges = append(ranges, ancientRange(ancient, table))
 			}
 			total += ancient.size()
 		}
@@ -1050,12 +1085,30 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 	table.AppendBulk(stats)
 	table.Render()
 
+	// Tables with a retention policy may start later than the rest of the freezer,
+	// so list the stored range of every table as well.
+	if len(ranges) > 0 {
+		table = tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Database", "Category", "First", "Last"})
+		table.AppendBulk(ranges)
+		table.Render()
+	}
+
 	if unaccounted.size > 0 {
 		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
 	}
 	return nil
 }
 
+// ancientRange returns the first and last stored items of the given table.
+func ancientRange(ancient freezerInfo, table tableSize) []string {
+	first, last := "-", "-"
+	if ancient.tableCount(table) > 0 {
+		first, last = fmt.Sprintf("%d", table.tail), fmt.Sprintf("%d", ancient.head)
+	}
+	return []string{fmt.Sprintf("Ancient store (%s)", strings.Title(ancient.name)), strings.Title(table.name), first, last}
+}
+
 func DeleteTrieState(db ethdb.Database) error {
 	var (
 		it     ethdb.Iterator
@@ -255,6 +255,18 @@ func (f *Freezer) TableAncients(kind string) (uint64, error) {
 	return f.tables[kind].items.Load(), nil
 }
 
+// TableTail returns the number of first stored item in the specified table,
+// which is ahead of the freezer tail if the table was pruned on its own.
+func (f *Freezer) TableTail(kind string) (uint64, error) {
+	f.writeLock.RLock()
+	defer f.writeLock.RUnlock()
+
+	if table := f.tables[kind]; table != nil {
+		return table.itemHidden.Load() + atomic.LoadUint64(&f.offset), nil
+	}
+	return 0, errUnknownTable
+}
+
 // ItemAmountInAncient returns the actual length of current ancientDB.
 func (f *Freezer) ItemAmountInAncient() (uint64, error) {
 	return f.frozen.Load() - atomic.LoadUint64(&f.offset), nil
@@ -339,13 +351,15 @@ func (f *Freezer) TruncateHead(items uint64) (uint64, error) {
 	if oitems <= items {
 		return oitems, nil
 	}
+	var reset bool
 	for kind, table := range f.tables {
 		err := table.truncateHead(items - f.offset)
 		if err == errTruncationBelowTail {
-			// This often happens in chain rewinds, but the blob table is special.
-			// It has the same head, but a different tail from other tables (like bodies, receipts).
-			// So if the chain is rewound to head below the blob's tail, it needs to reset again.
-			if kind != ChainFreezerBlobSidecarTable {
+			// This often happens in chain rewinds, but the blob table and the pruned
+			// tables are special. They have the same head, but a different tail from
+			// other tables (like headers, hashes). So if the chain is rewound to head
+			// below their tail, they need to reset again.
+			if !independentTail(kind) {
 				return 0, err
 			}
 			nt, err := table.resetItems(items - f.offset)
@@ -353,12 +367,17 @@ func (f *Freezer) TruncateHead(items uint64) (uint64, error) {
 				return 0, err
 			}
 			f.tables[kind] = nt
+			reset = true
 			continue
 		}
 		if err != nil {
 			return 0, err
 		}
 	}
+	// The write batch must point to the reset tables
+	if reset {
+		f.writeBatch = newFreezerBatch(f)
+	}
 	f.frozen.Store(items)
 	return oitems, nil
 }
@@ -411,8 +430,8 @@ func (f *Freezer) validate() error {
 	)
 	// Hack to get boundary of any table
 	for kind, table := range f.tables {
-		// addition tables is special cases
-		if slices.Contains(additionTables, kind) {
+		// addition and pruned tables are special cases
+		if independentTail(kind) {
 			continue
 		}
 		head = table.items.Load()
@@ -422,10 +441,10 @@ func (f *Freezer) validate() error {
 	}
 	// Now check every table against those boundaries.
 	for kind, table := range f.tables {
-		// check addition tables, try to align with exist tables
-		if slices.Contains(additionTables, kind) {
-			// if the table is empty, just skip
-			if EmptyTable(table) {
+		// check addition and pruned tables, try to align with exist tables
+		if independentTail(kind) {
+			// if the addition table is empty, just skip
+			if slices.Contains(additionTables, kind) && EmptyTable(table) {
 				continue
 			}
 			// otherwise, just align head
@@ -471,6 +490,10 @@ func (f *Freezer) repair() error {
 		if head > items {
 			head = items
 		}
+		// pruned tables only align head, their tail may be ahead
+		if slices.Contains(prunableTables, kind) {
+			continue
+		}
 		hidden := table.itemHidden.Load()
 		if hidden > tail {
 			tail = hidden
@@ -483,10 +506,11 @@ func (f *Freezer) repair() error {
 		}
 		err := table.truncateHead(head)
 		if err == errTruncationBelowTail {
-			// This often happens in chain rewinds, but the blob table is special.
-			// It has the same head, but a different tail from other tables (like bodies, receipts).
-			// So if the chain is rewound to head below the blob's tail, it needs to reset again.
-			if kind != ChainFreezerBlobSidecarTable {
+			// This often happens in chain rewinds, but the blob table and the pruned
+			// tables are special. They have the same head, but a different tail from
+			// other tables (like headers, hashes). So if the chain is rewound to head
+			// below their tail, they need to reset again.
+			if !independentTail(kind) {
 				return err
 			}
 			nt, err := table.resetItems(head)
@@ -707,8 +731,8 @@ func (f *Freezer) TruncateTableTail(kind string, tail uint64) (uint64, error) {
 	f.writeLock.Lock()
 	defer f.writeLock.Unlock()
 
-	if !slices.Contains(additionTables, kind) {
-		return 0, errors.New("only new added table could be truncated independently")
+	if !independentTail(kind) {
+		return 0, errors.New("only new added or prunable table could be truncated independently")
 	}
 	if tail < f.offset {
 		return 0, errors.New("the input tail&head is less than offset")
@@ -146,6 +146,14 @@ func (f *ResettableFreezer) Tail() (uint64, error) {
 	return f.freezer.Tail()
 }
 
+// TableTail returns the number of first stored item in the specified table.
+func (f *ResettableFreezer) TableTail(kind string) (uint64, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.freezer.TableTail(kind)
+}
+
 // AncientSize returns the ancient size of the specified category.
 func (f *ResettableFreezer) AncientSize(kind string) (uint64, error) {
 	f.lock.RLock()
@@ -24,9 +24,14 @@ import (
 	"math/rand"
 	"os"
 	"path"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/require"
@@ -426,6 +431,113 @@ func TestFreezer_AdditionTables(t *testing.T) {
 	require.NoError(t, f.Close())
 }
 
+func TestFreezer_PrunableTables(t *testing.T) {
+	dir := t.TempDir()
+	tables := map[string]bool{ChainFreezerHeaderTable: true, ChainFreezerBodiesTable: true}
+	f, err := NewFreezer(dir, "", false, 0, 2049, tables)
+	require.NoError(t, err)
+
+	var item = make([]byte, 1024)
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := appendSameItem(op, []string{ChainFreezerHeaderTable, ChainFreezerBodiesTable}, i, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Only the prunable tables can be truncated on their own
+	_, err = f.TruncateTableTail(ChainFreezerHeaderTable, 5)
+	require.Error(t, err)
+	_, err = f.TruncateTableTail(ChainFreezerBodiesTable, 5)
+	require.NoError(t, err)
+
+	checkTails := func(f *Freezer, headers, bodies uint64) {
+		t.Helper()
+		tail, err := f.TableTail(ChainFreezerHeaderTable)
+		require.NoError(t, err)
+		require.Equal(t, headers, tail)
+		tail, err = f.TableTail(ChainFreezerBodiesTable)
+		require.NoError(t, err)
+		require.Equal(t, bodies, tail)
+	}
+	checkTails(f, 0, 5)
+	_, err = f.Ancient(ChainFreezerBodiesTable, 4)
+	require.Error(t, err)
+	_, err = f.Ancient(ChainFreezerHeaderTable, 4)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The pruned tail must survive the repair and validation on reopen
+	f, err = NewFreezer(dir, "", true, 0, 2049, tables)
+	require.NoError(t, err)
+	checkTails(f, 0, 5)
+	require.NoError(t, f.Close())
+
+	f, err = NewFreezer(dir, "", false, 0, 2049, tables)
+	require.NoError(t, err)
+	checkTails(f, 0, 5)
+	tail, _ := f.Tail()
+	require.Equal(t, uint64(0), tail)
+
+	// Rewinding below the pruned tail resets the table
+	_, err = f.TruncateHead(3)
+	require.NoError(t, err)
+	checkTails(f, 0, 3)
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		return appendSameItem(op, []string{ChainFreezerHeaderTable, ChainFreezerBodiesTable}, 3, item)
+	})
+	require.NoError(t, err)
+	actual, err := f.Ancient(ChainFreezerBodiesTable, 3)
+	require.NoError(t, err)
+	require.Equal(t, item, actual)
+	require.NoError(t, f.Close())
+}
+
+func TestChainFreezerHistoryRetention(t *testing.T) {
+	// Other tests may have replaced the addition tables, restore the chain ones
+	defer func(tables []string) { additionTables = tables }(additionTables)
+	additionTables = []string{ChainFreezerBlobSidecarTable}
+
+	f, err := newChainFreezer(t.TempDir(), "", false, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tables := []string{ChainFreezerHashTable, ChainFreezerHeaderTable, ChainFreezerBodiesTable, ChainFreezerReceiptTable, ChainFreezerDifficultyTable}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := appendSameItem(op, tables, i, []byte{byte(i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Bodies are kept for the last 4 blocks, but only frozen items can be pruned.
+	f.tryPruneHistoryAncientTables(&ethdb.FreezerEnv{BodyHistory: 4, ReceiptHistory: 8}, 12)
+
+	info, err := inspect(ChainFreezerName, chainFreezerNoSnappy, f)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), info.head)
+	for _, table := range info.sizes {
+		var want uint64
+		switch table.name {
+		case ChainFreezerBodiesTable:
+			want = 2
+		case ChainFreezerReceiptTable:
+			want = 6
+		case ChainFreezerBlobSidecarTable:
+			continue
+		default:
+			want = 10
+		}
+		require.Equal(t, want, info.tableCount(table), table.name)
+	}
+}
+
 func appendSameItem(op ethdb.AncientWriteOp, tables []string, i uint64, item []byte) error {
 	for _, t := range tables {
 		if err := op.AppendRaw(t, i, item); err != nil {
@@ -479,6 +591,112 @@ func checkAncientCount(t *testing.T, f *Freezer, kind string, n uint64) {
 	}
 }
 
+func TestPrunedFreezerInspect(t *testing.T) {
+	ancient := t.TempDir()
+	f, err := newPrunedFreezer(filepath.Join(ancient, ChainFreezerName), filepath.Join(ancient, ChainRetainedFreezerName), NewMemoryDatabase(), 10)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// The pruned freezer keeps no items, but must still be inspectable
+	info, err := inspect(ChainFreezerName, chainFreezerNoSnappy, f)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), info.head)
+	require.Equal(t, uint64(10), info.tail)
+	require.Equal(t, uint64(0), info.count())
+	for _, table := range info.sizes {
+		require.Equal(t, uint64(0), info.tableCount(table), table.name)
+	}
+}
+
+func TestPrunedFreezerRetention(t *testing.T) {
+	// Other tests may have replaced the addition tables, restore the chain ones
+	defer func(tables []string) { additionTables = tables }(additionTables)
+	additionTables = []string{ChainFreezerBlobSidecarTable}
+
+	var (
+		db      = NewMemoryDatabase()
+		ancient = t.TempDir()
+		blocks  []*types.Block
+		parent  common.Hash
+	)
+	for i := 0; i <= 300; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), ParentHash: parent})
+		WriteBlock(db, block)
+		WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+		WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(int64(i)))
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		blocks, parent = append(blocks, block), block.Hash()
+	}
+	WriteHeadBlockHash(db, parent)
+	WriteSafePointBlockNumber(db, 300)
+
+	open := func() *prunedfreezer {
+		f, err := newPrunedFreezer(filepath.Join(ancient, ChainFreezerName), filepath.Join(ancient, ChainRetainedFreezerName), db, 0)
+		require.NoError(t, err)
+		return f
+	}
+	f := open()
+	f.threshold = 10
+	f.SetupFreezerEnv(&ethdb.FreezerEnv{BodyHistory: 200, ReceiptHistory: 150})
+	f.wg.Add(1)
+	go func() {
+		f.freeze()
+		f.wg.Done()
+	}()
+
+	// Blocks up to the stable state are frozen, keeping the bodies of the last
+	// 200 blocks and the receipts of the last 150 ones
+	require.Eventually(t, func() bool {
+		bodies, _ := f.TableTail(ChainFreezerBodiesTable)
+		receipts, _ := f.TableTail(ChainFreezerReceiptTable)
+		return bodies == 100 && receipts == 150
+	}, 10*time.Second, 10*time.Millisecond)
+	require.NoError(t, f.Close())
+
+	check := func(reader ethdb.AncientReader) {
+		frozen, err := reader.Ancients()
+		require.NoError(t, err)
+		require.Equal(t, uint64(173), frozen)
+
+		for _, number := range []uint64{0, 50, 120, 172} {
+			header, _ := reader.Ancient(ChainFreezerHeaderTable, number)
+			require.Equal(t, blocks[number].Hash(), crypto.Keccak256Hash(header), number)
+			body, _ := reader.Ancient(ChainFreezerBodiesTable, number)
+			require.Equal(t, number >= 100, len(body) > 0, number)
+			receipts, _ := reader.Ancient(ChainFreezerReceiptTable, number)
+			require.Equal(t, number >= 150, len(receipts) > 0, number)
+		}
+		headers, err := reader.AncientRange(ChainFreezerHeaderTable, 10, 3, 0)
+		require.NoError(t, err)
+		require.Len(t, headers, 3)
+		require.Equal(t, blocks[12].Hash(), crypto.Keccak256Hash(headers[2]))
+
+		info, err := inspect(ChainFreezerName, chainFreezerNoSnappy, reader)
+		require.NoError(t, err)
+		require.Equal(t, uint64(172), info.head)
+		require.Equal(t, uint64(0), info.tail)
+		for _, table := range info.sizes {
+			want := map[string]uint64{ChainFreezerBodiesTable: 73, ChainFreezerReceiptTable: 23}[table.name]
+			if want == 0 {
+				want = 173
+			}
+			require.Equal(t, want, info.tableCount(table), table.name)
+		}
+	}
+	// The retained blocks are dropped from the kvstore but served by the freezer
+	require.Empty(t, ReadHeaderRLP(db, blocks[50].Hash(), 50))
+	f = open()
+	check(f)
+	require.NoError(t, f.Close())
+
+	// The retained tables are also read back from a readonly database
+	WriteAncientType(db, PruneFreezerType)
+	rdb, err := NewDatabaseWithFreezer(db, ancient, "", true, false, false, false)
+	require.NoError(t, err)
+	check(rdb)
+	require.NoError(t, rdb.Close())
+}
+
 func TestRenameWindows(t *testing.T) {
 	var (
 		fname   = "file.bin"
@@ -16,6 +16,9 @@ import (
 )
 
 // prunedfreezer not contain ancient data, only record 'frozen' , the next recycle block number form kvstore.
+// If a body or receipt history is configured, the headers, hashes and difficulties
+// are retained for the whole history from then on, along with the recent bodies,
+// receipts and blob sidecars.
 type prunedfreezer struct {
 	db ethdb.KeyValueStore // Meta database
 	// WARNING: The `frozen` field is accessed atomically. On 32 bit platforms, only
@@ -24,13 +27,18 @@ type prunedfreezer struct {
 	frozen    uint64 // BlockNumber of next frozen block
 	threshold uint64 // Number of recent blocks not to freeze (params.FullImmutabilityThreshold apart from tests)
 
+	retainDir string                       // Directory of the store keeping the retained tables
+	retained  atomic.Pointer[chainFreezer] // Store keeping the retained tables, nil if nothing is retained
+	freezeEnv atomic.Value
+
 	instanceLock fileutil.Releaser // File-system lock to prevent double opens
 	quit         chan struct{}
+	wg           sync.WaitGroup
 	closeOnce    sync.Once
 }
 
 // newNoDataFreezer creates a chain freezer that deletes data enough ‘old’.
-func newPrunedFreezer(datadir string, db ethdb.KeyValueStore, offset uint64) (*prunedfreezer, error) {
+func newPrunedFreezer(datadir string, retainDir string, db ethdb.KeyValueStore, offset uint64) (*prunedfreezer, error) {
 	if info, err := os.Lstat(datadir); !os.IsNotExist(err) {
 		if info.Mode()&os.ModeSymlink != 0 {
 			log.Warn("Symbolic link ancient database is not supported", "path", datadir)
@@ -47,6 +55,7 @@ func newPrunedFreezer(datadir string, db ethdb.KeyValueStore, offset uint64) (*p
 		db:           db,
 		frozen:       offset,
 		threshold:    params.FullImmutabilityThreshold,
+		retainDir:    retainDir,
 		instanceLock: lock,
 		quit:         make(chan struct{}),
 	}
@@ -60,10 +69,78 @@ func newPrunedFreezer(datadir string, db ethdb.KeyValueStore, offset uint64) (*p
 		log.Warn("Failed to remove the ancient dir", "path", datadir, "error", err)
 		return nil, err
 	}
+	if common.FileExist(retainDir) {
+		if err := freezer.openRetained(); err != nil {
+			return nil, err
+		}
+	}
 	log.Info("Opened ancientdb with nodata mode", "database", datadir, "frozen", freezer.frozen)
 	return freezer, nil
 }
 
+// openRetained opens the store of the retained tables and lines it up with the
+// frozen blocks. The store is dropped if it falls behind, as the blocks to fill
+// the gap are gone from the kvstore already.
+func (f *prunedfreezer) openRetained() error {
+	offset := ReadOffSetOfRetainedAncientFreezer(f.db)
+	retained, err := newChainFreezer(f.retainDir, "", false, offset)
+	if err != nil {
+		return err
+	}
+	frozen := atomic.LoadUint64(&f.frozen)
+	ancients, _ := retained.Ancients()
+	switch {
+	case ancients == frozen:
+	case ancients > frozen && frozen >= offset:
+		if _, err := retained.TruncateHead(frozen); err != nil {
+			retained.Close()
+			return err
+		}
+	default:
+		log.Warn("Dropping the retained ancient store", "path", f.retainDir, "offset", offset, "ancients", ancients, "frozen", frozen)
+		retained.Close()
+		return os.RemoveAll(f.retainDir)
+	}
+	f.retained.Store(retained)
+	log.Info("Opened the retained ancient store", "path", f.retainDir, "offset", offset, "frozen", frozen)
+	return nil
+}
+
+// retainedStore returns the store of the retained tables, creating or dropping
+// it according to the history configured in the env.
+func (f *prunedfreezer) retainedStore(env *ethdb.FreezerEnv) (*chainFreezer, error) {
+	retain := env.BodyHistory != 0 || env.ReceiptHistory != 0
+	retained := f.retained.Load()
+	switch {
+	case retained == nil && retain:
+		frozen := atomic.LoadUint64(&f.frozen)
+		WriteOffSetOfRetainedAncientFreezer(f.db, frozen)
+		created, err := newChainFreezer(f.retainDir, "", false, frozen)
+		if err != nil {
+			return nil, err
+		}
+		created.SetupFreezerEnv(env)
+		f.retained.Store(created)
+		log.Info("Created the retained ancient store", "path", f.retainDir, "offset", frozen)
+		return created, nil
+
+	case retained != nil && !retain:
+		log.Warn("Dropping the retained ancient store, no history is configured", "path", f.retainDir)
+		return nil, f.dropRetained()
+	}
+	return retained, nil
+}
+
+// dropRetained closes the store of the retained tables and deletes it.
+func (f *prunedfreezer) dropRetained() error {
+	retained := f.retained.Swap(nil)
+	if retained == nil {
+		return nil
+	}
+	retained.Close()
+	return os.RemoveAll(f.retainDir)
+}
+
 // repair init frozen , compatible disk-ancientdb and pruner-block-tool.
 func (f *prunedfreezer) repair(datadir string) error {
 	offset := atomic.LoadUint64(&f.frozen)
@@ -99,23 +176,37 @@ func (f *prunedfreezer) Close() error {
 	var err error
 	f.closeOnce.Do(func() {
 		close(f.quit)
+		f.wg.Wait()
 		f.Sync()
+		if retained := f.retained.Load(); retained != nil {
+			retained.Close()
+		}
 		err = f.instanceLock.Release()
 	})
 	return err
 }
 
-// HasAncient returns an indicator whether the specified ancient data exists, return nil.
+// HasAncient returns an indicator whether the specified ancient data exists,
+// which is only the case for the retained items.
 func (f *prunedfreezer) HasAncient(kind string, number uint64) (bool, error) {
+	if retained := f.retained.Load(); retained != nil && number < atomic.LoadUint64(&f.frozen) {
+		return retained.HasAncient(kind, number)
+	}
 	return false, nil
 }
 
-// Ancient retrieves an ancient binary blob from prunedfreezer, return nil.
+// Ancient retrieves an ancient binary blob from prunedfreezer, return nil if the
+// item is not retained.
 func (f *prunedfreezer) Ancient(kind string, number uint64) ([]byte, error) {
 	if _, ok := chainFreezerNoSnappy[kind]; ok {
 		if number >= atomic.LoadUint64(&f.frozen) {
 			return nil, errOutOfBounds
 		}
+		if retained := f.retained.Load(); retained != nil {
+			if has, _ := retained.HasAncient(kind, number); has {
+				return retained.Ancient(kind, number)
+			}
+		}
 		return nil, nil
 	}
 	return nil, errUnknownTable
@@ -127,6 +218,7 @@ func (f *prunedfreezer) Ancients() (uint64, error) {
 }
 
 // ItemAmountInAncient returns the actual length of current ancientDB, return 0.
+// The retained tables are left out, as the blocks can't be rebuilt from them.
 func (f *prunedfreezer) ItemAmountInAncient() (uint64, error) {
 	return 0, nil
 }
@@ -147,14 +239,35 @@ func (db *prunedfreezer) AncientDatadir() (string, error) {
 	return "", errNotSupported
 }
 
-// Tail returns the number of first stored item in the freezer.
+// Tail returns the number of first stored item in the freezer. Unless tables
+// are retained, all the frozen items are dropped, so it's the same as the number
+// of frozen items.
 func (f *prunedfreezer) Tail() (uint64, error) {
-	return 0, errNotSupported
+	if retained := f.retained.Load(); retained != nil {
+		return retained.Tail()
+	}
+	return atomic.LoadUint64(&f.frozen), nil
 }
 
-// AncientSize returns the ancient size of the specified category, return 0.
+// TableTail returns the number of first stored item in the specified table,
+// which is the number of frozen items if the table keeps no data.
+func (f *prunedfreezer) TableTail(kind string) (uint64, error) {
+	if _, ok := chainFreezerNoSnappy[kind]; !ok {
+		return 0, errUnknownTable
+	}
+	if retained := f.retained.Load(); retained != nil {
+		return retained.TableTail(kind)
+	}
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category, return 0 if
+// nothing is retained.
 func (f *prunedfreezer) AncientSize(kind string) (uint64, error) {
 	if _, ok := chainFreezerNoSnappy[kind]; ok {
+		if retained := f.retained.Load(); retained != nil {
+			return retained.AncientSize(kind)
+		}
 		return 0, nil
 	}
 	return 0, errUnknownTable
@@ -177,6 +290,15 @@ func (f *prunedfreezer) AppendAncient(number uint64, hash, header, body, receipt
 func (f *prunedfreezer) TruncateHead(items uint64) (uint64, error) {
 	preHead := atomic.LoadUint64(&f.frozen)
 	if preHead > items {
+		if retained := f.retained.Load(); retained != nil {
+			if items < retained.AncientOffSet() {
+				if err := f.dropRetained(); err != nil {
+					return preHead, err
+				}
+			} else if _, err := retained.TruncateHead(items); err != nil {
+				return preHead, err
+			}
+		}
 		atomic.StoreUint64(&f.frozen, items)
 		WriteFrozenOfAncientFreezer(f.db, atomic.LoadUint64(&f.frozen))
 	}
@@ -190,6 +312,11 @@ func (f *prunedfreezer) TruncateTail(tail uint64) (uint64, error) {
 
 // Sync flushes meta data tables to disk.
 func (f *prunedfreezer) Sync() error {
+	if retained := f.retained.Load(); retained != nil {
+		if err := retained.Sync(); err != nil {
+			return err
+		}
+	}
 	WriteFrozenOfAncientFreezer(f.db, atomic.LoadUint64(&f.frozen))
 	// compatible offline prune blocks tool
 	WriteOffSetOfCurrentAncientFreezer(f.db, atomic.LoadUint64(&f.frozen))
@@ -220,6 +347,14 @@ func (f *prunedfreezer) freeze() {
 			}
 		}
 
+		// The env tells which tables to retain, it must wait a while for it
+		env, _ := f.freezeEnv.Load().(*ethdb.FreezerEnv)
+		if env == nil {
+			log.Warn("Freezer need related env, may wait for a while", "err", missFreezerEnvErr)
+			backoff = true
+			continue
+		}
+
 		// Retrieve the freezing threshold.
 		hash := ReadHeadBlockHash(nfdb)
 		if hash == (common.Hash{}) {
@@ -282,6 +417,24 @@ func (f *prunedfreezer) freeze() {
 		if limit-f.frozen > freezerBatchLimit {
 			limit = f.frozen + freezerBatchLimit
 		}
+		// Move the retained tables of the batch into their store before dropping
+		// the blocks from the kvstore
+		retained, err := f.retainedStore(env)
+		if err != nil {
+			log.Error("Failed to open the retained ancient store", "err", err)
+			backoff = true
+			continue
+		}
+		if retained != nil {
+			if _, err := retained.freezeRangeWithBlobs(nfdb, f.frozen, limit); err != nil {
+				log.Error("Failed to retain ancient blocks", "from", f.frozen, "to", limit, "err", err)
+				if _, err := retained.TruncateHead(f.frozen); err != nil {
+					log.Crit("Failed to roll back retained tables", "err", err)
+				}
+				backoff = true
+				continue
+			}
+		}
 		var (
 			start    = time.Now()
 			first    = f.frozen
@@ -309,10 +462,22 @@ func (f *prunedfreezer) freeze() {
 		}
 		backoff = f.frozen-first >= freezerBatchLimit
 		gcKvStore(f.db, ancients, first, f.frozen, start)
+
+		// Only keep the configured history of the retained tables
+		if retained != nil {
+			if isCancun(env, head.Number, head.Time) {
+				retained.tryPruneBlobAncientTable(env, *number)
+			}
+			retained.tryPruneHistoryAncientTables(env, *number)
+		}
 	}
 }
 
 func (f *prunedfreezer) SetupFreezerEnv(env *ethdb.FreezerEnv) error {
+	f.freezeEnv.Store(env)
+	if retained := f.retained.Load(); retained != nil {
+		return retained.SetupFreezerEnv(env)
+	}
 	return nil
 }
 
@@ -321,7 +486,16 @@ func (f *prunedfreezer) ReadAncients(fn func(ethdb.AncientReaderOp) error) (err
 }
 
 func (f *prunedfreezer) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
-	return nil, errNotSupported
+	retained := f.retained.Load()
+	if retained == nil {
+		return nil, errNotSupported
+	}
+	// The items of the retained tables are numbered from the offset of the store
+	offset := retained.AncientOffSet()
+	if start < offset {
+		return nil, errOutOfBounds
+	}
+	return retained.AncientRange(kind, start-offset, count, maxBytes)
 }
 
 func (f *prunedfreezer) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
@@ -90,6 +90,9 @@ var (
 	//offSet of the ancientDB before updated version.
 	offSetOfLastAncientFreezer = []byte("offSetOfLastAncientFreezer")
 
+	//offSet of the tables retained by the pruned ancientDB.
+	offSetOfRetainedAncientFreezer = []byte("offSetOfRetainedAncientFreezer")
+
 	//frozenOfAncientDBKey tracks the block number for ancientDB to save.
 	frozenOfAncientDBKey = []byte("FrozenOfAncientDB")
 
@@ -152,6 +155,11 @@ var (
 	CliqueSnapshotPrefix = []byte("clique-")
 	ParliaSnapshotPrefix = []byte("parlia-")
 
+	ParliaValidatorSetPrefix = []byte("parliavalset-") // ParliaValidatorSetPrefix + num (uint64 big endian) + hash -> validator set transition
+
+	DoubleSignEvidencePrefix    = []byte("doublesign-")    // DoubleSignEvidencePrefix + num (uint64 big endian) + hash + hash -> double sign evidence
+	MaliciousVoteEvidencePrefix = []byte("maliciousvote-") // MaliciousVoteEvidencePrefix + vote address + target num + target num (uint64 big endian) -> finality evidence
+
 	BlockBlobSidecarsPrefix = []byte("blobs")
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
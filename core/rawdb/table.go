@@ -106,6 +106,12 @@ func (t *table) Tail() (uint64, error) {
 	return t.db.Tail()
 }
 
+// TableTail is a noop passthrough that just forwards the request to the underlying
+// database.
+func (t *table) TableTail(kind string) (uint64, error) {
+	return t.db.TableTail(kind)
+}
+
 // AncientSize is a noop passthrough that just forwards the request to the underlying
 // database.
 func (t *table) AncientSize(kind string) (uint64, error) {
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+const (
+	// defaultProofAccounts is the default number of accounts of a block's diff
+	// layer spot-checked against the state root.
+	defaultProofAccounts = 4
+	// proofSlotsPerAccount is the max number of storage slots spot-checked for
+	// each account.
+	proofSlotsPerAccount = 4
+)
+
+var (
+	// errInvalidStateProof is returned if the proofs returned by the peer can't
+	// be verified against the state root, which is the fault of the peer.
+	errInvalidStateProof = errors.New("invalid state proof")
+	// errStateProofMismatch is returned if the proven state differs from the diff
+	// layer of the block, which means the local state is not the one committed
+	// by the state root.
+	errStateProofMismatch = errors.New("state mismatches the proof")
+)
+
+// AccountProofQuery is the account and the storage slots to prove, both keyed by
+// their hashes as in the diff layers.
+type AccountProofQuery struct {
+	Account common.Hash
+	Slots   []common.Hash
+}
+
+// AccountProof is the Merkle proof of an account against the state root, along
+// with the proofs of the storage slots against the storage root of the account.
+type AccountProof struct {
+	Account common.Hash
+	Proof   [][]byte
+	Storage []StorageProof
+}
+
+// StorageProof is the Merkle proof of a storage slot against the storage root.
+type StorageProof struct {
+	Key   common.Hash
+	Proof [][]byte
+}
+
+// ProofPeer is a verify peer able to serve the state proofs, besides the state
+// roots.
+type ProofPeer interface {
+	VerifyPeer
+	RequestProof(blockNumber uint64, blockHash common.Hash, queries []AccountProofQuery) error
+}
+
+// GetStateProofs proves the accounts and storage slots against the state root of
+// the block, the accounts missing in the state are proven absent.
+func (bc *BlockChain) GetStateProofs(blockHash common.Hash, queries []AccountProofQuery) ([]*AccountProof, error) {
+	header := bc.GetHeaderByHash(blockHash)
+	if header == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	accTrie, err := trie.NewStateTrie(trie.StateTrieID(header.Root), bc.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	proofs := make([]*AccountProof, 0, len(queries))
+	for _, query := range queries {
+		proof := trienode.NewProofSet()
+		if err := accTrie.Prove(query.Account[:], proof); err != nil {
+			return nil, err
+		}
+		accountProof := &AccountProof{Account: query.Account, Proof: proofNodes(proof)}
+		proofs = append(proofs, accountProof)
+
+		account, err := accTrie.GetAccountByHash(query.Account)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil || len(query.Slots) == 0 {
+			continue
+		}
+		stTrie, err := trie.NewStateTrie(trie.StorageTrieID(header.Root, query.Account, account.Root), bc.TrieDB())
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range query.Slots {
+			proof := trienode.NewProofSet()
+			if err := stTrie.Prove(slot[:], proof); err != nil {
+				return nil, err
+			}
+			accountProof.Storage = append(accountProof.Storage, StorageProof{Key: slot, Proof: proofNodes(proof)})
+		}
+	}
+	return proofs, nil
+}
+
+// sampleProofQueries picks n random accounts of the diff layer, along with some
+// random storage slots of each, to spot-check against the state root.
+func sampleProofQueries(diff *types.DiffLayer, n int, r *rand.Rand) []AccountProofQuery {
+	storages := make(map[common.Hash]*types.DiffStorage, len(diff.Storages))
+	for i := range diff.Storages {
+		storages[diff.Storages[i].Account] = &diff.Storages[i]
+	}
+	var queries []AccountProofQuery
+	for _, i := range r.Perm(len(diff.Accounts)) {
+		if len(queries) >= n {
+			break
+		}
+		query := AccountProofQuery{Account: diff.Accounts[i].Account}
+		if storage, ok := storages[query.Account]; ok {
+			for _, j := range r.Perm(len(storage.Keys)) {
+				if len(query.Slots) >= proofSlotsPerAccount {
+					break
+				}
+				query.Slots = append(query.Slots, storage.Keys[j])
+			}
+		}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+// verifyStateProofs verifies the proofs of the queried accounts and storage slots
+// against the state root, and checks the proven state is the one of the diff layer.
+func verifyStateProofs(root common.Hash, diff *types.DiffLayer, queries []AccountProofQuery, proofs []*AccountProof) error {
+	accounts := make(map[common.Hash][]byte, len(diff.Accounts))
+	for _, account := range diff.Accounts {
+		accounts[account.Account] = account.Blob
+	}
+	storages := make(map[common.Hash]map[common.Hash][]byte, len(diff.Storages))
+	for _, storage := range diff.Storages {
+		slots := make(map[common.Hash][]byte, len(storage.Keys))
+		for i, key := range storage.Keys {
+			slots[key] = storage.Vals[i]
+		}
+		storages[storage.Account] = slots
+	}
+	if len(proofs) != len(queries) {
+		return fmt.Errorf("%w: have %d account proofs, want %d", errInvalidStateProof, len(proofs), len(queries))
+	}
+	for i, query := range queries {
+		proof := proofs[i]
+		if proof.Account != query.Account || len(proof.Storage) > len(query.Slots) {
+			return fmt.Errorf("%w: unexpected proof of account %#x", errInvalidStateProof, proof.Account)
+		}
+		value, err := trie.VerifyProof(root, query.Account[:], proofSet(proof.Proof))
+		if err != nil {
+			return fmt.Errorf("%w: account %#x: %v", errInvalidStateProof, query.Account, err)
+		}
+		blob, ok := accounts[query.Account]
+		if !ok {
+			continue
+		}
+		if len(blob) == 0 {
+			if len(value) != 0 {
+				return fmt.Errorf("%w: account %#x deleted", errStateProofMismatch, query.Account)
+			}
+			continue
+		}
+		if len(value) == 0 {
+			return fmt.Errorf("%w: account %#x missing", errStateProofMismatch, query.Account)
+		}
+		// The storage root is not compared, as the nodes without tries don't
+		// compute it. The storage is checked against the proven root instead.
+		local, err := types.FullAccount(blob)
+		if err != nil {
+			return err
+		}
+		account := new(types.StateAccount)
+		if err := rlp.DecodeBytes(value, account); err != nil {
+			return fmt.Errorf("%w: account %#x: %v", errInvalidStateProof, query.Account, err)
+		}
+		if account.Nonce != local.Nonce || account.Balance.Cmp(local.Balance) != 0 || !bytes.Equal(account.CodeHash, local.CodeHash) {
+			return fmt.Errorf("%w: account %#x", errStateProofMismatch, query.Account)
+		}
+		if len(query.Slots) == 0 {
+			continue
+		}
+		if len(proof.Storage) != len(query.Slots) {
+			return fmt.Errorf("%w: have %d storage proofs of account %#x, want %d", errInvalidStateProof, len(proof.Storage), query.Account, len(query.Slots))
+		}
+		for j, slot := range query.Slots {
+			if proof.Storage[j].Key != slot {
+				return fmt.Errorf("%w: unexpected proof of slot %#x", errInvalidStateProof, proof.Storage[j].Key)
+			}
+			// The empty storage holds no slot, and has no node to prove it
+			var value []byte
+			if account.Root != types.EmptyRootHash {
+				value, err = trie.VerifyProof(account.Root, slot[:], proofSet(proof.Storage[j].Proof))
+				if err != nil {
+					return fmt.Errorf("%w: slot %#x of account %#x: %v", errInvalidStateProof, slot, query.Account, err)
+				}
+			}
+			if !bytes.Equal(value, storages[query.Account][slot]) {
+				return fmt.Errorf("%w: slot %#x of account %#x", errStateProofMismatch, slot, query.Account)
+			}
+		}
+	}
+	return nil
+}
+
+// proofNodes converts the proof set to the list of the proof nodes.
+func proofNodes(proof *trienode.ProofSet) [][]byte {
+	var nodes [][]byte
+	for _, blob := range proof.List() {
+		nodes = append(nodes, blob)
+	}
+	return nodes
+}
+
+// proofSet converts the list of the proof nodes to the proof set.
+func proofSet(nodes [][]byte) *trienode.ProofSet {
+	list := make(trienode.ProofList, len(nodes))
+	for i, node := range nodes {
+		list[i] = node
+	}
+	return list.Set()
+}
@@ -1,19 +1,25 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
@@ -41,27 +47,118 @@ var (
 	verifyTaskFailedMeter  = metrics.NewRegisteredMeter("verifymanager/task/result/failed", nil)
 
 	verifyTaskExecutionTimer = metrics.NewRegisteredTimer("verifymanager/task/execution", nil)
+
+	verifyUnsignedRootMeter = metrics.NewRegisteredMeter("verifymanager/message/unsigned", nil)
 )
 
+// proofTimeout is the time after which the spot-check of the state is skipped if
+// no peer returned valid proofs, the block is then verified by the roots only.
+var proofTimeout = 10 * time.Second
+
+// Statuses of the remote verification of a block.
+const (
+	RemoteVerifyPending  = "pending"  // waiting for the quorum of verify peers
+	RemoteVerifyVerified = "verified" // the quorum of verify peers attested the root
+	RemoteVerifyFailed   = "failed"   // the task was pruned before reaching the quorum, or the state is invalid
+	RemoteVerifyTrusted  = "trusted"  // taken as verified without asking, e.g. empty blocks
+)
+
+// RemoteVerifyConfig is the quorum of verify peers that must attest the state root
+// of a block before it is taken as verified.
+type RemoteVerifyConfig struct {
+	Quorum    int      // Number of distinct peers that must return the matching root
+	Peers     int      // Number of peers the root is requested from at first
+	Proofs    int      // Number of accounts spot-checked against the root with the proofs of a trust/2 peer, 0 to disable
+	Allowlist []string // Node IDs of the peers allowed to verify, any peer if empty
+
+	// RequireSignature discards the roots not signed by the node key of the peer,
+	// the peers running an older version don't sign.
+	RequireSignature bool
+}
+
+// DefaultRemoteVerifyConfig trusts the first matching root of any verify peer.
+var DefaultRemoteVerifyConfig = RemoteVerifyConfig{
+	Quorum: 1,
+	Peers:  defaultPeerNumber,
+	Proofs: defaultProofAccounts,
+}
+
+// sanitize returns a copy of the config with the quorum reachable by the peers
+// asked at first and the node IDs in the format of the peer IDs.
+func (c RemoteVerifyConfig) sanitize() RemoteVerifyConfig {
+	if c.Quorum < 1 {
+		c.Quorum = 1
+	}
+	if c.Peers < c.Quorum {
+		c.Peers = c.Quorum
+	}
+	if c.Proofs < 0 {
+		c.Proofs = 0
+	}
+	allowlist := make([]string, 0, len(c.Allowlist))
+	for _, id := range c.Allowlist {
+		allowlist = append(allowlist, strings.ToLower(strings.TrimPrefix(id, "0x")))
+	}
+	c.Allowlist = allowlist
+	return c
+}
+
+// RootAttestation is the state root of a block returned by a verify peer.
+type RootAttestation struct {
+	Peer      string             `json:"peer"`
+	Status    types.VerifyStatus `json:"status"`
+	Root      common.Hash        `json:"root"`
+	Signature hexutil.Bytes      `json:"signature,omitempty"` // signed by the node key of the peer over VerifyResult.SigHash
+	Time      uint64             `json:"time"`                // unix time in milliseconds the root was received
+}
+
+// RemoteVerifyOutcome is the outcome of the remote verification of a block, along
+// with the roots returned by the verify peers.
+type RemoteVerifyOutcome struct {
+	BlockNumber  uint64             `json:"blockNumber"`
+	BlockHash    common.Hash        `json:"blockHash"`
+	Root         common.Hash        `json:"root"`
+	Status       string             `json:"status"`
+	Quorum       int                `json:"quorum"`
+	Attestations []*RootAttestation `json:"attestations"`       // the roots matching the block
+	Dissents     []*RootAttestation `json:"dissents,omitempty"` // the roots mismatching the block
+
+	ProofAccounts int    `json:"proofAccounts"`          // number of accounts spot-checked with the state proofs
+	ProofPeer     string `json:"proofPeer,omitempty"`    // peer serving the proofs passing the spot-check
+	ProofError    string `json:"proofError,omitempty"`   // why the state failed the spot-check
+	ProofSkipped  string `json:"proofSkipped,omitempty"` // why the spot-check was skipped
+}
+
+// copy returns a copy of the outcome safe to hand out while the task runs.
+func (o *RemoteVerifyOutcome) copy() *RemoteVerifyOutcome {
+	cpy := *o
+	cpy.Attestations = append([]*RootAttestation{}, o.Attestations...)
+	cpy.Dissents = append([]*RootAttestation(nil), o.Dissents...)
+	return &cpy
+}
+
 type remoteVerifyManager struct {
 	bc            *BlockChain
 	taskLock      sync.RWMutex
 	tasks         map[common.Hash]*verifyTask
 	peers         verifyPeers
 	verifiedCache *lru.Cache
+	outcomeCache  *lru.Cache
 	allowInsecure bool
+	config        RemoteVerifyConfig
 
 	// Subscription
 	chainBlockCh chan ChainHeadEvent
 	chainHeadSub event.Subscription
 
 	// Channels
-	verifyCh  chan common.Hash
+	verifyCh  chan *RemoteVerifyOutcome
 	messageCh chan verifyMessage
 }
 
-func NewVerifyManager(blockchain *BlockChain, peers verifyPeers, allowInsecure bool) (*remoteVerifyManager, error) {
+func NewVerifyManager(blockchain *BlockChain, peers verifyPeers, allowInsecure bool, config RemoteVerifyConfig) (*remoteVerifyManager, error) {
 	verifiedCache, _ := lru.New(verifiedCacheSize)
+	outcomeCache, _ := lru.New(verifiedCacheSize)
 	block := blockchain.CurrentBlock()
 	if block == nil {
 		return nil, ErrCurrentBlockNotFound
@@ -92,10 +189,12 @@ func NewVerifyManager(blockchain *BlockChain, peers verifyPeers, allowInsecure b
 		tasks:         make(map[common.Hash]*verifyTask),
 		peers:         peers,
 		verifiedCache: verifiedCache,
+		outcomeCache:  outcomeCache,
 		allowInsecure: allowInsecure,
+		config:        config.sanitize(),
 
 		chainBlockCh: make(chan ChainHeadEvent, chainHeadChanSize),
-		verifyCh:     make(chan common.Hash, maxForkHeight),
+		verifyCh:     make(chan *RemoteVerifyOutcome, maxForkHeight),
 		messageCh:    make(chan verifyMessage),
 	}
 	vm.chainHeadSub = blockchain.SubscribeChainBlockEvent(vm.chainBlockCh)
@@ -111,10 +210,19 @@ func (vm *remoteVerifyManager) mainLoop() {
 		select {
 		case h := <-vm.chainBlockCh:
 			vm.NewBlockVerifyTask(h.Block.Header())
-		case hash := <-vm.verifyCh:
-			vm.cacheBlockVerified(hash)
+		case outcome := <-vm.verifyCh:
+			if outcome.Status == RemoteVerifyFailed {
+				vm.taskLock.Lock()
+				if task, ok := vm.tasks[outcome.BlockHash]; ok {
+					vm.failTask(task, outcome.ProofError)
+				}
+				vm.taskLock.Unlock()
+				continue
+			}
+			vm.cacheBlockVerified(outcome.BlockHash)
+			vm.outcomeCache.Add(outcome.BlockHash, outcome)
 			vm.taskLock.Lock()
-			if task, ok := vm.tasks[hash]; ok {
+			if task, ok := vm.tasks[outcome.BlockHash]; ok {
 				vm.CloseTask(task)
 				verifyTaskSucceedMeter.Mark(1)
 				verifyTaskExecutionTimer.Update(time.Since(task.startAt))
@@ -125,12 +233,27 @@ func (vm *remoteVerifyManager) mainLoop() {
 			for _, task := range vm.tasks {
 				if vm.bc.insertStopped() || (vm.bc.CurrentHeader().Number.Cmp(task.blockHeader.Number) == 1 &&
 					vm.bc.CurrentHeader().Number.Uint64()-task.blockHeader.Number.Uint64() > pruneHeightDiff) {
+					outcome := task.outcome()
+					outcome.Status = RemoteVerifyFailed
+					vm.outcomeCache.Add(outcome.BlockHash, outcome)
 					vm.CloseTask(task)
 					verifyTaskFailedMeter.Mark(1)
 				}
 			}
 			vm.taskLock.Unlock()
 		case message := <-vm.messageCh:
+			// The diff hash of the verify peer differing from the one of the block
+			// imported from a diff layer means the diff layer is invalid
+			if !message.proofResponse && message.verifyResult.Status == types.StatusDiffHashMismatch {
+				if _, ok := vm.bc.appliedDiffPeer(message.verifyResult.BlockHash); ok {
+					vm.taskLock.Lock()
+					if vt, ok := vm.tasks[message.verifyResult.BlockHash]; ok && vt.allowed(message.peerId) {
+						vm.failTask(vt, message.verifyResult.Status.Msg)
+					}
+					vm.taskLock.Unlock()
+					continue
+				}
+			}
 			vm.taskLock.RLock()
 			if vt, ok := vm.tasks[message.verifyResult.BlockHash]; ok {
 				vt.messageCh <- message
@@ -192,7 +315,7 @@ func (vm *remoteVerifyManager) NewBlockVerifyTask(header *types.Header) {
 				log.Error("failed to get diff hash", "block", hash, "number", header.Number, "error", err)
 				return
 			}
-			verifyTask := NewVerifyTask(diffHash, header, vm.peers, vm.verifyCh, vm.allowInsecure)
+			verifyTask := NewVerifyTask(diffHash, diffLayer, header, vm.peers, vm.verifyCh, vm.allowInsecure, vm.config)
 			vm.taskLock.Lock()
 			vm.tasks[hash] = verifyTask
 			vm.taskLock.Unlock()
@@ -239,11 +362,62 @@ func (vm *remoteVerifyManager) AncestorVerified(header *types.Header) bool {
 	return exist
 }
 
-func (vm *remoteVerifyManager) HandleRootResponse(vr *VerifyResult, pid string) error {
-	vm.messageCh <- verifyMessage{verifyResult: vr, peerId: pid}
+// VerifyOutcome returns the outcome of the remote verification of a block, nil if
+// the block is unknown to the manager or has gone out of the cache.
+func (vm *remoteVerifyManager) VerifyOutcome(hash common.Hash) *RemoteVerifyOutcome {
+	vm.taskLock.RLock()
+	task, ok := vm.tasks[hash]
+	vm.taskLock.RUnlock()
+	if ok {
+		return task.outcome()
+	}
+	if cached, ok := vm.outcomeCache.Get(hash); ok {
+		return cached.(*RemoteVerifyOutcome).copy()
+	}
+	if _, ok := vm.verifiedCache.Get(hash); ok {
+		outcome := &RemoteVerifyOutcome{
+			BlockHash:    hash,
+			Status:       RemoteVerifyTrusted,
+			Attestations: []*RootAttestation{},
+		}
+		if header := vm.bc.GetHeaderByHash(hash); header != nil {
+			outcome.BlockNumber = header.Number.Uint64()
+			outcome.Root = header.Root
+		}
+		return outcome
+	}
+	return nil
+}
+
+// HandleRootResponse passes the root returned by a verify peer to the task of the
+// block, the signature is the one of the peer over the result, nil if unsigned.
+func (vm *remoteVerifyManager) HandleRootResponse(vr *VerifyResult, pid string, signature []byte) error {
+	vm.messageCh <- verifyMessage{verifyResult: vr, peerId: pid, signature: signature}
 	return nil
 }
 
+// HandleProofResponse passes the state proofs returned by a verify peer to the
+// task of the block.
+func (vm *remoteVerifyManager) HandleProofResponse(blockHash common.Hash, pid string, proofs []*AccountProof) error {
+	vm.messageCh <- verifyMessage{verifyResult: &VerifyResult{BlockHash: blockHash}, peerId: pid, proofs: proofs, proofResponse: true}
+	return nil
+}
+
+// failTask closes the task of the block whose state failed the verification. The
+// block is imported again by executing it if it was imported from a diff layer.
+// The task lock must be held.
+func (vm *remoteVerifyManager) failTask(task *verifyTask, reason string) {
+	outcome := task.outcome()
+	outcome.Status = RemoteVerifyFailed
+	vm.outcomeCache.Add(outcome.BlockHash, outcome)
+	vm.CloseTask(task)
+	verifyTaskFailedMeter.Mark(1)
+
+	if _, ok := vm.bc.appliedDiffPeer(outcome.BlockHash); ok {
+		go vm.bc.reimportDiffBlock(task.blockHeader, reason)
+	}
+}
+
 func (vm *remoteVerifyManager) CloseTask(task *verifyTask) {
 	delete(vm.tasks, task.blockHeader.Hash())
 	task.Close()
@@ -257,9 +431,28 @@ type VerifyResult struct {
 	Root        common.Hash
 }
 
+// SigHash returns the hash the verify node signs to attest the result.
+func (vr *VerifyResult) SigHash() common.Hash {
+	enc, _ := rlp.EncodeToBytes([]interface{}{vr.Status.Code, vr.BlockNumber, vr.BlockHash, vr.Root})
+	return crypto.Keccak256Hash(enc)
+}
+
+// Signer recovers the node ID of the verify node signing the result.
+func (vr *VerifyResult) Signer(signature []byte) (enode.ID, error) {
+	pubkey, err := crypto.SigToPub(vr.SigHash().Bytes(), signature)
+	if err != nil {
+		return enode.ID{}, err
+	}
+	return enode.PubkeyToIDV4(pubkey), nil
+}
+
 type verifyMessage struct {
 	verifyResult *VerifyResult
 	peerId       string
+	signature    []byte
+
+	proofs        []*AccountProof
+	proofResponse bool // whether the message carries the proofs rather than the root
 }
 
 type verifyTask struct {
@@ -269,25 +462,76 @@ type verifyTask struct {
 	badPeers       map[string]struct{}
 	startAt        time.Time
 	allowInsecure  bool
+	config         RemoteVerifyConfig
+	allowlist      map[string]struct{} // nil if any peer is allowed to verify
+
+	diff         *types.DiffLayer
+	proofQueries []AccountProofQuery
+	proofTried   map[string]struct{}
+	proofAsked   string // the peer asked for the proofs last
+
+	lock      sync.Mutex
+	attested  map[string]struct{}
+	proofDone bool // whether the state passed or skipped the spot-check
+	result    *RemoteVerifyOutcome
 
 	messageCh  chan verifyMessage
 	terminalCh chan struct{}
 }
 
-func NewVerifyTask(diffhash common.Hash, header *types.Header, peers verifyPeers, verifyCh chan common.Hash, allowInsecure bool) *verifyTask {
+func NewVerifyTask(diffhash common.Hash, diff *types.DiffLayer, header *types.Header, peers verifyPeers, verifyCh chan *RemoteVerifyOutcome, allowInsecure bool, config RemoteVerifyConfig) *verifyTask {
 	vt := &verifyTask{
 		diffhash:       diffhash,
 		blockHeader:    header,
 		candidatePeers: peers,
 		badPeers:       make(map[string]struct{}),
 		allowInsecure:  allowInsecure,
-		messageCh:      make(chan verifyMessage),
-		terminalCh:     make(chan struct{}),
+		config:         config,
+		diff:           diff,
+		proofTried:     make(map[string]struct{}),
+		attested:       make(map[string]struct{}),
+		result: &RemoteVerifyOutcome{
+			BlockNumber:  header.Number.Uint64(),
+			BlockHash:    header.Hash(),
+			Root:         header.Root,
+			Status:       RemoteVerifyPending,
+			Quorum:       config.Quorum,
+			Attestations: []*RootAttestation{},
+		},
+		messageCh:  make(chan verifyMessage),
+		terminalCh: make(chan struct{}),
+	}
+	if config.Proofs > 0 {
+		vt.proofQueries = sampleProofQueries(diff, config.Proofs, rand.New(rand.NewSource(time.Now().UnixNano())))
+		vt.result.ProofAccounts = len(vt.proofQueries)
+	}
+	vt.proofDone = len(vt.proofQueries) == 0
+	if len(config.Allowlist) > 0 {
+		vt.allowlist = make(map[string]struct{}, len(config.Allowlist))
+		for _, id := range config.Allowlist {
+			vt.allowlist[id] = struct{}{}
+		}
 	}
 	go vt.Start(verifyCh)
 	return vt
 }
 
+// outcome returns the outcome of the task so far.
+func (vt *verifyTask) outcome() *RemoteVerifyOutcome {
+	vt.lock.Lock()
+	defer vt.lock.Unlock()
+	return vt.result.copy()
+}
+
+// allowed returns true if the peer is allowed to verify the block.
+func (vt *verifyTask) allowed(peerId string) bool {
+	if vt.allowlist == nil {
+		return true
+	}
+	_, ok := vt.allowlist[peerId]
+	return ok
+}
+
 func (vt *verifyTask) Close() {
 	// It is safe to call close multiple
 	select {
@@ -297,15 +541,24 @@ func (vt *verifyTask) Close() {
 	}
 }
 
-func (vt *verifyTask) Start(verifyCh chan common.Hash) {
+func (vt *verifyTask) Start(verifyCh chan *RemoteVerifyOutcome) {
 	vt.startAt = time.Now()
 
-	vt.sendVerifyRequest(defaultPeerNumber)
+	vt.sendVerifyRequest(vt.config.Peers)
+	vt.sendProofRequest(verifyCh)
 	resend := time.NewTicker(resendInterval)
 	defer resend.Stop()
 	for {
 		select {
 		case msg := <-vt.messageCh:
+			if !vt.allowed(msg.peerId) {
+				log.Debug("ignore root from peer not allowed to verify", "hash", msg.verifyResult.BlockHash, "peer", msg.peerId)
+				continue
+			}
+			if msg.proofResponse {
+				vt.checkProofsAndMark(msg, verifyCh)
+				continue
+			}
 			switch msg.verifyResult.Status {
 			case types.StatusFullVerified:
 				vt.compareRootHashAndMark(msg, verifyCh)
@@ -324,10 +577,14 @@ func (vt *verifyTask) Start(verifyCh chan common.Hash) {
 		case <-resend.C:
 			// if a task has run over 15s, try all the vaild peers to verify.
 			if time.Since(vt.startAt) < tryAllPeersTime {
-				vt.sendVerifyRequest(1)
+				vt.lock.Lock()
+				missing := vt.config.Quorum - len(vt.attested)
+				vt.lock.Unlock()
+				vt.sendVerifyRequest(max(missing, 1))
 			} else {
 				vt.sendVerifyRequest(-1)
 			}
+			vt.sendProofRequest(verifyCh)
 		case <-vt.terminalCh:
 			return
 		}
@@ -335,15 +592,21 @@ func (vt *verifyTask) Start(verifyCh chan common.Hash) {
 }
 
 // sendVerifyRequest func select at most n peers from (candidatePeers-badPeers) randomly and send verify request.
-// when n<0, send to all the peers exclude badPeers.
+// when n<0, send to all the peers exclude badPeers. The peers not allowed to verify or
+// having attested the root already are skipped too.
 func (vt *verifyTask) sendVerifyRequest(n int) {
 	var validPeers []VerifyPeer
 	candidatePeers := vt.candidatePeers.GetVerifyPeers()
+	vt.lock.Lock()
 	for _, p := range candidatePeers {
-		if _, ok := vt.badPeers[p.ID()]; !ok {
+		if _, ok := vt.badPeers[p.ID()]; ok || !vt.allowed(p.ID()) {
+			continue
+		}
+		if _, ok := vt.attested[p.ID()]; !ok {
 			validPeers = append(validPeers, p)
 		}
 	}
+	vt.lock.Unlock()
 	// if has not valid peer, log warning.
 	if len(validPeers) == 0 {
 		log.Warn("there is no valid peer for block", "number", vt.blockHeader.Number)
@@ -363,13 +626,171 @@ func (vt *verifyTask) sendVerifyRequest(n int) {
 	}
 }
 
-func (vt *verifyTask) compareRootHashAndMark(msg verifyMessage, verifyCh chan common.Hash) {
-	if msg.verifyResult.Root == vt.blockHeader.Root {
+// compareRootHashAndMark records the root returned by the peer, and marks the block
+// verified once the quorum of distinct peers return the root of the block. If the
+// signature is required, only the roots signed by the node key of the peer count
+// unless insecure verify is allowed.
+func (vt *verifyTask) compareRootHashAndMark(msg verifyMessage, verifyCh chan *RemoteVerifyOutcome) {
+	if msg.signature != nil {
+		if signer, err := msg.verifyResult.Signer(msg.signature); err != nil || signer.String() != msg.peerId {
+			log.Debug("invalid root signature from peer", "hash", msg.verifyResult.BlockHash, "peer", msg.peerId, "signer", signer, "err", err)
+			msg.signature = nil
+		}
+	}
+	if msg.signature == nil {
+		verifyUnsignedRootMeter.Mark(1)
+		if vt.config.RequireSignature && !vt.allowInsecure {
+			log.Info("peer returns unsigned root", "hash", msg.verifyResult.BlockHash, "number", msg.verifyResult.BlockNumber, "peer", msg.peerId)
+			vt.badPeers[msg.peerId] = struct{}{}
+			return
+		}
+	}
+	attestation := &RootAttestation{
+		Peer:      msg.peerId,
+		Status:    msg.verifyResult.Status,
+		Root:      msg.verifyResult.Root,
+		Signature: msg.signature,
+		Time:      uint64(time.Now().UnixMilli()),
+	}
+
+	vt.lock.Lock()
+	if msg.verifyResult.Root != vt.blockHeader.Root {
+		vt.badPeers[msg.peerId] = struct{}{}
+		vt.result.Dissents = append(vt.result.Dissents, attestation)
+		vt.lock.Unlock()
+		return
+	}
+	if _, ok := vt.attested[msg.peerId]; ok || vt.result.Status != RemoteVerifyPending {
+		vt.lock.Unlock()
+		return
+	}
+	vt.attested[msg.peerId] = struct{}{}
+	vt.result.Attestations = append(vt.result.Attestations, attestation)
+	outcome := vt.markVerified()
+	vt.lock.Unlock()
+
+	if outcome != nil {
 		// write back to manager so that manager can cache the result and delete this task.
-		verifyCh <- msg.verifyResult.BlockHash
+		verifyCh <- outcome
+	}
+}
+
+// sendProofRequest asks a trust/2 peer not asked yet for the proofs of the sampled
+// state. The spot-check is skipped if none of the peers serves valid proofs in time,
+// so that the trust/1 peers keep working.
+func (vt *verifyTask) sendProofRequest(verifyCh chan *RemoteVerifyOutcome) {
+	vt.lock.Lock()
+	done := vt.proofDone || vt.result.ProofError != ""
+	vt.lock.Unlock()
+	if done {
+		return
+	}
+	if time.Since(vt.startAt) > proofTimeout {
+		vt.skipProofs("no valid state proofs in time", verifyCh)
+		return
+	}
+	for _, p := range vt.candidatePeers.GetVerifyPeers() {
+		peer, ok := p.(ProofPeer)
+		if !ok || !vt.allowed(p.ID()) {
+			continue
+		}
+		if _, ok := vt.badPeers[p.ID()]; ok {
+			continue
+		}
+		if _, ok := vt.proofTried[p.ID()]; ok {
+			continue
+		}
+		if err := peer.RequestProof(vt.blockHeader.Number.Uint64(), vt.blockHeader.Hash(), vt.proofQueries); err != nil {
+			continue
+		}
+		vt.proofTried[p.ID()] = struct{}{}
+		vt.proofAsked = p.ID()
+		return
+	}
+	// wait for the peer asked last unless it turned out bad
+	if _, bad := vt.badPeers[vt.proofAsked]; vt.proofAsked != "" && !bad {
+		return
+	}
+	if len(vt.proofTried) == 0 {
+		vt.skipProofs("no peer serves the state proofs", verifyCh)
 	} else {
+		vt.skipProofs("no peer left serving valid state proofs", verifyCh)
+	}
+}
+
+// skipProofs gives up the spot-check of the state, the block is verified once the
+// quorum of peers attested the root.
+func (vt *verifyTask) skipProofs(reason string, verifyCh chan *RemoteVerifyOutcome) {
+	log.Debug("skip the spot-check of the state", "hash", vt.blockHeader.Hash(), "number", vt.blockHeader.Number, "reason", reason)
+	vt.lock.Lock()
+	vt.proofDone = true
+	vt.result.ProofSkipped = reason
+	outcome := vt.markVerified()
+	vt.lock.Unlock()
+
+	if outcome != nil {
+		verifyCh <- outcome
+	}
+}
+
+// checkProofsAndMark spot-checks the state of the block with the proofs returned
+// by the peer. The peer is marked bad if the proofs are invalid, while the task
+// fails if the proven state differs from the local one. An empty
+// response means the peer doesn't have the state, the next peer is asked then.
+func (vt *verifyTask) checkProofsAndMark(msg verifyMessage, verifyCh chan *RemoteVerifyOutcome) {
+	if _, ok := vt.proofTried[msg.peerId]; !ok {
+		return
+	}
+	if len(msg.proofs) == 0 && len(vt.proofQueries) > 0 {
+		log.Debug("peer has no state to prove", "hash", vt.blockHeader.Hash(), "number", vt.blockHeader.Number, "peer", msg.peerId)
+		if vt.proofAsked == msg.peerId {
+			vt.proofAsked = ""
+		}
+		vt.sendProofRequest(verifyCh)
+		return
+	}
+	err := verifyStateProofs(vt.blockHeader.Root, vt.diff, vt.proofQueries, msg.proofs)
+	if errors.Is(err, errInvalidStateProof) {
+		log.Info("peer returns invalid state proofs", "hash", vt.blockHeader.Hash(), "number", vt.blockHeader.Number, "peer", msg.peerId, "err", err)
 		vt.badPeers[msg.peerId] = struct{}{}
+		vt.sendProofRequest(verifyCh)
+		return
+	}
+	vt.lock.Lock()
+	if vt.proofDone || vt.result.ProofError != "" {
+		vt.lock.Unlock()
+		return
+	}
+	if err != nil {
+		log.Error("block state fails the spot-check", "hash", vt.blockHeader.Hash(), "number", vt.blockHeader.Number, "peer", msg.peerId, "err", err)
+		vt.result.ProofPeer = msg.peerId
+		vt.result.ProofError = err.Error()
+		vt.result.Status = RemoteVerifyFailed
+		outcome := vt.result.copy()
+		vt.lock.Unlock()
+
+		verifyCh <- outcome
+		return
+	}
+	vt.proofDone = true
+	vt.result.ProofPeer = msg.peerId
+	outcome := vt.markVerified()
+	vt.lock.Unlock()
+
+	if outcome != nil {
+		verifyCh <- outcome
+	}
+}
+
+// markVerified marks the block verified once the quorum of peers attested the root
+// and the state passed the spot-check. It returns the outcome to write back to the
+// manager, nil if the block is not verified yet. The lock must be held.
+func (vt *verifyTask) markVerified() *RemoteVerifyOutcome {
+	if vt.result.Status != RemoteVerifyPending || len(vt.attested) < vt.config.Quorum || !vt.proofDone {
+		return nil
 	}
+	vt.result.Status = RemoteVerifyVerified
+	return vt.result.copy()
 }
 
 type VerifyPeer interface {
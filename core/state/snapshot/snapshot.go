@@ -393,6 +393,40 @@ func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs m
 	return nil
 }
 
+// Discard removes the diff layer of the given block root along with all the layers
+// built on top of it, e.g. to regenerate them by executing the blocks again. The
+// disk layer can't be discarded.
+func (t *Tree) Discard(root common.Hash) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.layers[root].(*diffLayer); !ok {
+		return fmt.Errorf("snapshot [%#x] is not a diff layer", root)
+	}
+	children := make(map[common.Hash][]common.Hash)
+	for root, snap := range t.layers {
+		if diff, ok := snap.(*diffLayer); ok {
+			parent := diff.parent.Root()
+			children[parent] = append(children[parent], root)
+		}
+	}
+	var remove func(root common.Hash)
+	remove = func(root common.Hash) {
+		if diff, ok := t.layers[root].(*diffLayer); ok {
+			diff.lock.Lock()
+			diff.stale.Store(true)
+			diff.lock.Unlock()
+		}
+		delete(t.layers, root)
+		for _, child := range children[root] {
+			remove(child)
+		}
+		delete(children, root)
+	}
+	remove(root)
+	return nil
+}
+
 func (t *Tree) CapLimit() int {
 	return t.capLimit
 }
@@ -332,6 +332,50 @@ func TestPostCapBasicDataAccess(t *testing.T) {
 
 // TestSnaphots tests the functionality for retrieving the snapshot
 // with given head root and the desired depth.
+// Tests that discarding a diff layer removes the layers built on top of it too,
+// and leaves the other branches untouched.
+func TestDiscard(t *testing.T) {
+	base := &diskLayer{
+		diskdb: rawdb.NewMemoryDatabase(),
+		root:   common.HexToHash("0x01"),
+		cache:  fastcache.New(1024 * 500),
+	}
+	snaps := &Tree{
+		layers: map[common.Hash]snapshot{
+			base.root: base,
+		},
+	}
+	snaps.Update(common.HexToHash("0xa1"), common.HexToHash("0x01"), nil, randomAccountSet("0xa1"), nil, nil)
+	snaps.Update(common.HexToHash("0xa2"), common.HexToHash("0xa1"), nil, randomAccountSet("0xa2"), nil, nil)
+	snaps.Update(common.HexToHash("0xa3"), common.HexToHash("0xa2"), nil, randomAccountSet("0xa3"), nil, nil)
+	snaps.Update(common.HexToHash("0xb2"), common.HexToHash("0xa1"), nil, randomAccountSet("0xb2"), nil, nil)
+
+	if err := snaps.Discard(base.root); err == nil {
+		t.Fatal("disk layer discarded")
+	}
+	stale := snaps.Snapshot(common.HexToHash("0xa3"))
+	if err := snaps.Discard(common.HexToHash("0xa2")); err != nil {
+		t.Fatalf("failed to discard layer: %v", err)
+	}
+	for _, root := range []string{"0xa2", "0xa3"} {
+		if snaps.Snapshot(common.HexToHash(root)) != nil {
+			t.Errorf("layer %s not discarded", root)
+		}
+	}
+	for _, root := range []string{"0x01", "0xa1", "0xb2"} {
+		if snaps.Snapshot(common.HexToHash(root)) == nil {
+			t.Errorf("layer %s discarded", root)
+		}
+	}
+	if _, err := stale.Account(common.HexToHash("0xa3")); err != ErrSnapshotStale {
+		t.Errorf("discarded layer error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	// The discarded layer can be regenerated on top of its parent
+	if err := snaps.Update(common.HexToHash("0xa2"), common.HexToHash("0xa1"), nil, randomAccountSet("0xa2"), nil, nil); err != nil {
+		t.Fatalf("failed to regenerate layer: %v", err)
+	}
+}
+
 func TestSnaphots(t *testing.T) {
 	// setAccount is a helper to construct a random account entry and assign it to
 	// an account slot in a snapshot
@@ -92,6 +92,7 @@ type StateDB struct {
 	storages       map[common.Hash]map[common.Hash][]byte    // The mutated slots in prefix-zero trimmed rlp format
 	accountsOrigin map[common.Address][]byte                 // The original value of mutated accounts in 'slim RLP' encoding
 	storagesOrigin map[common.Address]map[common.Hash][]byte // The original value of mutated slots in prefix-zero trimmed rlp format
+	diffCodes      []types.DiffCode                          // The contract codes of the applied diff layer, written on commit
 
 	// This map holds 'live' objects, which will get modified while processing
 	// a state transition.
@@ -935,6 +936,7 @@ func (s *StateDB) copyInternal(doPrefetch bool) *StateDB {
 	state.storages = copy2DSet(s.storages)
 	state.accountsOrigin = copySet(state.accountsOrigin)
 	state.storagesOrigin = copy2DSet(state.storagesOrigin)
+	state.diffCodes = s.diffCodes
 
 	// Deep copy the logs occurred in the scope of block
 	for hash, logs := range s.logs {
@@ -1679,6 +1681,12 @@ func (s *StateDB) Commit(block uint64, failPostCommitFunc func(), postCommitFunc
 					}
 				}
 			}
+			for _, code := range s.diffCodes {
+				rawdb.WriteCode(codeWriter, code.Hash, code.Code)
+				if s.snap != nil {
+					diffLayer.Codes = append(diffLayer.Codes, code)
+				}
+			}
 			if codeWriter.ValueSize() > 0 {
 				if err := codeWriter.Write(); err != nil {
 					log.Crit("Failed to commit dirty codes", "error", err)
@@ -1758,9 +1766,60 @@ func (s *StateDB) Commit(block uint64, failPostCommitFunc func(), postCommitFunc
 	s.storagesOrigin = make(map[common.Address]map[common.Hash][]byte)
 	s.stateObjectsDirty = make(map[common.Address]struct{})
 	s.stateObjectsDestruct = make(map[common.Address]*types.StateAccount)
+	s.diffCodes = nil
 	return root, diffLayer, nil
 }
 
+// ApplyDiffLayer applies the state changes of a block carried by its diff layer,
+// instead of executing the transactions of the block. The changes are written
+// to the snapshot on commit.
+//
+// It's only supported without tries, as the diff layer is keyed by the hashes of
+// the accounts and slots, and the storage roots in it can't be trusted.
+func (s *StateDB) ApplyDiffLayer(diff *types.DiffLayer) error {
+	if !s.noTrie {
+		return errors.New("diff layer can't be applied with tries")
+	}
+	if len(s.journal.entries) != 0 || len(s.stateObjectsPending) != 0 || len(s.accounts) != 0 || len(s.stateObjectsDestruct) != 0 {
+		return errors.New("diff layer applied to modified state")
+	}
+	// Check the whole diff layer before touching the state, so that it can be
+	// still processed by the transactions if the diff layer is rejected.
+	for _, code := range diff.Codes {
+		if crypto.Keccak256Hash(code.Code) != code.Hash {
+			return fmt.Errorf("invalid code hash %#x", code.Hash)
+		}
+	}
+	for _, account := range diff.Accounts {
+		if len(account.Blob) == 0 {
+			continue
+		}
+		if _, err := types.FullAccount(account.Blob); err != nil {
+			return fmt.Errorf("invalid account %#x: %v", account.Account, err)
+		}
+	}
+	for _, storage := range diff.Storages {
+		if len(storage.Keys) != len(storage.Vals) {
+			return fmt.Errorf("invalid storage of account %#x: %d keys, %d values", storage.Account, len(storage.Keys), len(storage.Vals))
+		}
+	}
+	for _, addr := range diff.Destructs {
+		s.stateObjectsDestruct[addr] = nil
+	}
+	for _, account := range diff.Accounts {
+		s.accounts[account.Account] = account.Blob
+	}
+	for _, storage := range diff.Storages {
+		slots := make(map[common.Hash][]byte, len(storage.Keys))
+		for i, key := range storage.Keys {
+			slots[key] = storage.Vals[i]
+		}
+		s.storages[storage.Account] = slots
+	}
+	s.diffCodes = diff.Codes
+	return nil
+}
+
 func (s *StateDB) SnapToDiffLayer() ([]common.Address, []types.DiffAccount, []types.DiffStorage) {
 	destructs := make([]common.Address, 0, len(s.stateObjectsDestruct))
 	for account := range s.stateObjectsDestruct {
@@ -1878,6 +1937,26 @@ func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addre
 	return s.accessList.Contains(addr, slot)
 }
 
+// AccessList returns the accounts and storage slots accessed by the current
+// transaction, including the ones warmed before the execution.
+func (s *StateDB) AccessList() types.AccessList {
+	if s.accessList == nil {
+		return nil
+	}
+	list := make(types.AccessList, 0, len(s.accessList.addresses))
+	for addr, idx := range s.accessList.addresses {
+		tuple := types.AccessTuple{Address: addr}
+		if idx >= 0 {
+			tuple.StorageKeys = make([]common.Hash, 0, len(s.accessList.slots[idx]))
+			for slot := range s.accessList.slots[idx] {
+				tuple.StorageKeys = append(tuple.StorageKeys, slot)
+			}
+		}
+		list = append(list, tuple)
+	}
+	return list
+}
+
 func (s *StateDB) GetStorage(address common.Address) *sync.Map {
 	return s.storagePool.getStorage(address)
 }
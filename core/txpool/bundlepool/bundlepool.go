@@ -0,0 +1,337 @@
+// Package bundlepool implements the pool of the transaction bundles submitted by
+// the searchers, which are merged into the local blocks by the miner.
+package bundlepool
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrEmptyBundle is returned if the bundle contains no transaction.
+	ErrEmptyBundle = errors.New("empty bundle")
+
+	// ErrBundleTooLarge is returned if the bundle contains more transactions than allowed.
+	ErrBundleTooLarge = errors.New("too many transactions in bundle")
+
+	// ErrBlobTxInBundle is returned if the bundle contains a blob transaction.
+	ErrBlobTxInBundle = errors.New("blob transaction not supported in bundle")
+
+	// ErrBundleOutOfRange is returned if the block range or timestamp range of the
+	// bundle is invalid or already passed.
+	ErrBundleOutOfRange = errors.New("bundle out of range")
+)
+
+var bundleGauge = metrics.NewRegisteredGauge("bundlepool/bundles", nil)
+
+// BlockChain defines the minimal set of methods needed to back a bundle pool with
+// a chain. Exists to allow mocking the live chain out of tests.
+type BlockChain interface {
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// CurrentBlock returns the current head of the chain.
+	CurrentBlock() *types.Header
+}
+
+// BundlePool is the subpool keeping the transaction bundles. It does not accept
+// plain transactions, the bundles are submitted through AddBundle only.
+type BundlePool struct {
+	config Config
+	chain  BlockChain
+	signer types.Signer
+
+	mu      sync.RWMutex
+	head    *types.Header
+	gasTip  *big.Int
+	bundles map[common.Hash]*types.Bundle
+	heap    bundleHeap // bundles sorted by price, the cheapest one is evicted first
+}
+
+// New creates a new bundle pool.
+func New(config Config, chain BlockChain) *BundlePool {
+	config = (&config).sanitize()
+
+	return &BundlePool{
+		config:  config,
+		chain:   chain,
+		signer:  types.LatestSigner(chain.Config()),
+		gasTip:  new(big.Int),
+		bundles: make(map[common.Hash]*types.Bundle),
+	}
+}
+
+// Filter returns false since the bundle pool does not accept plain transactions.
+func (p *BundlePool) Filter(tx *types.Transaction) bool {
+	return false
+}
+
+// Init sets the gas price needed to keep a bundle in the pool and the chain head.
+func (p *BundlePool) Init(gasTip uint64, head *types.Header, reserve txpool.AddressReserver) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gasTip = new(big.Int).SetUint64(gasTip)
+	p.head = head
+	return nil
+}
+
+// Close terminates the bundle pool.
+func (p *BundlePool) Close() error {
+	log.Info("Bundle pool stopped")
+	return nil
+}
+
+// Reset drops the bundles which can not be included after the new head anymore.
+func (p *BundlePool) Reset(oldHead, newHead *types.Header) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.head = newHead
+	for hash, bundle := range p.bundles {
+		if bundle.MaxBlockNumber <= newHead.Number.Uint64() ||
+			(bundle.MaxTimestamp != 0 && bundle.MaxTimestamp <= newHead.Time) {
+			p.drop(hash)
+		}
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+}
+
+// SetGasTip updates the minimum price required by the pool for a new bundle,
+// and drops all bundles below this threshold.
+func (p *BundlePool) SetGasTip(tip *big.Int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gasTip = new(big.Int).Set(tip)
+	for p.heap.Len() > 0 && p.heap[0].Price.Cmp(p.gasTip) < 0 {
+		p.drop(p.heap[0].Hash())
+	}
+	bundleGauge.Update(int64(len(p.bundles)))
+}
+
+// Has returns false since the bundle pool keeps no plain transaction.
+func (p *BundlePool) Has(hash common.Hash) bool {
+	return false
+}
+
+// Get returns nil since the bundle pool keeps no plain transaction.
+func (p *BundlePool) Get(hash common.Hash) *types.Transaction {
+	return nil
+}
+
+// Add rejects the plain transactions, they should be added as a bundle.
+func (p *BundlePool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		errs[i] = errors.New("bundle pool does not accept plain transactions")
+	}
+	return errs
+}
+
+// Pending returns nothing, the bundles are retrieved through PendingBundles.
+func (p *BundlePool) Pending(filter txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
+	return nil
+}
+
+// SubscribeTransactions returns a subscription never firing, as no plain
+// transaction is kept by the pool.
+func (p *BundlePool) SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// SubscribeReannoTxsEvent returns a subscription never firing, as no plain
+// transaction is kept by the pool.
+func (p *BundlePool) SubscribeReannoTxsEvent(ch chan<- core.ReannoTxsEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// Nonce returns 0 since the bundle pool tracks no account.
+func (p *BundlePool) Nonce(addr common.Address) uint64 {
+	return 0
+}
+
+// Stats returns nothing since the bundle pool keeps no plain transaction.
+func (p *BundlePool) Stats() (int, int) {
+	return 0, 0
+}
+
+// Content returns nothing since the bundle pool keeps no plain transaction.
+func (p *BundlePool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
+	return make(map[common.Address][]*types.Transaction), make(map[common.Address][]*types.Transaction)
+}
+
+// ContentFrom returns nothing since the bundle pool keeps no plain transaction.
+func (p *BundlePool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	return []*types.Transaction{}, []*types.Transaction{}
+}
+
+// Locals returns nothing since the bundle pool has no local account.
+func (p *BundlePool) Locals() []common.Address {
+	return []common.Address{}
+}
+
+// Status returns unknown since the bundle pool keeps no plain transaction.
+func (p *BundlePool) Status(hash common.Hash) txpool.TxStatus {
+	return txpool.TxStatusUnknown
+}
+
+// AddBundle validates the bundle and enqueues it into the pool. If the pool is
+// full, the cheapest bundle is evicted if the new one offers a higher price.
+func (p *BundlePool) AddBundle(bundle *types.Bundle) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.validateBundle(bundle); err != nil {
+		return err
+	}
+	hash := bundle.Hash()
+	if _, ok := p.bundles[hash]; ok {
+		return txpool.ErrAlreadyKnown
+	}
+	if uint64(len(p.bundles)) >= p.config.GlobalSlots {
+		if bundle.Price.Cmp(p.heap[0].Price) <= 0 {
+			return txpool.ErrUnderpriced
+		}
+		log.Debug("Evicting cheapest bundle", "hash", p.heap[0].Hash(), "price", p.heap[0].Price)
+		p.drop(p.heap[0].Hash())
+	}
+	p.bundles[hash] = bundle
+	heap.Push(&p.heap, bundle)
+	bundleGauge.Update(int64(len(p.bundles)))
+
+	log.Debug("Bundle added into pool", "hash", hash, "txs", len(bundle.Txs), "minBlock", bundle.MinBlockNumber,
+		"maxBlock", bundle.MaxBlockNumber, "price", bundle.Price)
+	return nil
+}
+
+// PendingBundles retrieves the bundles includable in the block with the given
+// number and timestamp, sorted by price.
+func (p *BundlePool) PendingBundles(blockNumber uint64, blockTimestamp uint64) []*types.Bundle {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	bundles := make([]*types.Bundle, 0, len(p.bundles))
+	for _, bundle := range p.bundles {
+		if bundle.Includable(blockNumber, blockTimestamp) {
+			bundles = append(bundles, bundle)
+		}
+	}
+	sort.SliceStable(bundles, func(i, j int) bool {
+		return bundles[i].Price.Cmp(bundles[j].Price) > 0
+	})
+	return bundles
+}
+
+// validateBundle checks the bundle against the pool rules and the current head,
+// filling the default block range and the price of the bundle.
+func (p *BundlePool) validateBundle(bundle *types.Bundle) error {
+	if len(bundle.Txs) == 0 {
+		return ErrEmptyBundle
+	}
+	if uint64(len(bundle.Txs)) > p.config.MaxBundleTxs {
+		return fmt.Errorf("%w: have %d, max %d", ErrBundleTooLarge, len(bundle.Txs), p.config.MaxBundleTxs)
+	}
+	head := p.head
+	if head == nil {
+		head = p.chain.CurrentBlock()
+	}
+	next := head.Number.Uint64() + 1
+	if bundle.MinBlockNumber < next {
+		bundle.MinBlockNumber = next
+	}
+	if bundle.MaxBlockNumber == 0 {
+		bundle.MaxBlockNumber = head.Number.Uint64() + p.config.MaxBundleBlocks
+	}
+	if bundle.MaxBlockNumber < bundle.MinBlockNumber || bundle.MaxBlockNumber > head.Number.Uint64()+p.config.MaxBundleBlocks {
+		return fmt.Errorf("%w: block range [%d, %d], head %d", ErrBundleOutOfRange, bundle.MinBlockNumber, bundle.MaxBlockNumber, head.Number)
+	}
+	if bundle.MaxTimestamp != 0 && (bundle.MaxTimestamp <= head.Time || bundle.MaxTimestamp < bundle.MinTimestamp) {
+		return fmt.Errorf("%w: timestamp range [%d, %d], head time %d", ErrBundleOutOfRange, bundle.MinTimestamp, bundle.MaxTimestamp, head.Time)
+	}
+
+	var (
+		gas   uint64
+		value = new(big.Int)
+	)
+	for _, tx := range bundle.Txs {
+		if tx.Type() == types.BlobTxType {
+			return ErrBlobTxInBundle
+		}
+		if _, err := types.Sender(p.signer, tx); err != nil {
+			return txpool.ErrInvalidSender
+		}
+		// Every transaction must cover its intrinsic gas, which also keeps the
+		// total gas of the bundle above zero for the price below
+		intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, p.chain.Config().IsIstanbul(head.Number), p.chain.Config().IsShanghai(head.Number, head.Time))
+		if err != nil {
+			return err
+		}
+		if tx.Gas() < intrGas {
+			return fmt.Errorf("%w: gas %v, minimum needed %v", core.ErrIntrinsicGas, tx.Gas(), intrGas)
+		}
+		gas += tx.Gas()
+		value.Add(value, new(big.Int).Mul(tx.EffectiveGasTipValue(head.BaseFee), new(big.Int).SetUint64(tx.Gas())))
+	}
+	if gas > head.GasLimit {
+		return txpool.ErrGasLimit
+	}
+	bundle.Price = value.Div(value, new(big.Int).SetUint64(gas))
+	if bundle.Price.Cmp(p.gasTip) < 0 {
+		return txpool.ErrUnderpriced
+	}
+	return nil
+}
+
+// drop removes the bundle from the pool, the lock must be held.
+func (p *BundlePool) drop(hash common.Hash) {
+	if _, ok := p.bundles[hash]; !ok {
+		return
+	}
+	delete(p.bundles, hash)
+	for i, bundle := range p.heap {
+		if bundle.Hash() == hash {
+			heap.Remove(&p.heap, i)
+			break
+		}
+	}
+}
+
+// bundleHeap is a min-heap of the bundles ordered by price.
+type bundleHeap []*types.Bundle
+
+func (h bundleHeap) Len() int           { return len(h) }
+func (h bundleHeap) Less(i, j int) bool { return h[i].Price.Cmp(h[j].Price) < 0 }
+func (h bundleHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *bundleHeap) Push(x any) {
+	*h = append(*h, x.(*types.Bundle))
+}
+
+func (h *bundleHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[0 : n-1]
+	return x
+}
@@ -0,0 +1,87 @@
+package bundlepool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type testBlockChain struct {
+	head *types.Header
+}
+
+func (bc *testBlockChain) Config() *params.ChainConfig { return params.TestChainConfig }
+func (bc *testBlockChain) CurrentBlock() *types.Header { return bc.head }
+
+func newTestBundle(t *testing.T, nonce uint64, gasPrice int64) *types.Bundle {
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(1), params.TxGas, big.NewInt(gasPrice), nil),
+		types.LatestSigner(params.TestChainConfig), key)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	return &types.Bundle{Txs: types.Transactions{tx}}
+}
+
+func TestBundlePool(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(10), GasLimit: 30_000_000, Time: 100}
+	config := Config{GlobalSlots: 2, MaxBundleTxs: 2, MaxBundleBlocks: 5}
+	pool := New(config, &testBlockChain{head: head})
+	if err := pool.Init(1, head, nil); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+
+	if err := pool.AddBundle(&types.Bundle{}); !errors.Is(err, ErrEmptyBundle) {
+		t.Fatalf("empty bundle: have %v, want %v", err, ErrEmptyBundle)
+	}
+	key, _ := crypto.GenerateKey()
+	noGas, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), 0, big.NewInt(10), nil), types.LatestSigner(params.TestChainConfig), key)
+	if err := pool.AddBundle(&types.Bundle{Txs: types.Transactions{noGas}}); !errors.Is(err, core.ErrIntrinsicGas) {
+		t.Fatalf("bundle without gas: have %v, want %v", err, core.ErrIntrinsicGas)
+	}
+	if err := pool.AddBundle(newTestBundle(t, 0, 0)); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Fatalf("underpriced bundle: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	outOfRange := newTestBundle(t, 0, 10)
+	outOfRange.MaxBlockNumber = 16
+	if err := pool.AddBundle(outOfRange); !errors.Is(err, ErrBundleOutOfRange) {
+		t.Fatalf("out of range bundle: have %v, want %v", err, ErrBundleOutOfRange)
+	}
+
+	cheap, pricey := newTestBundle(t, 0, 10), newTestBundle(t, 0, 30)
+	cheap.MaxBlockNumber = 12
+	if err := pool.AddBundle(cheap); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if err := pool.AddBundle(pricey); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if cheap.MinBlockNumber != 11 || pricey.MaxBlockNumber != 15 {
+		t.Fatalf("default block range mismatch: have [%d, %d]", cheap.MinBlockNumber, pricey.MaxBlockNumber)
+	}
+	// The pool is full, the cheapest bundle is evicted by a better one only
+	if err := pool.AddBundle(newTestBundle(t, 0, 5)); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Fatalf("underpriced bundle in full pool: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	middle := newTestBundle(t, 0, 20)
+	if err := pool.AddBundle(middle); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	pending := pool.PendingBundles(11, 103)
+	if len(pending) != 2 || pending[0] != pricey || pending[1] != middle {
+		t.Fatalf("pending bundles mismatch: have %d bundles", len(pending))
+	}
+
+	// The bundles out of the block range are dropped on reset
+	pool.Reset(head, &types.Header{Number: big.NewInt(15), GasLimit: 30_000_000, Time: 115})
+	if len(pool.bundles) != 0 {
+		t.Fatalf("bundles not dropped on reset: %d", len(pool.bundles))
+	}
+}
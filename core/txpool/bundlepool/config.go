@@ -0,0 +1,39 @@
+package bundlepool
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Config are the configuration parameters of the bundle pool.
+type Config struct {
+	Enabled         bool   // Whether bundles are accepted, the pool and its API are not created otherwise
+	GlobalSlots     uint64 // Maximum number of bundles kept in the pool
+	MaxBundleTxs    uint64 // Maximum number of transactions in a bundle
+	MaxBundleBlocks uint64 // Maximum number of blocks a bundle may target ahead of the head
+}
+
+// DefaultConfig contains the default configurations for the bundle pool.
+var DefaultConfig = Config{
+	GlobalSlots:     4096,
+	MaxBundleTxs:    32,
+	MaxBundleBlocks: 100,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() Config {
+	conf := *config
+	if conf.GlobalSlots < 1 {
+		log.Warn("Sanitizing invalid bundlepool global slots", "provided", conf.GlobalSlots, "updated", DefaultConfig.GlobalSlots)
+		conf.GlobalSlots = DefaultConfig.GlobalSlots
+	}
+	if conf.MaxBundleTxs < 1 {
+		log.Warn("Sanitizing invalid bundlepool max bundle txs", "provided", conf.MaxBundleTxs, "updated", DefaultConfig.MaxBundleTxs)
+		conf.MaxBundleTxs = DefaultConfig.MaxBundleTxs
+	}
+	if conf.MaxBundleBlocks < 1 {
+		log.Warn("Sanitizing invalid bundlepool max bundle blocks", "provided", conf.MaxBundleBlocks, "updated", DefaultConfig.MaxBundleBlocks)
+		conf.MaxBundleBlocks = DefaultConfig.MaxBundleBlocks
+	}
+	return conf
+}
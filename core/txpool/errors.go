@@ -63,4 +63,8 @@ var (
 
 	// ErrInBlackList is returned if the transaction send by banned address
 	ErrInBlackList = errors.New("sender or to in black list")
+
+	// ErrBundlePoolNotEnabled is returned if a bundle is submitted while no bundle
+	// subpool is running.
+	ErrBundlePoolNotEnabled = errors.New("bundle pool is not enabled")
 )
@@ -167,3 +167,14 @@ type SubPool interface {
 	// identified by their hashes.
 	Status(hash common.Hash) TxStatus
 }
+
+// BundleSubpool represents a subpool keeping the transaction bundles of the
+// searchers besides the plain transactions.
+type BundleSubpool interface {
+	// AddBundle enqueues a bundle into the pool if it is valid.
+	AddBundle(bundle *types.Bundle) error
+
+	// PendingBundles retrieves the bundles includable in the block with the given
+	// number and timestamp, sorted by price.
+	PendingBundles(blockNumber uint64, blockTimestamp uint64) []*types.Bundle
+}
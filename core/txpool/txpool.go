@@ -469,6 +469,27 @@ func (p *TxPool) Locals() []common.Address {
 	return flat
 }
 
+// AddBundle enqueues a bundle into the bundle subpool.
+func (p *TxPool) AddBundle(bundle *types.Bundle) error {
+	for _, subpool := range p.subpools {
+		if bundlePool, ok := subpool.(BundleSubpool); ok {
+			return bundlePool.AddBundle(bundle)
+		}
+	}
+	return ErrBundlePoolNotEnabled
+}
+
+// PendingBundles retrieves the bundles includable in the block with the given
+// number and timestamp, sorted by price.
+func (p *TxPool) PendingBundles(blockNumber uint64, blockTimestamp uint64) []*types.Bundle {
+	for _, subpool := range p.subpools {
+		if bundlePool, ok := subpool.(BundleSubpool); ok {
+			return bundlePool.PendingBundles(blockNumber, blockTimestamp)
+		}
+	}
+	return nil
+}
+
 // Status returns the known status (unknown/pending/queued) of a transaction
 // identified by its hash.
 func (p *TxPool) Status(hash common.Hash) TxStatus {
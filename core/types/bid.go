@@ -184,3 +184,118 @@ type MevParams struct {
 	GasCeil               uint64
 	BuilderFeeCeil        *big.Int
 }
+
+const (
+	BidOutcomeIgnored     = "ignored"     // the bid is not simulated, e.g. lower reward than the best bid
+	BidOutcomeInterrupted = "interrupted" // the simulation is interrupted by a better bid or a new head
+	BidOutcomeFailed      = "failed"      // the simulation failed, the issue is reported to the builder
+	BidOutcomeSimulated   = "simulated"   // the simulation completed but the bid is not the best one
+	BidOutcomeBest        = "best"        // the bid became the best bid of the block
+	BidOutcomeWon         = "won"         // the block is sealed with the bid
+)
+
+// BidRecord is the audit record of a bid received by the validator, explaining
+// the outcome of the bid to the builders.
+type BidRecord struct {
+	BlockNumber             uint64         `json:"blockNumber"`
+	ParentHash              common.Hash    `json:"parentHash"`
+	Builder                 common.Address `json:"builder"`
+	BidHash                 common.Hash    `json:"bidHash"`
+	GasUsed                 uint64         `json:"gasUsed"`
+	GasFee                  *big.Int       `json:"gasFee"`
+	BuilderFee              *big.Int       `json:"builderFee"`
+	ExpectedBlockReward     *big.Int       `json:"expectedBlockReward"`
+	ExpectedValidatorReward *big.Int       `json:"expectedValidatorReward"`
+	PackedBlockReward       *big.Int       `json:"packedBlockReward"`
+	PackedValidatorReward   *big.Int       `json:"packedValidatorReward"`
+	MergedTxCount           uint64         `json:"mergedTxCount,omitempty"`     // mempool txs merged into the bid
+	MergedBlockReward       *big.Int       `json:"mergedBlockReward,omitempty"` // block reward of the merged txs, included in the packed reward
+	SimulationTime          time.Duration  `json:"simulationTime"`              // in nanoseconds
+	Outcome                 string         `json:"outcome"`
+	Error                   string         `json:"error,omitempty"`
+	SealedBlockHash         *common.Hash   `json:"sealedBlockHash,omitempty"` // set if the bid won
+	Time                    uint64         `json:"time"`                      // unix time in milliseconds of the record
+}
+
+const (
+	BidStatusReceived   = "received"   // the bid is received from a registered builder
+	BidStatusQueued     = "queued"     // the bid is queued to be judged for simulation
+	BidStatusSimulating = "simulating" // the bid is put into simulation
+	BidStatusSimulated  = "simulated"  // the simulation completed and the bid is the best one so far
+	BidStatusSelected   = "selected"   // the block is sealed with the bid
+	BidStatusBeaten     = "beaten"     // a better bid is preferred to the bid
+	BidStatusRejected   = "rejected"   // the bid is rejected with an error
+)
+
+// BidStatus is the event notified to the builder as its bid makes progress in
+// the validator.
+type BidStatus struct {
+	BidHash               common.Hash    `json:"bidHash"`
+	BlockNumber           uint64         `json:"blockNumber"`
+	ParentHash            common.Hash    `json:"parentHash"`
+	Builder               common.Address `json:"builder"`
+	Status                string         `json:"status"`
+	PackedBlockReward     *big.Int       `json:"packedBlockReward,omitempty"`     // set once the bid is simulated
+	PackedValidatorReward *big.Int       `json:"packedValidatorReward,omitempty"` // set once the bid is simulated
+	ErrorCode             int            `json:"errorCode,omitempty"`             // code of core/types/bid_error.go, set if rejected
+	Error                 string         `json:"error,omitempty"`
+	Time                  uint64         `json:"time"` // unix time in milliseconds of the event
+}
+
+// BidStatusAuthWindow is the max difference between the local time and the time
+// signed by the builder subscribing to the status of its bids.
+const BidStatusAuthWindow = 30 * time.Second
+
+// BidStatusAuthHash returns the hash the builder signs to subscribe to the status
+// of its bids at the given unix time in seconds.
+func BidStatusAuthHash(builder common.Address, timestamp uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("mev_subscribeBidStatus"), builder.Bytes(), new(big.Int).SetUint64(timestamp).FillBytes(make([]byte, 8)))
+}
+
+// BuilderReputation is the track record of a builder kept by the validator. The
+// counters are reset once the builder is penalized for crossing a threshold, or
+// once the decay window of the policy has passed since WindowStart.
+type BuilderReputation struct {
+	Builder           common.Address `json:"builder"`
+	InvalidBids       uint64         `json:"invalidBids"`           // bids rejected before simulation
+	FailedSimulations uint64         `json:"failedSimulations"`     // bids with a transaction failing in simulation
+	RewardShortfalls  uint64         `json:"rewardShortfalls"`      // bids packing less reward than expected
+	WindowStart       uint64         `json:"windowStart,omitempty"` // unix time in seconds the counters are kept since
+	Penalties         uint64         `json:"penalties"`
+	PenaltyUntil      uint64         `json:"penaltyUntil,omitempty"`  // unix time in seconds the penalty ends at
+	Deprioritized     bool           `json:"deprioritized,omitempty"` // whether the penalty deprioritizes rather than suspends the builder
+}
+
+// Penalized returns true if the builder is under penalty at the given time.
+func (r *BuilderReputation) Penalized(now time.Time) bool {
+	return r.PenaltyUntil > uint64(now.Unix())
+}
+
+const (
+	BlockCandidateLocal = "local" // the block built by the validator itself
+	BlockCandidateBid   = "bid"   // the block built from the best bid of the builders
+)
+
+// BlockCandidate is a candidate of an in-turn block.
+type BlockCandidate struct {
+	BlockReward     *big.Int        `json:"blockReward"`
+	ValidatorReward *big.Int        `json:"validatorReward"`
+	GasUsed         uint64          `json:"gasUsed"`
+	TxCount         int             `json:"txCount"`
+	Builder         *common.Address `json:"builder,omitempty"` // set if the candidate is a bid
+	BidHash         *common.Hash    `json:"bidHash,omitempty"` // set if the candidate is a bid
+}
+
+// BlockDecision explains the choice between the local block and the best bid for
+// an in-turn block sealed by the validator.
+type BlockDecision struct {
+	BlockNumber           uint64          `json:"blockNumber"`
+	ParentHash            common.Hash     `json:"parentHash"`
+	BlockHash             *common.Hash    `json:"blockHash,omitempty"`
+	Local                 *BlockCandidate `json:"local"`
+	Bid                   *BlockCandidate `json:"bid,omitempty"` // nil if no bid is received
+	Winner                string          `json:"winner"`
+	BlockRewardUplift     *big.Int        `json:"blockRewardUplift,omitempty"`     // bid minus local, set if a bid is received
+	ValidatorRewardUplift *big.Int        `json:"validatorRewardUplift,omitempty"` // bid minus local, set if a bid is received
+	Time                  uint64          `json:"time"`                            // unix time in milliseconds the block is sealed at
+}
@@ -8,12 +8,16 @@ const (
 	MevNotRunningError   = -38003
 	MevBusyError         = -38004
 	MevNotInTurnError    = -38005
+	BidRateLimitedError  = -38006
+	BidTooLateError      = -38007
+	BidSimFailedError    = -38008
 )
 
 var (
-	ErrMevNotRunning = newBidError(errors.New("the validator stop accepting bids for now, try again later"), MevNotRunningError)
-	ErrMevBusy       = newBidError(errors.New("the validator is working on too many bids, try again later"), MevBusyError)
-	ErrMevNotInTurn  = newBidError(errors.New("the validator is not in-turn to propose currently, try again later"), MevNotInTurnError)
+	ErrMevNotRunning  = newBidError(errors.New("the validator stop accepting bids for now, try again later"), MevNotRunningError)
+	ErrMevBusy        = newBidError(errors.New("the validator is working on too many bids, try again later"), MevBusyError)
+	ErrMevNotInTurn   = newBidError(errors.New("the validator is not in-turn to propose currently, try again later"), MevNotInTurnError)
+	ErrBidRateLimited = newBidError(errors.New("the builder sends bids too fast, try again later"), BidRateLimitedError)
 )
 
 // bidError is an API error that encompasses an invalid bid with JSON error
@@ -37,6 +41,10 @@ func NewInvalidPayBidTxError(message string) *bidError {
 	return newBidError(errors.New(message), InvalidPayBidTxError)
 }
 
+func NewBidTooLateError(message string) *bidError {
+	return newBidError(errors.New(message), BidTooLateError)
+}
+
 func newBidError(err error, code int) *bidError {
 	return &bidError{
 		error: err,
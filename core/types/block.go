@@ -19,6 +19,7 @@ package types
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
@@ -636,6 +637,114 @@ func (d *DiffLayer) EncodeRLP(w io.Writer) error {
 	})
 }
 
+// diffAccountJSON is the JSON form of a changed account. The storage root is
+// left out on purpose, it's not reliable in the diff layers of nodes without
+// tries.
+type diffAccountJSON struct {
+	Account  common.Hash     `json:"account"`
+	Address  *common.Address `json:"address,omitempty"`
+	Deleted  bool            `json:"deleted,omitempty"`
+	Nonce    *hexutil.Uint64 `json:"nonce,omitempty"`
+	Balance  *hexutil.U256   `json:"balance,omitempty"`
+	CodeHash *common.Hash    `json:"codeHash,omitempty"`
+}
+
+type diffStorageJSON struct {
+	Account common.Hash                 `json:"account"`
+	Address *common.Address             `json:"address,omitempty"`
+	Slots   map[common.Hash]common.Hash `json:"slots"`
+	Keys    map[common.Hash]common.Hash `json:"keys,omitempty"` // raw keys of the slot hashes
+}
+
+type diffCodeJSON struct {
+	Hash common.Hash   `json:"hash"`
+	Code hexutil.Bytes `json:"code"`
+}
+
+// MarshalJSON marshals the diff layer as JSON, with the account blobs and the
+// storage values decoded. Accounts and storage are keyed by their hashes, the
+// same as in the snapshot. Receipts are not included.
+func (d *DiffLayer) MarshalJSON() ([]byte, error) {
+	return d.MarshalJSONWithPreimages(nil)
+}
+
+// MarshalJSONWithPreimages is like MarshalJSON, but also resolves the addresses of
+// the accounts and the keys of the storage slots with the given preimage lookup.
+// The ones without a preimage, e.g. if the node doesn't record them, are only
+// keyed by their hashes.
+func (d *DiffLayer) MarshalJSONWithPreimages(preimage func(hash common.Hash) []byte) ([]byte, error) {
+	address := func(hash common.Hash) *common.Address {
+		if preimage == nil {
+			return nil
+		}
+		if blob := preimage(hash); len(blob) == common.AddressLength {
+			addr := common.BytesToAddress(blob)
+			return &addr
+		}
+		return nil
+	}
+	var enc struct {
+		BlockHash common.Hash       `json:"blockHash"`
+		Number    hexutil.Uint64    `json:"number"`
+		Codes     []diffCodeJSON    `json:"codes"`
+		Destructs []common.Address  `json:"destructs"`
+		Accounts  []diffAccountJSON `json:"accounts"`
+		Storages  []diffStorageJSON `json:"storages"`
+	}
+	enc.BlockHash = d.BlockHash
+	enc.Number = hexutil.Uint64(d.Number)
+	enc.Codes = make([]diffCodeJSON, 0, len(d.Codes))
+	for _, code := range d.Codes {
+		enc.Codes = append(enc.Codes, diffCodeJSON{Hash: code.Hash, Code: code.Code})
+	}
+	enc.Destructs = make([]common.Address, 0, len(d.Destructs))
+	enc.Destructs = append(enc.Destructs, d.Destructs...)
+	enc.Accounts = make([]diffAccountJSON, 0, len(d.Accounts))
+	for _, account := range d.Accounts {
+		acc := diffAccountJSON{Account: account.Account, Address: address(account.Account), Deleted: len(account.Blob) == 0}
+		if !acc.Deleted {
+			full, err := FullAccount(account.Blob)
+			if err != nil {
+				return nil, fmt.Errorf("invalid account %x: %v", account.Account, err)
+			}
+			nonce, codeHash := hexutil.Uint64(full.Nonce), common.BytesToHash(full.CodeHash)
+			acc.Nonce, acc.Balance, acc.CodeHash = &nonce, (*hexutil.U256)(full.Balance), &codeHash
+		}
+		enc.Accounts = append(enc.Accounts, acc)
+	}
+	enc.Storages = make([]diffStorageJSON, 0, len(d.Storages))
+	for _, storage := range d.Storages {
+		if len(storage.Keys) != len(storage.Vals) {
+			return nil, fmt.Errorf("invalid storage of account %x: %d keys, %d values", storage.Account, len(storage.Keys), len(storage.Vals))
+		}
+		var (
+			slots = make(map[common.Hash]common.Hash, len(storage.Keys))
+			keys  map[common.Hash]common.Hash
+		)
+		for i, key := range storage.Keys {
+			if preimage != nil {
+				if blob := preimage(key); len(blob) == common.HashLength {
+					if keys == nil {
+						keys = make(map[common.Hash]common.Hash)
+					}
+					keys[key] = common.BytesToHash(blob)
+				}
+			}
+			var value common.Hash
+			if len(storage.Vals[i]) != 0 {
+				_, content, _, err := rlp.Split(storage.Vals[i])
+				if err != nil {
+					return nil, fmt.Errorf("invalid slot %x of account %x: %v", key, storage.Account, err)
+				}
+				value = common.BytesToHash(content)
+			}
+			slots[key] = value
+		}
+		enc.Storages = append(enc.Storages, diffStorageJSON{Account: storage.Account, Address: address(storage.Account), Slots: slots, Keys: keys})
+	}
+	return json.Marshal(&enc)
+}
+
 type DiffCode struct {
 	Hash common.Hash
 	Code []byte
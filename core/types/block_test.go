@@ -18,6 +18,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/blocktest"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
 )
 
 // from bcValidBlockTest.json, "SimpleTx"
@@ -318,3 +320,64 @@ func TestRlpDecodeParentHash(t *testing.T) {
 		}
 	}
 }
+
+func TestDiffLayerJSON(t *testing.T) {
+	value, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(common.Hash{31: 0x2a}.Bytes()))
+	diff := &DiffLayer{
+		BlockHash: common.Hash{0x01},
+		Number:    10,
+		Codes:     []DiffCode{{Hash: common.Hash{0x02}, Code: []byte{0x60, 0x00}}},
+		Destructs: []common.Address{{0x03}},
+		Accounts: []DiffAccount{
+			{Account: common.Hash{0x04}, Blob: SlimAccountRLP(StateAccount{Nonce: 1, Balance: uint256.NewInt(100), Root: EmptyRootHash, CodeHash: EmptyCodeHash[:]})},
+			{Account: common.Hash{0x05}},
+		},
+		Storages: []DiffStorage{{Account: common.Hash{0x04}, Keys: []common.Hash{{0x06}, {0x07}}, Vals: [][]byte{value, nil}}},
+	}
+	have, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("failed to marshal diff layer: %v", err)
+	}
+	want := `{"blockHash":"0x0100000000000000000000000000000000000000000000000000000000000000","number":"0xa",` +
+		`"codes":[{"hash":"0x0200000000000000000000000000000000000000000000000000000000000000","code":"0x6000"}],` +
+		`"destructs":["0x0300000000000000000000000000000000000000"],` +
+		`"accounts":[{"account":"0x0400000000000000000000000000000000000000000000000000000000000000","nonce":"0x1","balance":"0x64","codeHash":"0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},` +
+		`{"account":"0x0500000000000000000000000000000000000000000000000000000000000000","deleted":true}],` +
+		`"storages":[{"account":"0x0400000000000000000000000000000000000000000000000000000000000000","slots":{` +
+		`"0x0600000000000000000000000000000000000000000000000000000000000000":"0x000000000000000000000000000000000000000000000000000000000000002a",` +
+		`"0x0700000000000000000000000000000000000000000000000000000000000000":"0x0000000000000000000000000000000000000000000000000000000000000000"}}]}`
+	if string(have) != want {
+		t.Fatalf("wrong JSON:\nhave %s\nwant %s", have, want)
+	}
+
+	// The addresses and the slot keys are resolved by the preimages
+	preimages := map[common.Hash][]byte{
+		{0x04}: common.Address{0x14}.Bytes(),
+		{0x06}: common.Hash{0x16}.Bytes(),
+	}
+	have, err = diff.MarshalJSONWithPreimages(func(hash common.Hash) []byte { return preimages[hash] })
+	if err != nil {
+		t.Fatalf("failed to marshal diff layer: %v", err)
+	}
+	var dec struct {
+		Accounts []struct {
+			Address *common.Address `json:"address"`
+		} `json:"accounts"`
+		Storages []struct {
+			Address *common.Address             `json:"address"`
+			Keys    map[common.Hash]common.Hash `json:"keys"`
+		} `json:"storages"`
+	}
+	if err := json.Unmarshal(have, &dec); err != nil {
+		t.Fatalf("failed to unmarshal diff layer: %v", err)
+	}
+	if dec.Accounts[0].Address == nil || *dec.Accounts[0].Address != (common.Address{0x14}) || dec.Accounts[1].Address != nil {
+		t.Fatalf("wrong account addresses: %s", have)
+	}
+	if dec.Storages[0].Address == nil || *dec.Storages[0].Address != (common.Address{0x14}) {
+		t.Fatalf("wrong storage address: %s", have)
+	}
+	if len(dec.Storages[0].Keys) != 1 || dec.Storages[0].Keys[common.Hash{0x06}] != (common.Hash{0x16}) {
+		t.Fatalf("wrong slot keys: %s", have)
+	}
+}
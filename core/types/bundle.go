@@ -0,0 +1,118 @@
+package types
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SendBundleArgs represents the arguments to submit a bundle.
+type SendBundleArgs struct {
+	// Txs are the signed transactions of the bundle, executed in the given order
+	Txs []hexutil.Bytes `json:"txs"`
+	// MinBlockNumber is the first block the bundle may be included in, 0 means the next block
+	MinBlockNumber uint64 `json:"minBlockNumber,omitempty"`
+	// MaxBlockNumber is the last block the bundle may be included in, 0 means the default range of the pool
+	MaxBlockNumber uint64 `json:"maxBlockNumber,omitempty"`
+	// MinTimestamp and MaxTimestamp bound the timestamp of the block including the bundle
+	MinTimestamp *uint64 `json:"minTimestamp,omitempty"`
+	MaxTimestamp *uint64 `json:"maxTimestamp,omitempty"`
+	// RevertingTxHashes are the transactions allowed to revert without dropping the bundle
+	RevertingTxHashes []common.Hash `json:"revertingTxHashes,omitempty"`
+}
+
+// ToBundle decodes the transactions of the arguments into a bundle.
+func (args *SendBundleArgs) ToBundle() (*Bundle, error) {
+	txs := make(Transactions, 0, len(args.Txs))
+	for _, encoded := range args.Txs {
+		tx := new(Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	bundle := &Bundle{
+		Txs:               txs,
+		MinBlockNumber:    args.MinBlockNumber,
+		MaxBlockNumber:    args.MaxBlockNumber,
+		RevertingTxHashes: args.RevertingTxHashes,
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *args.MaxTimestamp
+	}
+	return bundle, nil
+}
+
+// Bundle is an ordered list of transactions from a searcher, which is either
+// included as a whole at the given position of a block or not included at all.
+type Bundle struct {
+	Txs               Transactions
+	MinBlockNumber    uint64
+	MaxBlockNumber    uint64
+	MinTimestamp      uint64 // 0 means no lower bound
+	MaxTimestamp      uint64 // 0 means no upper bound
+	RevertingTxHashes []common.Hash
+
+	// Price is the gas price offered by the bundle, weighted by the gas limit of
+	// the transactions, used to order the bundles in the pool.
+	Price *big.Int
+
+	// caches
+	hash atomic.Value
+}
+
+// Hash returns the bundle hash, which is the hash of the transaction hashes.
+func (bundle *Bundle) Hash() common.Hash {
+	if hash := bundle.hash.Load(); hash != nil {
+		return hash.(common.Hash)
+	}
+
+	hashes := make([][]byte, 0, len(bundle.Txs))
+	for _, tx := range bundle.Txs {
+		hash := tx.Hash()
+		hashes = append(hashes, hash[:])
+	}
+	h := crypto.Keccak256Hash(hashes...)
+	bundle.hash.Store(h)
+	return h
+}
+
+// AllowReverting returns whether the transaction is allowed to revert.
+func (bundle *Bundle) AllowReverting(txHash common.Hash) bool {
+	for _, hash := range bundle.RevertingTxHashes {
+		if hash == txHash {
+			return true
+		}
+	}
+	return false
+}
+
+// Includable returns whether the bundle may be included in the block with the given
+// number and timestamp.
+func (bundle *Bundle) Includable(blockNumber, blockTimestamp uint64) bool {
+	if blockNumber < bundle.MinBlockNumber || blockNumber > bundle.MaxBlockNumber {
+		return false
+	}
+	if bundle.MinTimestamp != 0 && blockTimestamp < bundle.MinTimestamp {
+		return false
+	}
+	if bundle.MaxTimestamp != 0 && blockTimestamp > bundle.MaxTimestamp {
+		return false
+	}
+	return true
+}
+
+// SimulatedBundle is a bundle executed on top of a block.
+type SimulatedBundle struct {
+	OriginalBundle *Bundle
+
+	BundleGasFees  *big.Int
+	BundleGasPrice *big.Int
+	BundleGasUsed  uint64
+}
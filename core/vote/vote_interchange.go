@@ -0,0 +1,236 @@
+package vote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// InterchangeFormatVersion is the version of the slashing protection interchange format.
+	InterchangeFormatVersion = "1"
+
+	// slashingProtectionFile is the file in the journal dir keeping the highest imported vote.
+	slashingProtectionFile = "slashing-protection.json"
+)
+
+// Interchange is the slashing protection interchange format of the votes, modeled
+// on EIP-3076 with the source and target blocks of fast finality instead of epochs.
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []*InterchangeData  `json:"data"`
+}
+
+// InterchangeMetadata identifies the format and the chain of the interchange.
+type InterchangeMetadata struct {
+	Version     string      `json:"interchange_format_version"`
+	GenesisHash common.Hash `json:"genesis_hash"`
+}
+
+// InterchangeData is the signing history of a single BLS key.
+type InterchangeData struct {
+	PubKey      hexutil.Bytes      `json:"pubkey"`
+	SignedVotes []*InterchangeVote `json:"signed_votes"`
+}
+
+// InterchangeVote is a vote signed by the key.
+type InterchangeVote struct {
+	SourceNumber uint64        `json:"source_number,string"`
+	SourceHash   common.Hash   `json:"source_hash"`
+	TargetNumber uint64        `json:"target_number,string"`
+	TargetHash   common.Hash   `json:"target_hash"`
+	SigningRoot  common.Hash   `json:"signing_root,omitempty"` // Hash of the vote data
+	Signature    hexutil.Bytes `json:"signature,omitempty"`
+}
+
+// slashingProtection is the highest vote imported from another node. Votes below it
+// are refused since the history before it may not be fully kept by the journal.
+type slashingProtection struct {
+	SourceNumber uint64 `json:"source_number"`
+	TargetNumber uint64 `json:"target_number"`
+}
+
+// loadSlashingProtection reads the imported slashing protection from the journal dir,
+// nil is returned if nothing has been imported.
+func loadSlashingProtection(journalPath string) (*slashingProtection, error) {
+	blob, err := os.ReadFile(filepath.Join(journalPath, slashingProtectionFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	protection := new(slashingProtection)
+	if err := json.Unmarshal(blob, protection); err != nil {
+		return nil, err
+	}
+	return protection, nil
+}
+
+// storeSlashingProtection atomically writes the imported slashing protection into the journal dir.
+func storeSlashingProtection(journalPath string, protection *slashingProtection) error {
+	blob, err := json.Marshal(protection)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(journalPath, slashingProtectionFile)
+	if err := os.WriteFile(path+".tmp", blob, 0640); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// ExportInterchange exports the votes kept by the journal in the interchange format.
+func (journal *VoteJournal) ExportInterchange(genesisHash common.Hash) (*Interchange, error) {
+	firstIndex, err := journal.walLog.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	lastIndex, err := journal.walLog.LastIndex()
+	if err != nil {
+		return nil, err
+	}
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{Version: InterchangeFormatVersion, GenesisHash: genesisHash},
+		Data:     []*InterchangeData{},
+	}
+	keys := make(map[types.BLSPublicKey]*InterchangeData)
+	for index := firstIndex; index <= lastIndex && lastIndex > 0; index++ {
+		vote, err := journal.ReadVote(index)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil || vote.Data == nil {
+			continue
+		}
+		data, ok := keys[vote.VoteAddress]
+		if !ok {
+			data = &InterchangeData{PubKey: common.CopyBytes(vote.VoteAddress[:]), SignedVotes: []*InterchangeVote{}}
+			keys[vote.VoteAddress] = data
+			interchange.Data = append(interchange.Data, data)
+		}
+		signed := &InterchangeVote{
+			SourceNumber: vote.Data.SourceNumber,
+			SourceHash:   vote.Data.SourceHash,
+			TargetNumber: vote.Data.TargetNumber,
+			TargetHash:   vote.Data.TargetHash,
+			SigningRoot:  vote.Data.Hash(),
+		}
+		if vote.Signature != (types.BLSSignature{}) {
+			signed.Signature = common.CopyBytes(vote.Signature[:])
+		}
+		data.SignedVotes = append(data.SignedVotes, signed)
+	}
+	return interchange, nil
+}
+
+// ImportInterchange merges the votes signed by pubKey into the journal and raises
+// the slashing protection to the highest imported vote, so that no vote conflicting
+// with the imported history is signed. If pubKey is nil, the interchange must contain
+// the history of a single key. It returns the number of imported votes.
+func (journal *VoteJournal) ImportInterchange(interchange *Interchange, genesisHash common.Hash, pubKey *types.BLSPublicKey) (int, error) {
+	if interchange.Metadata.Version != InterchangeFormatVersion {
+		return 0, fmt.Errorf("unsupported interchange format version %q", interchange.Metadata.Version)
+	}
+	if interchange.Metadata.GenesisHash != genesisHash {
+		return 0, fmt.Errorf("interchange genesis mismatch: have %x, want %x", interchange.Metadata.GenesisHash, genesisHash)
+	}
+	if pubKey == nil && len(interchange.Data) != 1 {
+		return 0, fmt.Errorf("interchange contains %d keys, the key to import must be given", len(interchange.Data))
+	}
+	var votes []*types.VoteEnvelope
+	for _, data := range interchange.Data {
+		if len(data.PubKey) != types.BLSPublicKeyLength {
+			return 0, fmt.Errorf("invalid BLS public key %x", data.PubKey)
+		}
+		var key types.BLSPublicKey
+		copy(key[:], data.PubKey)
+		if pubKey != nil && key != *pubKey {
+			continue
+		}
+		for _, signed := range data.SignedVotes {
+			vote := &types.VoteEnvelope{
+				VoteAddress: key,
+				Data: &types.VoteData{
+					SourceNumber: signed.SourceNumber,
+					SourceHash:   signed.SourceHash,
+					TargetNumber: signed.TargetNumber,
+					TargetHash:   signed.TargetHash,
+				},
+			}
+			if signed.SigningRoot != (common.Hash{}) && signed.SigningRoot != vote.Data.Hash() {
+				return 0, fmt.Errorf("signing root mismatch of vote %d-->%d", signed.SourceNumber, signed.TargetNumber)
+			}
+			if len(signed.Signature) > 0 {
+				if len(signed.Signature) != types.BLSSignatureLength {
+					return 0, fmt.Errorf("invalid signature of vote %d-->%d", signed.SourceNumber, signed.TargetNumber)
+				}
+				copy(vote.Signature[:], signed.Signature)
+			}
+			votes = append(votes, vote)
+		}
+	}
+	if len(votes) == 0 {
+		return 0, nil
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Data.TargetNumber < votes[j].Data.TargetNumber
+	})
+	// Raise the protection first, the journal only keeps the most recent votes.
+	protection, err := loadSlashingProtection(journal.journalPath)
+	if err != nil {
+		return 0, err
+	}
+	if protection == nil {
+		protection = new(slashingProtection)
+	}
+	for _, vote := range votes {
+		if vote.Data.SourceNumber > protection.SourceNumber {
+			protection.SourceNumber = vote.Data.SourceNumber
+		}
+		if vote.Data.TargetNumber > protection.TargetNumber {
+			protection.TargetNumber = vote.Data.TargetNumber
+		}
+	}
+	if err := storeSlashingProtection(journal.journalPath, protection); err != nil {
+		return 0, err
+	}
+	journal.protection.Store(protection)
+
+	if len(votes) > maxSizeOfRecentEntry {
+		votes = votes[len(votes)-maxSizeOfRecentEntry:]
+	}
+	var imported int
+	for _, vote := range votes {
+		if known, ok := journal.voteDataBuffer.Get(vote.Data.TargetNumber); ok {
+			if known.(*types.VoteData).Hash() != vote.Data.Hash() {
+				log.Warn("Imported vote conflicts with the journal", "target", vote.Data.TargetNumber,
+					"imported", vote.Data.TargetHash, "journal", known.(*types.VoteData).TargetHash)
+			}
+			continue
+		}
+		if err := journal.WriteVote(vote); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+// underProtection checks the vote against the slashing protection imported from
+// another node, if any.
+func (journal *VoteJournal) underProtection(sourceNumber, targetNumber uint64) bool {
+	protection := journal.protection.Load()
+	if protection == nil {
+		return true
+	}
+	return targetNumber > protection.TargetNumber && sourceNumber >= protection.SourceNumber
+}
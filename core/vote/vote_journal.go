@@ -1,7 +1,16 @@
 package vote
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/tidwall/wal"
@@ -22,6 +31,8 @@ type VoteJournal struct {
 	walLog *wal.Log
 
 	voteDataBuffer *lru.Cache
+
+	protection atomic.Pointer[slashingProtection] // highest vote imported from another node
 }
 
 var voteJournalErrorCounter = metrics.NewRegisteredCounter("voteJournal/error", nil)
@@ -56,6 +67,12 @@ func NewVoteJournal(filePath string) (*VoteJournal, error) {
 		journalPath: filePath,
 		walLog:      walLog,
 	}
+	protection, err := loadSlashingProtection(filePath)
+	if err != nil {
+		log.Error("Failed to load slashing protection of vote journal", "err", err)
+		return nil, err
+	}
+	voteJournal.protection.Store(protection)
 
 	// Reload all voteData from journal to lru memory everytime node reboot.
 	for index := firstIndex; index <= lastIndex; index++ {
@@ -105,6 +122,11 @@ func (journal *VoteJournal) WriteVote(voteMessage *types.VoteEnvelope) error {
 	return nil
 }
 
+// Close closes the underlying log of the journal.
+func (journal *VoteJournal) Close() error {
+	return journal.walLog.Close()
+}
+
 func (journal *VoteJournal) ReadVote(index uint64) (*types.VoteEnvelope, error) {
 	voteMessage, err := journal.walLog.Read(index)
 	if err != nil && err != wal.ErrNotFound {
@@ -123,3 +145,173 @@ func (journal *VoteJournal) ReadVote(index uint64) (*types.VoteEnvelope, error)
 
 	return vote, nil
 }
+
+// JournalEntry is an entry read back from the segment files of a vote journal.
+type JournalEntry struct {
+	Index   uint64              // index of the entry in the journal
+	Segment string              // segment file containing the entry
+	Vote    *types.VoteEnvelope // decoded vote, nil if the entry is corrupted
+	Err     error               // reason why the entry couldn't be decoded
+}
+
+// JournalReport is the result of scanning the segment files of a vote journal.
+type JournalReport struct {
+	Entries []*JournalEntry
+	Issues  []string // gaps, corrupted segments and leftovers of interrupted truncations
+
+	segments []*journalSegment
+}
+
+// journalSegment is a segment file of the journal along with its raw entries.
+type journalSegment struct {
+	path  string
+	index uint64   // index of the first entry, as encoded in the file name
+	lines [][]byte // raw entries which could be parsed, including the trailing newline
+}
+
+// journalLine is the json format of a wal entry.
+type journalLine struct {
+	Index string `json:"index"`
+	Data  string `json:"data"`
+}
+
+// ScanVoteJournal reads the segment files of the vote journal at path without
+// going through the wal library, so that corrupted journals which can't be opened
+// are inspected as well. The journal must not be in use.
+func ScanVoteJournal(path string) (*JournalReport, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	report := new(JournalReport)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || len(name) < 20 {
+			continue
+		}
+		index, err := strconv.ParseUint(name[:20], 10, 64)
+		if err != nil || index == 0 {
+			continue
+		}
+		if len(name) != 20 {
+			report.Issues = append(report.Issues, fmt.Sprintf("segment %s is left over from an interrupted truncation", name))
+			continue
+		}
+		report.segments = append(report.segments, &journalSegment{path: filepath.Join(path, name), index: index})
+	}
+	var next uint64
+	for _, segment := range report.segments {
+		name := filepath.Base(segment.path)
+		if next != 0 && segment.index != next {
+			report.Issues = append(report.Issues, fmt.Sprintf("gap before segment %s, expected index %d", name, next))
+		}
+		data, err := os.ReadFile(segment.path)
+		if err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("segment %s is unreadable: %v", name, err))
+			next = 0
+			continue
+		}
+		index := segment.index
+		for len(data) > 0 {
+			entry := &JournalEntry{Index: index, Segment: name}
+			report.Entries = append(report.Entries, entry)
+
+			n := bytes.IndexByte(data, '\n')
+			if n == -1 {
+				entry.Err = errors.New("truncated entry")
+				report.Issues = append(report.Issues, fmt.Sprintf("segment %s is corrupted at index %d: truncated entry", name, index))
+				break
+			}
+			line := data[:n+1]
+			data = data[n+1:]
+			if entry.Vote, entry.Err = decodeJournalLine(line, index); entry.Err != nil {
+				report.Issues = append(report.Issues, fmt.Sprintf("segment %s is corrupted at index %d: %v", name, index, entry.Err))
+				break
+			}
+			segment.lines = append(segment.lines, line)
+			index++
+		}
+		next = index
+	}
+	return report, nil
+}
+
+// decodeJournalLine decodes the vote out of a raw wal entry.
+func decodeJournalLine(line []byte, index uint64) (*types.VoteEnvelope, error) {
+	var entry journalLine
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Index != strconv.FormatUint(index, 10) {
+		return nil, fmt.Errorf("unexpected index %s", entry.Index)
+	}
+	var data []byte
+	switch {
+	case strings.HasPrefix(entry.Data, "+"):
+		data = []byte(entry.Data[1:])
+	case strings.HasPrefix(entry.Data, "$"):
+		var err error
+		if data, err = base64.URLEncoding.DecodeString(entry.Data[1:]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("invalid data encoding")
+	}
+	vote := new(types.VoteEnvelope)
+	if err := json.Unmarshal(data, vote); err != nil {
+		return nil, err
+	}
+	if vote.Data == nil {
+		return nil, errors.New("missing vote data")
+	}
+	return vote, nil
+}
+
+// TruncateVoteJournal removes all the entries after index from the vote journal
+// at path and returns the number of removed entries. Corrupted entries after index
+// are removed as well, but the entries up to index must all be intact. The journal
+// must not be in use.
+func TruncateVoteJournal(path string, index uint64) (int, error) {
+	report, err := ScanVoteJournal(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(report.Entries) == 0 {
+		return 0, errors.New("empty vote journal")
+	}
+	if first := report.Entries[0].Index; index < first {
+		return 0, fmt.Errorf("index %d is before the first entry %d", index, first)
+	}
+	var kept int
+	for _, entry := range report.Entries {
+		if entry.Index > index {
+			break
+		}
+		if entry.Err != nil {
+			return 0, fmt.Errorf("entry %d is corrupted, truncate to an earlier index", entry.Index)
+		}
+		kept++
+	}
+	if last := report.Entries[kept-1].Index; last < index {
+		return 0, fmt.Errorf("index %d is beyond the last intact entry %d", index, last)
+	}
+	for i := len(report.segments) - 1; i >= 0; i-- {
+		segment := report.segments[i]
+		if segment.index > index {
+			if err := os.Remove(segment.path); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		// Rewrite the segment containing index, replacing it atomically
+		tmp := segment.path + ".tmp"
+		if err := os.WriteFile(tmp, bytes.Join(segment.lines[:index-segment.index+1], nil), 0640); err != nil {
+			return 0, err
+		}
+		if err := os.Rename(tmp, segment.path); err != nil {
+			return 0, err
+		}
+		break
+	}
+	return len(report.Entries) - kept, nil
+}
@@ -0,0 +1,138 @@
+package vote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestVoteJournalScanAndTruncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voteJournal")
+	journal, err := NewVoteJournal(path)
+	if err != nil {
+		t.Fatalf("failed to create vote journal: %v", err)
+	}
+	for i := uint64(1); i <= 5; i++ {
+		vote := &types.VoteEnvelope{
+			VoteAddress: types.BLSPublicKey{1},
+			Data:        &types.VoteData{SourceNumber: i - 1, TargetNumber: i, TargetHash: common.Hash{byte(i)}},
+		}
+		if err := journal.WriteVote(vote); err != nil {
+			t.Fatalf("failed to write vote: %v", err)
+		}
+	}
+	journal.walLog.Close()
+
+	// Corrupt the tail of the journal, as an interrupted write would do
+	segment := filepath.Join(path, "00000000000000000001")
+	file, err := os.OpenFile(segment, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open segment: %v", err)
+	}
+	file.WriteString(`{"index":"6","da`)
+	file.Close()
+	if _, err := NewVoteJournal(path); err == nil {
+		t.Fatalf("corrupted vote journal opened")
+	}
+
+	report, err := ScanVoteJournal(path)
+	if err != nil {
+		t.Fatalf("failed to scan vote journal: %v", err)
+	}
+	if len(report.Entries) != 6 || len(report.Issues) != 1 {
+		t.Fatalf("unexpected scan result: %d entries, issues %v", len(report.Entries), report.Issues)
+	}
+	if report.Entries[4].Vote.Data.TargetNumber != 5 || report.Entries[5].Err == nil {
+		t.Fatalf("unexpected entries: %v %v", report.Entries[4].Vote, report.Entries[5].Err)
+	}
+
+	if _, err := TruncateVoteJournal(path, 6); err == nil {
+		t.Fatalf("truncated to a corrupted entry")
+	}
+	removed, err := TruncateVoteJournal(path, 3)
+	if err != nil {
+		t.Fatalf("failed to truncate vote journal: %v", err)
+	}
+	if removed != 3 {
+		t.Fatalf("removed entries mismatch: have %d, want 3", removed)
+	}
+	journal, err = NewVoteJournal(path)
+	if err != nil {
+		t.Fatalf("failed to reopen vote journal: %v", err)
+	}
+	defer journal.walLog.Close()
+	if last, _ := journal.walLog.LastIndex(); last != 3 {
+		t.Fatalf("last index mismatch: have %d, want 3", last)
+	}
+	if vote, _ := journal.ReadVote(3); vote == nil || vote.Data.TargetNumber != 3 {
+		t.Fatalf("unexpected vote at index 3: %v", vote)
+	}
+}
+
+func TestVoteJournalInterchange(t *testing.T) {
+	genesis := common.Hash{0xaa}
+	source, err := NewVoteJournal(filepath.Join(t.TempDir(), "source"))
+	if err != nil {
+		t.Fatalf("failed to create vote journal: %v", err)
+	}
+	defer source.walLog.Close()
+	for i := uint64(1); i <= 3; i++ {
+		vote := &types.VoteEnvelope{
+			VoteAddress: types.BLSPublicKey{1},
+			Signature:   types.BLSSignature{byte(i)},
+			Data:        &types.VoteData{SourceNumber: i + 9, TargetNumber: i + 10, TargetHash: common.Hash{byte(i)}},
+		}
+		if err := source.WriteVote(vote); err != nil {
+			t.Fatalf("failed to write vote: %v", err)
+		}
+	}
+	interchange, err := source.ExportInterchange(genesis)
+	if err != nil {
+		t.Fatalf("failed to export interchange: %v", err)
+	}
+	if len(interchange.Data) != 1 || len(interchange.Data[0].SignedVotes) != 3 {
+		t.Fatalf("unexpected interchange: %+v", interchange.Data)
+	}
+
+	path := filepath.Join(t.TempDir(), "target")
+	target, err := NewVoteJournal(path)
+	if err != nil {
+		t.Fatalf("failed to create vote journal: %v", err)
+	}
+	if !target.underProtection(10, 11) {
+		t.Fatalf("vote refused without imported history")
+	}
+	if _, err := target.ImportInterchange(interchange, common.Hash{0xbb}, nil); err == nil {
+		t.Fatalf("imported interchange of another chain")
+	}
+	other := types.BLSPublicKey{2}
+	if n, err := target.ImportInterchange(interchange, genesis, &other); err != nil || n != 0 {
+		t.Fatalf("imported votes of another key: %d %v", n, err)
+	}
+	if n, err := target.ImportInterchange(interchange, genesis, nil); err != nil || n != 3 {
+		t.Fatalf("failed to import interchange: %d %v", n, err)
+	}
+	target.walLog.Close()
+
+	// The imported history must survive restarts
+	target, err = NewVoteJournal(path)
+	if err != nil {
+		t.Fatalf("failed to reopen vote journal: %v", err)
+	}
+	defer target.walLog.Close()
+	if !target.voteDataBuffer.Contains(uint64(13)) {
+		t.Fatalf("imported vote missing from the journal")
+	}
+	if vote, _ := target.ReadVote(3); vote == nil || vote.Signature != (types.BLSSignature{3}) {
+		t.Fatalf("imported vote mismatch: %v", vote)
+	}
+	if target.underProtection(12, 13) || target.underProtection(11, 14) {
+		t.Fatalf("vote conflicting with the imported history allowed")
+	}
+	if !target.underProtection(12, 14) {
+		t.Fatalf("vote above the imported history refused")
+	}
+}
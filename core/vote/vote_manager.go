@@ -39,30 +39,23 @@ type VoteManager struct {
 	syncVoteSub event.Subscription
 
 	pool    *VotePool
-	signer  *VoteSigner
+	signer  Signer
 	journal *VoteJournal
 
 	engine consensus.PoSA
 }
 
-func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath, blsPasswordPath, blsWalletPath string, engine consensus.PoSA) (*VoteManager, error) {
+func NewVoteManager(eth Backend, chain *core.BlockChain, pool *VotePool, journalPath string, signer Signer, engine consensus.PoSA) (*VoteManager, error) {
 	voteManager := &VoteManager{
 		eth:         eth,
 		chain:       chain,
 		chainHeadCh: make(chan core.ChainHeadEvent, chainHeadChanSize),
 		syncVoteCh:  make(chan core.NewVoteEvent, voteBufferForPut),
 		pool:        pool,
+		signer:      signer,
 		engine:      engine,
 	}
 
-	// Create voteSigner.
-	voteSigner, err := NewVoteSigner(blsPasswordPath, blsWalletPath)
-	if err != nil {
-		return nil, err
-	}
-	log.Info("Create voteSigner successfully")
-	voteManager.signer = voteSigner
-
 	// Create voteJournal
 	voteJournal, err := NewVoteJournal(journalPath)
 	if err != nil {
@@ -99,6 +92,7 @@ func (voteManager *VoteManager) loop() {
 	startVote := true
 	blockCountSinceMining := 0
 	var once sync.Once
+	pubKey := voteManager.signer.PublicKey()
 	for {
 		select {
 		case ev := <-dlEventCh:
@@ -142,7 +136,7 @@ func (voteManager *VoteManager) loop() {
 			// Check if cur validator is within the validatorSet at curHead
 			if !voteManager.engine.IsActiveValidatorAt(voteManager.chain, curHead,
 				func(bLSPublicKey *types.BLSPublicKey) bool {
-					return bytes.Equal(voteManager.signer.PubKey[:], bLSPublicKey[:])
+					return bytes.Equal(pubKey[:], bLSPublicKey[:])
 				}) {
 				log.Debug("cur validator is not within the validatorSet at curHead")
 				continue
@@ -152,7 +146,7 @@ func (voteManager *VoteManager) loop() {
 			once.Do(func() {
 				minerInfo := metrics.Get("miner-info")
 				if minerInfo != nil {
-					minerInfo.(metrics.Label).Value()["VoteKey"] = common.Bytes2Hex(voteManager.signer.PubKey[:])
+					minerInfo.(metrics.Label).Value()["VoteKey"] = common.Bytes2Hex(pubKey[:])
 				}
 			})
 
@@ -193,7 +187,7 @@ func (voteManager *VoteManager) loop() {
 			}
 		case event := <-voteManager.syncVoteCh:
 			voteMessage := event.Vote
-			if voteManager.eth.IsMining() || !bytes.Equal(voteManager.signer.PubKey[:], voteMessage.VoteAddress[:]) {
+			if voteManager.eth.IsMining() || !bytes.Equal(pubKey[:], voteMessage.VoteAddress[:]) {
 				continue
 			}
 			if err := voteManager.journal.WriteVote(voteMessage); err != nil {
@@ -217,6 +211,7 @@ func (voteManager *VoteManager) loop() {
 // A validator must not publish two distinct votes for the same height. (Rule 1)
 // A validator must not vote within the span of its other votes . (Rule 2)
 // Validators always vote for their canonical chain’s latest block. (Rule 3)
+// A validator must not vote below the history imported from another node. (Rule 4)
 func (voteManager *VoteManager) UnderRules(header *types.Header) (bool, uint64, common.Hash) {
 	sourceNumber, sourceHash, err := voteManager.engine.GetJustifiedNumberAndHash(voteManager.chain, []*types.Header{header})
 	if err != nil {
@@ -267,6 +262,13 @@ func (voteManager *VoteManager) UnderRules(header *types.Header) (bool, uint64,
 		}
 	}
 
+	// Rule 4: A validator must not vote below the history imported from another node,
+	// since the votes before it may not be kept by the journal.
+	if !voteManager.journal.underProtection(sourceNumber, targetNumber) {
+		log.Debug(fmt.Sprintf("error: cur vote %d-->%d conflicts with the imported slashing protection", sourceNumber, targetNumber))
+		return false, 0, common.Hash{}
+	}
+
 	// Rule 3: Validators always vote for their canonical chain’s latest block.
 	// Since the header subscribed to is the canonical chain, so this rule is satisfied by default.
 	log.Debug("All three rules check passed")
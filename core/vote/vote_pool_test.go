@@ -176,7 +176,11 @@ func testVotePool(t *testing.T, isValidRules bool) {
 	file.Close()
 	os.Remove(journal)
 
-	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, walletPasswordDir, walletDir, mockEngine)
+	voteSigner, err := NewVoteSigner(walletPasswordDir, walletDir)
+	if err != nil {
+		t.Fatalf("failed to create vote signer: %v", err)
+	}
+	voteManager, err := NewVoteManager(newTestBackend(), chain, votePool, journal, voteSigner, mockEngine)
 	if err != nil {
 		t.Fatalf("failed to create vote managers")
 	}
@@ -24,6 +24,17 @@ const (
 
 var votesSigningErrorCounter = metrics.NewRegisteredCounter("votesSigner/error", nil)
 
+// Signer signs the votes of the validator, the slashing rules must have been
+// checked against the vote journal before a vote is passed to it.
+type Signer interface {
+	// PublicKey returns the BLS public key the votes are signed with.
+	PublicKey() types.BLSPublicKey
+
+	// SignVote signs the vote data and fills the vote address and signature.
+	SignVote(vote *types.VoteEnvelope) error
+}
+
+// VoteSigner signs the votes with the first account of a local BLS wallet.
 type VoteSigner struct {
 	km     *keymanager.IKeymanager
 	PubKey [48]byte
@@ -78,6 +89,11 @@ func NewVoteSigner(blsPasswordPath, blsWalletPath string) (*VoteSigner, error) {
 	}, nil
 }
 
+// PublicKey returns the BLS public key the votes are signed with.
+func (signer *VoteSigner) PublicKey() types.BLSPublicKey {
+	return signer.PubKey
+}
+
 func (signer *VoteSigner) SignVote(vote *types.VoteEnvelope) error {
 	// Sign the vote, fetch the first pubKey as validator's bls public key.
 	pubKey := signer.PubKey
@@ -0,0 +1,186 @@
+package vote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	remoteSignerPublicKeysPath = "/api/v1/eth2/publicKeys"
+	remoteSignerSignPath       = "/api/v1/eth2/sign/"
+
+	// remoteSignerVoteType is the signing type of fast finality votes.
+	remoteSignerVoteType = "BSC_VOTE"
+
+	// maxRemoteSignerResponseSize bounds the size of the responses read from the remote signer.
+	maxRemoteSignerResponseSize = 1024 * 1024
+)
+
+// RemoteSigner signs the votes through a remote signing host speaking a
+// Web3Signer compatible HTTP API, so that the BLS key never lives on the node.
+type RemoteSigner struct {
+	url    string
+	pubKey types.BLSPublicKey
+	client *http.Client
+}
+
+// remoteSignRequest is the body of a sign request, the vote data is included so
+// that the remote signer can apply its own slashing protection.
+type remoteSignRequest struct {
+	Type        string          `json:"type"`
+	SigningRoot common.Hash     `json:"signingRoot"`
+	Vote        *types.VoteData `json:"vote"`
+}
+
+// remoteSignResponse is the json body of a sign response.
+type remoteSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// NewRemoteSigner creates a signer forwarding the sign requests to the remote
+// signer at url. If pubKey is empty, the first key served by the remote signer
+// is used, the same as the local wallet does.
+func NewRemoteSigner(url string, pubKey string) (*RemoteSigner, error) {
+	signer := &RemoteSigner{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: voteSignerTimeout},
+	}
+	keys, err := signer.publicKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch remote public keys")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no public key served by the remote signer")
+	}
+	if pubKey == "" {
+		signer.pubKey = keys[0]
+	} else {
+		blob, err := hexutil.Decode(pubKey)
+		if err != nil || len(blob) != types.BLSPublicKeyLength {
+			return nil, fmt.Errorf("invalid BLS public key %s", pubKey)
+		}
+		copy(signer.pubKey[:], blob)
+
+		var served bool
+		for _, key := range keys {
+			served = served || key == signer.pubKey
+		}
+		if !served {
+			return nil, fmt.Errorf("BLS public key %s is not served by the remote signer", pubKey)
+		}
+	}
+	if _, err := bls.PublicKeyFromBytes(signer.pubKey[:]); err != nil {
+		return nil, errors.Wrap(err, "convert public key from bytes to bls failed")
+	}
+	log.Info("Connected to remote BLS signer", "url", signer.url, "pubkey", common.Bytes2Hex(signer.pubKey[:]))
+	return signer, nil
+}
+
+// PublicKey returns the BLS public key the votes are signed with.
+func (signer *RemoteSigner) PublicKey() types.BLSPublicKey {
+	return signer.pubKey
+}
+
+// SignVote forwards the vote to the remote signer and verifies the returned
+// signature before filling it into the vote.
+func (signer *RemoteSigner) SignVote(vote *types.VoteEnvelope) error {
+	voteDataHash := vote.Data.Hash()
+	body, err := json.Marshal(&remoteSignRequest{
+		Type:        remoteSignerVoteType,
+		SigningRoot: voteDataHash,
+		Vote:        vote.Data,
+	})
+	if err != nil {
+		return err
+	}
+	path := remoteSignerSignPath + hexutil.Encode(signer.pubKey[:])
+	resp, err := signer.do(http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+	var res remoteSignResponse
+	if err := json.Unmarshal(resp, &res); err != nil {
+		// Web3Signer answers with the bare hex signature if json is not accepted
+		if res.Signature, err = hexutil.Decode(strings.TrimSpace(string(resp))); err != nil {
+			return errors.Wrap(err, "invalid remote signer response")
+		}
+	}
+	sig, err := bls.SignatureFromBytes(res.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	blsPubKey, err := bls.PublicKeyFromBytes(signer.pubKey[:])
+	if err != nil {
+		return errors.Wrap(err, "convert public key from bytes to bls failed")
+	}
+	if !sig.Verify(blsPubKey, voteDataHash[:]) {
+		return errors.New("remote signer returned an invalid signature")
+	}
+	copy(vote.VoteAddress[:], signer.pubKey[:])
+	copy(vote.Signature[:], sig.Marshal())
+	return nil
+}
+
+// publicKeys retrieves the BLS public keys served by the remote signer.
+func (signer *RemoteSigner) publicKeys() ([]types.BLSPublicKey, error) {
+	resp, err := signer.do(http.MethodGet, remoteSignerPublicKeysPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	var encoded []hexutil.Bytes
+	if err := json.Unmarshal(resp, &encoded); err != nil {
+		return nil, err
+	}
+	keys := make([]types.BLSPublicKey, 0, len(encoded))
+	for _, blob := range encoded {
+		if len(blob) != types.BLSPublicKeyLength {
+			return nil, fmt.Errorf("invalid BLS public key %x", blob)
+		}
+		var key types.BLSPublicKey
+		copy(key[:], blob)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// do sends a request to the remote signer and returns the response body.
+func (signer *RemoteSigner) do(method, path string, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), voteSignerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, signer.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := signer.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	blob, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSignerResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer responded %s: %s", resp.Status, strings.TrimSpace(string(blob)))
+	}
+	return blob, nil
+}
@@ -0,0 +1,82 @@
+package vote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/crypto/bls"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newRemoteSignerStub starts a Web3Signer like stub serving a single BLS key.
+func newRemoteSignerStub(t *testing.T, key bls.SecretKey, tamper bool) *httptest.Server {
+	pubKey := hexutil.Encode(key.PublicKey().Marshal())
+	mux := http.NewServeMux()
+	mux.HandleFunc(remoteSignerPublicKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]string{pubKey})
+	})
+	mux.HandleFunc(remoteSignerSignPath+pubKey, func(w http.ResponseWriter, r *http.Request) {
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Type != remoteSignerVoteType {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if req.Vote.Hash() != req.SigningRoot {
+			http.Error(w, "signing root mismatch", http.StatusPreconditionFailed)
+			return
+		}
+		root := req.SigningRoot
+		if tamper {
+			root = common.Hash{}
+		}
+		fmt.Fprint(w, hexutil.Encode(key.Sign(root[:]).Marshal()))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := bls.RandKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	server := newRemoteSignerStub(t, key, false)
+
+	if _, err := NewRemoteSigner(server.URL, hexutil.Encode(make([]byte, types.BLSPublicKeyLength))); err == nil {
+		t.Fatalf("remote signer created with a key not served")
+	}
+	signer, err := NewRemoteSigner(server.URL, "")
+	if err != nil {
+		t.Fatalf("failed to create remote signer: %v", err)
+	}
+	if pubKey := signer.PublicKey(); string(pubKey[:]) != string(key.PublicKey().Marshal()) {
+		t.Fatalf("public key mismatch")
+	}
+	vote := &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 1, TargetNumber: 2, TargetHash: common.Hash{2}}}
+	if err := signer.SignVote(vote); err != nil {
+		t.Fatalf("failed to sign vote: %v", err)
+	}
+	if err := vote.Verify(); err != nil {
+		t.Fatalf("invalid remote signature: %v", err)
+	}
+
+	// Signatures not matching the vote must be refused
+	signer, err = NewRemoteSigner(newRemoteSignerStub(t, key, true).URL, "")
+	if err != nil {
+		t.Fatalf("failed to create remote signer: %v", err)
+	}
+	vote = &types.VoteEnvelope{Data: &types.VoteData{SourceNumber: 1, TargetNumber: 2}}
+	if err := signer.SignVote(vote); err == nil {
+		t.Fatalf("invalid remote signature accepted")
+	}
+	if vote.Signature != (types.BLSSignature{}) {
+		t.Fatalf("invalid remote signature filled into the vote")
+	}
+}
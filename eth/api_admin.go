@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -162,11 +163,29 @@ func (api *AdminAPI) StopMev() {
 // AddBuilder adds a builder to the bid simulator.
 // url is the endpoint of the builder, for example, "https://mev-builder.amazonaws.com",
 // if validator is equipped with sentry, ignore the url.
+// On a node running as a sentry, the builder is added to the sentry instead, it
+// must be added to the upstream validator as well.
 func (api *AdminAPI) AddBuilder(builder common.Address, url string) error {
 	return api.eth.APIBackend.AddBuilder(builder, url)
 }
 
-// RemoveBuilder removes a builder from the bid simulator.
+// RemoveBuilder removes a builder from the bid simulator, or from the sentry on a
+// node running as a sentry.
 func (api *AdminAPI) RemoveBuilder(builder common.Address) error {
 	return api.eth.APIBackend.RemoveBuilder(builder)
 }
+
+// SuspendBuilder suspends a builder for the given seconds, overriding the builder
+// policy. The bids of the builder are rejected until the suspension ends.
+func (api *AdminAPI) SuspendBuilder(builder common.Address, seconds uint64) error {
+	if seconds == 0 {
+		return errors.New("suspension duration must be positive")
+	}
+	api.eth.APIBackend.SuspendBuilder(builder, time.Duration(seconds)*time.Second)
+	return nil
+}
+
+// ResumeBuilder lifts the penalty of a builder and resets its reputation.
+func (api *AdminAPI) ResumeBuilder(builder common.Address) {
+	api.eth.APIBackend.ResumeBuilder(builder)
+}
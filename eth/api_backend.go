@@ -299,6 +299,10 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
+func (b *EthAPIBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	return b.eth.txPool.AddBundle(bundle)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(txpool.PendingFilter{})
 	var txs types.Transactions
@@ -477,10 +481,16 @@ func (b *EthAPIBackend) StopMev() {
 }
 
 func (b *EthAPIBackend) AddBuilder(builder common.Address, url string) error {
+	if b.eth.mevSentry != nil {
+		return b.eth.mevSentry.AddBuilder(builder, url)
+	}
 	return b.Miner().AddBuilder(builder, url)
 }
 
 func (b *EthAPIBackend) RemoveBuilder(builder common.Address) error {
+	if b.eth.mevSentry != nil {
+		return b.eth.mevSentry.RemoveBuilder(builder)
+	}
 	return b.Miner().RemoveBuilder(builder)
 }
 
@@ -492,6 +502,30 @@ func (b *EthAPIBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	return b.Miner().BestPackedBlockReward(parentHash)
 }
 
+func (b *EthAPIBackend) BidHistory(blockNumber uint64) ([]*types.BidRecord, error) {
+	return b.Miner().BidHistory(blockNumber)
+}
+
+func (b *EthAPIBackend) BuilderReputation(builder common.Address) *types.BuilderReputation {
+	return b.Miner().BuilderReputation(builder)
+}
+
+func (b *EthAPIBackend) BlockDecision(number uint64) *types.BlockDecision {
+	return b.Miner().BlockDecision(number)
+}
+
+func (b *EthAPIBackend) SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error) {
+	return b.Miner().SubscribeBidStatus(builder, auth, ch)
+}
+
+func (b *EthAPIBackend) SuspendBuilder(builder common.Address, duration time.Duration) {
+	b.Miner().SuspendBuilder(builder, duration)
+}
+
+func (b *EthAPIBackend) ResumeBuilder(builder common.Address) {
+	b.Miner().ResumeBuilder(builder)
+}
+
 func (b *EthAPIBackend) MinerInTurn() bool {
 	return b.Miner().InTurn()
 }
@@ -18,6 +18,7 @@ package eth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -359,6 +360,22 @@ func (api *DebugAPI) getModifiedAccounts(startBlock, endBlock *types.Block) ([]c
 	return dirty, nil
 }
 
+// GetDiffLayer returns the state changes made by the given block: the changed
+// accounts, storage slots and codes, plus the destructed accounts. Only the
+// diff layers of recent blocks are kept in memory, older ones are available
+// if the node persists them (--persistdiff).
+//
+// Accounts and storage slots are keyed by their hashes. The addresses and the
+// slot keys are only included if the node records the preimages
+// (--cache.preimages).
+func (api *DebugAPI) GetDiffLayer(blockHash common.Hash) (json.RawMessage, error) {
+	diff := api.eth.blockchain.GetTrustedDiffLayer(blockHash)
+	if diff == nil {
+		return nil, fmt.Errorf("diff layer of block %#x not found", blockHash)
+	}
+	return diff.MarshalJSONWithPreimages(api.eth.blockchain.TrieDB().Preimage)
+}
+
 // GetAccessibleState returns the first number where the node has accessible
 // state on disk. Note this being the post-state of that block and the pre-state
 // of the next block.
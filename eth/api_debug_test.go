@@ -18,17 +18,25 @@ package eth
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 	"golang.org/x/exp/slices"
@@ -225,3 +233,75 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestGetDiffLayer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		to    = common.Address{0xaa}
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+		}
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(testAddr), to, big.NewInt(1000), params.TxGas, gen.BaseFee(), nil), types.HomesteadSigner{}, testKey)
+		gen.AddTx(tx)
+	})
+	cacheConfig := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.Preimages = true
+	chain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewDebugAPI(&Ethereum{blockchain: chain})
+
+	if _, err := api.GetDiffLayer(common.Hash{0x01}); err == nil {
+		t.Fatal("expected error for unknown block")
+	}
+	// The diff layer is cached asynchronously after the import
+	var (
+		blob json.RawMessage
+		err  error
+	)
+	for i := 0; i < 100; i++ {
+		if blob, err = api.GetDiffLayer(blocks[0].Hash()); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to get diff layer: %v", err)
+	}
+	var dec struct {
+		BlockHash common.Hash    `json:"blockHash"`
+		Number    hexutil.Uint64 `json:"number"`
+		Accounts  []struct {
+			Account common.Hash     `json:"account"`
+			Address *common.Address `json:"address"`
+			Balance *hexutil.U256   `json:"balance"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to unmarshal diff layer: %v", err)
+	}
+	if dec.BlockHash != blocks[0].Hash() || dec.Number != 1 {
+		t.Fatalf("wrong block: have %x #%d, want %x #1", dec.BlockHash, dec.Number, blocks[0].Hash())
+	}
+	var found bool
+	for _, account := range dec.Accounts {
+		if account.Account == crypto.Keccak256Hash(to.Bytes()) {
+			found = true
+			if account.Balance == nil || (*uint256.Int)(account.Balance).Uint64() != 1000 {
+				t.Fatalf("wrong balance of recipient: %v", account.Balance)
+			}
+			if account.Address == nil || *account.Address != to {
+				t.Fatalf("wrong address of recipient: %v", account.Address)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("recipient missing from diff layer: %s", blob)
+	}
+}
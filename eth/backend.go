@@ -18,6 +18,7 @@
 package eth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -38,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -47,6 +49,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/protocols/bsc"
+	"github.com/ethereum/go-ethereum/eth/protocols/diff"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/eth/protocols/trust"
@@ -57,6 +60,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/miner/sentry"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
@@ -118,6 +122,8 @@ type Ethereum struct {
 	shutdownTracker *shutdowncheck.ShutdownTracker // Tracks if and when the node has shutdown ungracefully
 
 	votePool *vote.VotePool
+
+	mevSentry *sentry.Sentry // forwards the bids to the upstream validator if running as a sentry
 }
 
 // New creates a new Ethereum object (including the
@@ -200,6 +206,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err = chainDb.SetupFreezerEnv(&ethdb.FreezerEnv{
 		ChainCfg:         chainConfig,
 		BlobExtraReserve: config.BlobExtraReserve,
+		BodyHistory:      config.BodyHistory,
+		ReceiptHistory:   config.ReceiptHistory,
 	}); err != nil {
 		return nil, err
 	}
@@ -233,6 +241,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if config.ParliaSnapshotFile != "" {
+		p, ok := eth.engine.(*parlia.Parlia)
+		if !ok {
+			return nil, errors.New("parlia snapshot file requires the parlia engine")
+		}
+		file, err := parlia.ReadSnapshotFile(stack.ResolvePath(config.ParliaSnapshotFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parlia snapshot file: %v", err)
+		}
+		if _, err := p.ImportSnapshot(file, config.ParliaSnapshotSigner, config.ParliaSnapshotInsecure); err != nil {
+			return nil, fmt.Errorf("failed to import parlia snapshot file: %v", err)
+		}
+	}
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -289,18 +310,30 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.PersistDiff {
 		bcOps = append(bcOps, core.EnablePersistDiff(config.DiffBlock))
 	}
+	if config.DiffSync {
+		bcOps = append(bcOps, core.EnableDiffSync(config.TriesVerifyMode))
+	}
 	if stack.Config().EnableDoubleSignMonitor {
-		bcOps = append(bcOps, core.EnableDoubleSignChecker)
+		bcOps = append(bcOps, core.EnableDoubleSignChecker(stack.Config().DoubleSignMonitorRetention))
 	}
 
 	peers := newPeerSet()
-	bcOps = append(bcOps, core.EnableBlockValidator(chainConfig, eth.engine, config.TriesVerifyMode, peers))
+	bcOps = append(bcOps, core.EnableBlockValidator(chainConfig, eth.engine, config.TriesVerifyMode, config.TriesVerify, peers))
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TransactionHistory, bcOps...)
 	if err != nil {
 		return nil, err
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if submitter := stack.Config().DoubleSignEvidenceSubmitter; submitter != "" {
+		if dsm := eth.blockchain.DoubleSignMonitor(); dsm != nil {
+			dsm.SetSubmitter(eth.evidenceSubmitter(common.HexToAddress(submitter)), eth.evidenceReceipt)
+			log.Info("Enable double sign evidence submission", "submitter", submitter)
+		} else {
+			log.Warn("Double sign evidence submitter is set but double sign monitor is disabled")
+		}
+	}
+
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
 	}
@@ -309,9 +342,24 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.Miner.Mev.BidLogFile != "" {
+		config.Miner.Mev.BidLogFile = stack.ResolvePath(config.Miner.Mev.BidLogFile)
+	}
+	if config.Miner.Mev.BuilderReputationFile != "" {
+		config.Miner.Mev.BuilderReputationFile = stack.ResolvePath(config.Miner.Mev.BuilderReputationFile)
+	}
+	for _, file := range []*string{&config.Miner.Mev.BidServer.JWTSecret, &config.Miner.Mev.BidServer.TLSCertFile,
+		&config.Miner.Mev.BidServer.TLSKeyFile, &config.Miner.Mev.BidServer.ClientCAFile} {
+		if *file != "" {
+			*file = stack.ResolvePath(*file)
+		}
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
-
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	subpools := []txpool.SubPool{legacyPool, blobPool}
+	if config.BundlePool.Enabled {
+		subpools = append(subpools, bundlepool.New(config.BundlePool, eth.blockchain))
+	}
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, subpools)
 	if err != nil {
 		return nil, err
 	}
@@ -330,6 +378,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		DirectBroadcast:        config.DirectBroadcast,
 		DisablePeerTxBroadcast: config.DisablePeerTxBroadcast,
 		PeerSet:                peers,
+		NodeKey:                stack.Config().NodeKey(),
+		DiffSync:               config.DiffSync,
 	}); err != nil {
 		return nil, err
 	}
@@ -353,16 +403,27 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		log.Info("Create votePool successfully")
 		eth.handler.votepool = votePool
 		if stack.Config().EnableMaliciousVoteMonitor {
-			eth.handler.maliciousVoteMonitor = monitor.NewMaliciousVoteMonitor()
+			eth.handler.maliciousVoteMonitor = monitor.NewMaliciousVoteMonitor(chainDb)
 			log.Info("Create MaliciousVoteMonitor successfully")
 		}
 
 		if config.Miner.VoteEnable {
 			conf := stack.Config()
-			blsPasswordPath := stack.ResolvePath(conf.BLSPasswordFile)
-			blsWalletPath := stack.ResolvePath(conf.BLSWalletDir)
+			var voteSigner vote.Signer
+			if conf.BLSRemoteSigner != "" {
+				voteSigner, err = vote.NewRemoteSigner(conf.BLSRemoteSigner, conf.BLSRemoteSignerPubKey)
+			} else {
+				blsPasswordPath := stack.ResolvePath(conf.BLSPasswordFile)
+				blsWalletPath := stack.ResolvePath(conf.BLSWalletDir)
+				voteSigner, err = vote.NewVoteSigner(blsPasswordPath, blsWalletPath)
+			}
+			if err != nil {
+				log.Error("Failed to create voteSigner", "err", err)
+				return nil, err
+			}
+			log.Info("Create voteSigner successfully")
 			voteJournalPath := stack.ResolvePath(conf.VoteJournalDir)
-			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, blsPasswordPath, blsWalletPath, posa); err != nil {
+			if _, err := vote.NewVoteManager(eth, eth.blockchain, votePool, voteJournalPath, voteSigner, posa); err != nil {
 				log.Error("Failed to Initialize voteManager", "err", err)
 				return nil, err
 			}
@@ -395,6 +456,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
+	// Forward the bids to the upstream validator if running as a sentry
+	if config.Miner.Mev.Sentry.Enabled {
+		for i, upstream := range config.Miner.Mev.Sentry.Upstreams {
+			if upstream.JWTSecret != "" {
+				config.Miner.Mev.Sentry.Upstreams[i].JWTSecret = stack.ResolvePath(upstream.JWTSecret)
+			}
+		}
+		eth.mevSentry, err = sentry.New(config.Miner.Mev.Sentry, config.Miner.Mev.Builders, eth.blockchain.Config(),
+			eth.payBidSigner(config.Miner.Mev.Sentry.PayBidAccount))
+		if err != nil {
+			return nil, err
+		}
+		stack.RegisterLifecycle(eth.mevSentry)
+	}
+
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
 
@@ -403,6 +479,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 
+	// Serve the bids on the dedicated endpoint if configured
+	if config.Miner.Mev.BidServer.ListenAddr != "" {
+		bidServer, err := newBidServer(config.Miner.Mev.BidServer, eth.mevService())
+		if err != nil {
+			return nil, err
+		}
+		stack.RegisterLifecycle(bidServer)
+	}
+
 	// Successful startup; push a marker and check previous unclean shutdowns.
 	eth.shutdownTracker.MarkStartup()
 
@@ -431,9 +516,34 @@ func makeExtraData(extra []byte) []byte {
 func (s *Ethereum) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.APIBackend)
 
+	// The sentry serves the mev namespace on behalf of the upstream validator
+	if s.mevSentry != nil {
+		for i, api := range apis {
+			if _, ok := api.Service.(*ethapi.MevAPI); ok {
+				apis[i].Service = s.mevService()
+			}
+		}
+	}
+
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// The bundles are only accepted if the bundle pool is enabled
+	if s.config.BundlePool.Enabled {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Service:   ethapi.NewBundleAPI(s.APIBackend),
+		})
+	}
+
+	// Expose the malicious vote evidence next to the other parlia APIs
+	if s.handler.maliciousVoteMonitor != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "parlia",
+			Service:   monitor.NewMaliciousVoteAPI(s.handler.maliciousVoteMonitor),
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -457,10 +567,55 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
+		}, {
+			Namespace: "monitor",
+			Service:   monitor.NewAPI(s.blockchain.DoubleSignMonitor()),
 		},
 	}...)
 }
 
+// mevService returns the service of the mev namespace, which forwards the bids to
+// the upstream validator if running as a sentry.
+func (s *Ethereum) mevService() interface{} {
+	if s.mevSentry != nil {
+		return sentry.NewAPI(s.mevSentry)
+	}
+	return ethapi.NewMevAPI(s.APIBackend)
+}
+
+// payBidSigner returns a function signing the payment of the builder fees by the
+// given unlocked local account.
+func (s *Ethereum) payBidSigner(account common.Address) sentry.SignTxFn {
+	return func(tx *types.Transaction) (*types.Transaction, error) {
+		wallet, err := s.accountManager.Find(accounts.Account{Address: account})
+		if err != nil {
+			return nil, err
+		}
+		return wallet.SignTx(accounts.Account{Address: account}, tx, s.blockchain.Config().ChainID)
+	}
+}
+
+// evidenceSubmitter returns a function sending slashing evidence to the system
+// contracts, the transactions are signed by the given unlocked local account.
+func (s *Ethereum) evidenceSubmitter(sender common.Address) monitor.EvidenceSubmitFn {
+	txAPI := ethapi.NewTransactionAPI(s.APIBackend, new(ethapi.AddrLocker))
+	return func(to common.Address, data []byte) (common.Hash, error) {
+		input := hexutil.Bytes(data)
+		return txAPI.SendTransaction(context.Background(), ethapi.TransactionArgs{
+			From:  &sender,
+			To:    &to,
+			Input: &input,
+		})
+	}
+}
+
+// evidenceReceipt returns the receipt of a sent evidence transaction, nil if it's
+// not included in the canonical chain yet.
+func (s *Ethereum) evidenceReceipt(txHash common.Hash) *types.Receipt {
+	receipt, _, _, _ := rawdb.ReadReceipt(s.chainDb, txHash, s.blockchain.Config())
+	return receipt
+}
+
 func (s *Ethereum) ResetWithGenesisBlock(gb *types.Block) {
 	s.blockchain.ResetWithGenesisBlock(gb)
 }
@@ -644,6 +799,9 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	if s.config.EnableTrustProtocol {
 		protos = append(protos, trust.MakeProtocols((*trustHandler)(s.handler), s.snapDialCandidates)...)
 	}
+	if s.config.EnableDiffProtocol || s.config.DiffSync {
+		protos = append(protos, diff.MakeProtocols((*diffHandler)(s.handler), s.snapDialCandidates)...)
+	}
 	protos = append(protos, bsc.MakeProtocols((*bscHandler)(s.handler), s.bscDialCandidates)...)
 
 	return protos
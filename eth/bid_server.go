@@ -0,0 +1,120 @@
+package eth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// bidServer is the dedicated endpoint the builders send the bids to. It serves the
+// mev namespace only, authenticating the builders by JWT, mutual TLS or both, so
+// that the bid intake is isolated from the public RPC endpoints.
+type bidServer struct {
+	config miner.BidServerConfig
+
+	rpc      *rpc.Server
+	handler  http.Handler
+	tls      *tls.Config
+	server   *http.Server
+	listener net.Listener
+}
+
+// newBidServer creates the bid server, loading the JWT secret and the certificates.
+func newBidServer(config miner.BidServerConfig, service interface{}) (*bidServer, error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("mev", service); err != nil {
+		return nil, err
+	}
+
+	var secret []byte
+	if config.JWTSecret != "" {
+		data, err := os.ReadFile(config.JWTSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bid server JWT secret: %v", err)
+		}
+		if secret = common.FromHex(strings.TrimSpace(string(data))); len(secret) != 32 {
+			return nil, errors.New("invalid bid server JWT secret")
+		}
+	}
+
+	var tlsConfig *tls.Config
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bid server certificate: %v", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+		if config.ClientCAFile != "" {
+			pem, err := os.ReadFile(config.ClientCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read bid server client CA: %v", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("invalid bid server client CA")
+			}
+			tlsConfig.ClientCAs = pool
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if config.ClientCAFile != "" {
+		return nil, errors.New("mutual TLS of bid server requires a server certificate")
+	}
+	if secret == nil && tlsConfig == nil {
+		log.Warn("Bid server enabled without authentication", "addr", config.ListenAddr)
+	}
+
+	return &bidServer{
+		config:  config,
+		rpc:     srv,
+		handler: node.NewHTTPHandlerStack(srv, nil, []string{"*"}, secret),
+		tls:     tlsConfig,
+	}, nil
+}
+
+// Start implements node.Lifecycle, starting to serve the bids.
+func (s *bidServer) Start() error {
+	listener, err := net.Listen("tcp", s.config.ListenAddr)
+	if err != nil {
+		return err
+	}
+	if s.tls != nil {
+		listener = tls.NewListener(listener, s.tls)
+	}
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: rpc.DefaultHTTPTimeouts.ReadHeaderTimeout,
+		ReadTimeout:       rpc.DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout:      rpc.DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:       rpc.DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go s.server.Serve(listener)
+
+	log.Info("Bid server started", "endpoint", listener.Addr(), "tls", s.tls != nil,
+		"mtls", s.tls != nil && s.tls.ClientCAs != nil, "jwt", s.config.JWTSecret != "")
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the bid server.
+func (s *bidServer) Stop() error {
+	if s.server != nil {
+		s.server.Close()
+	}
+	s.rpc.Stop()
+	log.Info("Bid server stopped", "endpoint", s.config.ListenAddr)
+	return nil
+}
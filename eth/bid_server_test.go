@@ -0,0 +1,64 @@
+package eth
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+func TestBidServerJWT(t *testing.T) {
+	secret := make([]byte, 32)
+	secret[0] = 1
+	path := filepath.Join(t.TempDir(), "jwtsecret")
+	if err := os.WriteFile(path, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatalf("failed to write jwt secret: %v", err)
+	}
+	server, err := newBidServer(miner.BidServerConfig{ListenAddr: "127.0.0.1:0", JWTSecret: path}, ethapi.NewMevAPI(nil))
+	if err != nil {
+		t.Fatalf("failed to create bid server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start bid server: %v", err)
+	}
+	defer server.Stop()
+
+	call := func(token string) int {
+		body := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"mev_unknown","params":[]}`)
+		req, _ := http.NewRequest(http.MethodPost, "http://"+server.listener.Addr().String(), body)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to call bid server: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := call(""); code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated request: have status %d, want %d", code, http.StatusUnauthorized)
+	}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iat": &jwt.NumericDate{Time: time.Now()},
+	}).SignedString(secret)
+	if code := call(token); code != http.StatusOK {
+		t.Fatalf("authenticated request: have status %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestBidServerMutualTLSRequiresCertificate(t *testing.T) {
+	_, err := newBidServer(miner.BidServerConfig{ListenAddr: "127.0.0.1:0", ClientCAFile: "ca.pem"}, ethapi.NewMevAPI(nil))
+	if err == nil {
+		t.Fatalf("mutual TLS without server certificate accepted")
+	}
+}
@@ -58,6 +58,10 @@ var (
 	fsHeaderSafetyNet = 2048            // Number of headers to discard in case a chain violation is detected
 	fsHeaderContCheck = 3 * time.Second // Time interval to check for header continuations during state download
 	fsMinFullBlocks   = 64              // Number of blocks to retrieve fully even in snap sync
+
+	diffFetchTick         = 10 * time.Millisecond // Interval to check whether to fetch the diff layers of the next blocks
+	diffFetchLimit uint64 = 256                   // Number of blocks ahead of the chain head to fetch the diff layers of
+	diffFetchBatch        = 16                    // Number of diff layers to fetch per request
 )
 
 var (
@@ -213,8 +217,60 @@ type BlockChain interface {
 
 type DownloadOption func(downloader *Downloader) *Downloader
 
+// DiffFetcher requests the diff layers of the blocks from the peer delivered them,
+// or from any other peer serving the diff layers.
+type DiffFetcher func(pid string, hashes []common.Hash) error
+
+// EnableDiffFetchOp fetches the diff layers of the downloaded blocks ahead of their
+// import in full sync, so that they can be imported without being executed.
+func EnableDiffFetchOp(fetch DiffFetcher) DownloadOption {
+	return func(dl *Downloader) *Downloader {
+		dl.chainInsertHook = func(results []*fetchResult, stop chan struct{}) {
+			if dl.getMode() == FullSync && stop != nil {
+				go dl.fetchDiffLayers(fetch, results, stop)
+			}
+		}
+		return dl
+	}
+}
+
+// fetchDiffLayers requests the diff layers of the blocks to import in batches,
+// keeping at most diffFetchLimit blocks ahead of the chain head.
+func (d *Downloader) fetchDiffLayers(fetch DiffFetcher, results []*fetchResult, stop chan struct{}) {
+	ticker := time.NewTicker(diffFetchTick)
+	defer ticker.Stop()
+
+	for len(results) > 0 {
+		// The blocks without transactions have no diff layer
+		if len(results[0].Transactions) == 0 {
+			results = results[1:]
+			continue
+		}
+		for d.blockchain.CurrentBlock().Number.Uint64()+diffFetchLimit < results[0].Header.Number.Uint64() {
+			select {
+			case <-stop:
+				return
+			case <-d.quitCh:
+				return
+			case <-ticker.C:
+			}
+		}
+		pid := results[0].pid
+		hashes := make([]common.Hash, 0, diffFetchBatch)
+		for len(results) > 0 && len(hashes) < diffFetchBatch && results[0].pid == pid {
+			if len(results[0].Transactions) > 0 {
+				hashes = append(hashes, results[0].Header.Hash())
+			}
+			results = results[1:]
+		}
+		if err := fetch(pid, hashes); err != nil {
+			log.Debug("Failed to fetch diff layers", "peer", pid, "count", len(hashes), "err", err)
+		}
+	}
+}
+
 // New creates a new downloader to fetch hashes and blocks from remote peers.
-func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn, _ func()) *Downloader {
+func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn, _ func(), options ...DownloadOption) *Downloader {
 	if lightchain == nil {
 		lightchain = chain
 	}
@@ -232,6 +288,9 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, lightchai
 		stateSyncStart: make(chan *stateSync),
 		syncStartBlock: chain.CurrentSnapBlock().Number.Uint64(),
 	}
+	for _, option := range options {
+		dl = option(dl)
+	}
 
 	go dl.stateFetcher()
 	return dl
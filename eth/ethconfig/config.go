@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/parlia"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
@@ -61,12 +62,14 @@ var Defaults = Config{
 	TrieTimeout:        60 * time.Minute,
 	TriesInMemory:      128,
 	TriesVerifyMode:    core.LocalVerify,
+	TriesVerify:        core.DefaultRemoteVerifyConfig,
 	SnapshotCache:      102,
 	DiffBlock:          uint64(86400),
 	FilterLogCacheSize: 32,
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	BundlePool:         bundlepool.DefaultConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -107,6 +110,8 @@ type Config struct {
 	DirectBroadcast     bool
 	DisableSnapProtocol bool // Whether disable snap protocol
 	EnableTrustProtocol bool // Whether enable trust protocol
+	EnableDiffProtocol  bool // Whether enable diff protocol to serve the diff layers
+	DiffSync            bool // Whether to import the blocks by applying the diff layers fetched over diff protocol
 	PipeCommit          bool
 	RangeLimit          bool
 
@@ -114,6 +119,8 @@ type Config struct {
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	BodyHistory        uint64 `toml:",omitempty"` // The maximum number of blocks from head whose bodies are kept in the ancient store, 0 keeps all.
+	ReceiptHistory     uint64 `toml:",omitempty"` // The maximum number of blocks from head whose receipts are kept in the ancient store, 0 keeps all.
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
 	// consistent with persistent state.
@@ -157,6 +164,7 @@ type Config struct {
 	SnapshotCache   int
 	TriesInMemory   uint64
 	TriesVerifyMode core.VerifyMode
+	TriesVerify     core.RemoteVerifyConfig // Quorum of the verify peers in full and insecure verify mode
 	Preimages       bool
 
 	// This is the number of blocks for which logs will be cached in the filter system.
@@ -166,8 +174,9 @@ type Config struct {
 	Miner miner.Config
 
 	// Transaction pool options
-	TxPool   legacypool.Config
-	BlobPool blobpool.Config
+	TxPool     legacypool.Config
+	BlobPool   blobpool.Config
+	BundlePool bundlepool.Config
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -202,6 +211,15 @@ type Config struct {
 
 	// blob setting
 	BlobExtraReserve uint64
+
+	// ParliaSnapshotFile is a parlia snapshot file used to seed the snapshot cache at startup.
+	ParliaSnapshotFile string `toml:",omitempty"`
+
+	// ParliaSnapshotSigner is the account which must have signed ParliaSnapshotFile.
+	ParliaSnapshotSigner *common.Address `toml:",omitempty"`
+
+	// ParliaSnapshotInsecure accepts ParliaSnapshotFile without ParliaSnapshotSigner.
+	ParliaSnapshotInsecure bool `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
@@ -30,11 +31,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DirectBroadcast         bool
 		DisableSnapProtocol     bool
 		EnableTrustProtocol     bool
+		EnableDiffProtocol      bool
+		DiffSync                bool
 		PipeCommit              bool
 		RangeLimit              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
 		TransactionHistory      uint64                 `toml:",omitempty"`
 		StateHistory            uint64                 `toml:",omitempty"`
+		BodyHistory             uint64                 `toml:",omitempty"`
+		ReceiptHistory          uint64                 `toml:",omitempty"`
 		StateScheme             string                 `toml:",omitempty"`
 		PathSyncFlush           bool                   `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
@@ -58,21 +63,26 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapshotCache           int
 		TriesInMemory           uint64
 		TriesVerifyMode         core.VerifyMode
+		TriesVerify             core.RemoteVerifyConfig
 		Preimages               bool
 		FilterLogCacheSize      int
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		BundlePool              bundlepool.Config
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
 		RPCTxFeeCap             float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
-		OverrideFeynman         *uint64 `toml:",omitempty"`
-		OverrideFeynmanFix      *uint64 `toml:",omitempty"`
+		OverrideCancun          *uint64         `toml:",omitempty"`
+		OverrideVerkle          *uint64         `toml:",omitempty"`
+		OverrideFeynman         *uint64         `toml:",omitempty"`
+		OverrideFeynmanFix      *uint64         `toml:",omitempty"`
+		ParliaSnapshotFile      string          `toml:",omitempty"`
+		ParliaSnapshotSigner    *common.Address `toml:",omitempty"`
+		ParliaSnapshotInsecure  bool            `toml:",omitempty"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -88,11 +98,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DirectBroadcast = c.DirectBroadcast
 	enc.DisableSnapProtocol = c.DisableSnapProtocol
 	enc.EnableTrustProtocol = c.EnableTrustProtocol
+	enc.EnableDiffProtocol = c.EnableDiffProtocol
+	enc.DiffSync = c.DiffSync
 	enc.PipeCommit = c.PipeCommit
 	enc.RangeLimit = c.RangeLimit
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
 	enc.StateHistory = c.StateHistory
+	enc.BodyHistory = c.BodyHistory
+	enc.ReceiptHistory = c.ReceiptHistory
 	enc.StateScheme = c.StateScheme
 	enc.PathSyncFlush = c.PathSyncFlush
 	enc.RequiredBlocks = c.RequiredBlocks
@@ -116,11 +130,13 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapshotCache = c.SnapshotCache
 	enc.TriesInMemory = c.TriesInMemory
 	enc.TriesVerifyMode = c.TriesVerifyMode
+	enc.TriesVerify = c.TriesVerify
 	enc.Preimages = c.Preimages
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.BundlePool = c.BundlePool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
@@ -131,6 +147,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.OverrideVerkle = c.OverrideVerkle
 	enc.OverrideFeynman = c.OverrideFeynman
 	enc.OverrideFeynmanFix = c.OverrideFeynmanFix
+	enc.ParliaSnapshotFile = c.ParliaSnapshotFile
+	enc.ParliaSnapshotSigner = c.ParliaSnapshotSigner
+	enc.ParliaSnapshotInsecure = c.ParliaSnapshotInsecure
 	return &enc, nil
 }
 
@@ -150,11 +169,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DirectBroadcast         *bool
 		DisableSnapProtocol     *bool
 		EnableTrustProtocol     *bool
+		EnableDiffProtocol      *bool
+		DiffSync                *bool
 		PipeCommit              *bool
 		RangeLimit              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
 		TransactionHistory      *uint64                `toml:",omitempty"`
 		StateHistory            *uint64                `toml:",omitempty"`
+		BodyHistory             *uint64                `toml:",omitempty"`
+		ReceiptHistory          *uint64                `toml:",omitempty"`
 		StateScheme             *string                `toml:",omitempty"`
 		PathSyncFlush           *bool                  `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
@@ -178,21 +201,26 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapshotCache           *int
 		TriesInMemory           *uint64
 		TriesVerifyMode         *core.VerifyMode
+		TriesVerify             *core.RemoteVerifyConfig
 		Preimages               *bool
 		FilterLogCacheSize      *int
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		BundlePool              *bundlepool.Config
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
 		RPCTxFeeCap             *float64
-		OverrideCancun          *uint64 `toml:",omitempty"`
-		OverrideVerkle          *uint64 `toml:",omitempty"`
-		OverrideFeynman         *uint64 `toml:",omitempty"`
-		OverrideFeynmanFix      *uint64 `toml:",omitempty"`
+		OverrideCancun          *uint64         `toml:",omitempty"`
+		OverrideVerkle          *uint64         `toml:",omitempty"`
+		OverrideFeynman         *uint64         `toml:",omitempty"`
+		OverrideFeynmanFix      *uint64         `toml:",omitempty"`
+		ParliaSnapshotFile      *string         `toml:",omitempty"`
+		ParliaSnapshotSigner    *common.Address `toml:",omitempty"`
+		ParliaSnapshotInsecure  *bool           `toml:",omitempty"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -237,6 +265,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnableTrustProtocol != nil {
 		c.EnableTrustProtocol = *dec.EnableTrustProtocol
 	}
+	if dec.EnableDiffProtocol != nil {
+		c.EnableDiffProtocol = *dec.EnableDiffProtocol
+	}
+	if dec.DiffSync != nil {
+		c.DiffSync = *dec.DiffSync
+	}
 	if dec.PipeCommit != nil {
 		c.PipeCommit = *dec.PipeCommit
 	}
@@ -252,6 +286,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.BodyHistory != nil {
+		c.BodyHistory = *dec.BodyHistory
+	}
+	if dec.ReceiptHistory != nil {
+		c.ReceiptHistory = *dec.ReceiptHistory
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -321,6 +361,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TriesVerifyMode != nil {
 		c.TriesVerifyMode = *dec.TriesVerifyMode
 	}
+	if dec.TriesVerify != nil {
+		c.TriesVerify = *dec.TriesVerify
+	}
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
@@ -336,6 +379,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.BundlePool != nil {
+		c.BundlePool = *dec.BundlePool
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -366,5 +412,14 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideFeynmanFix != nil {
 		c.OverrideFeynmanFix = dec.OverrideFeynmanFix
 	}
+	if dec.ParliaSnapshotFile != nil {
+		c.ParliaSnapshotFile = *dec.ParliaSnapshotFile
+	}
+	if dec.ParliaSnapshotSigner != nil {
+		c.ParliaSnapshotSigner = dec.ParliaSnapshotSigner
+	}
+	if dec.ParliaSnapshotInsecure != nil {
+		c.ParliaSnapshotInsecure = *dec.ParliaSnapshotInsecure
+	}
 	return nil
 }
@@ -20,6 +20,7 @@ package fetcher
 import (
 	"errors"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -46,6 +47,8 @@ const (
 	maxQueueDist = 32  // Maximum allowed distance from the chain head to queue
 	hashLimit    = 256 // Maximum number of unique blocks or headers a peer may have announced
 	blockLimit   = 64  // Maximum number of unique blocks a peer may have delivered
+	staleLimit   = 4   // Maximum number of stale headers of a peer waiting for verification
+	maxStaleWait = 64  // Maximum number of stale headers waiting for verification
 )
 
 var (
@@ -66,6 +69,9 @@ var (
 	headerFilterOutMeter = metrics.NewRegisteredMeter("eth/fetcher/block/filter/headers/out", nil)
 	bodyFilterInMeter    = metrics.NewRegisteredMeter("eth/fetcher/block/filter/bodies/in", nil)
 	bodyFilterOutMeter   = metrics.NewRegisteredMeter("eth/fetcher/block/filter/bodies/out", nil)
+
+	staleHeaderInMeter   = metrics.NewRegisteredMeter("eth/fetcher/block/stale/in", nil)
+	staleHeaderDropMeter = metrics.NewRegisteredMeter("eth/fetcher/block/stale/drop", nil)
 )
 
 var errTerminated = errors.New("terminated")
@@ -85,6 +91,10 @@ type bodyRequesterFn func([]common.Hash, chan *eth.Response) (*eth.Request, erro
 // headerVerifierFn is a callback type to verify a block's header for fast propagation.
 type headerVerifierFn func(header *types.Header) error
 
+// headerObserverFn is a callback type to observe the headers that passed verification,
+// including the ones which end up on a side chain or are never imported.
+type headerObserverFn func(header *types.Header)
+
 // blockBroadcasterFn is a callback type for broadcasting a block to connected peers.
 type blockBroadcasterFn func(block *types.Block, propagate bool)
 
@@ -142,6 +152,13 @@ type blockOrHeaderInject struct {
 	block  *types.Block  // Used for normal mode fetcher which imports full block.
 }
 
+// staleHeader represents a header discarded for being too old to import, which
+// is scheduled for verification and observation.
+type staleHeader struct {
+	origin string
+	header *types.Header
+}
+
 // number returns the block number of the injected object.
 func (inject *blockOrHeaderInject) number() uint64 {
 	if inject.header != nil {
@@ -187,10 +204,17 @@ type BlockFetcher struct {
 	queues map[string]int                            // Per peer block counts to prevent memory exhaustion
 	queued map[common.Hash]*blockOrHeaderInject      // Set of already queued blocks (to dedup imports)
 
+	// Stale header states
+	stale       chan *staleHeader        // Stale headers waiting for verification, bounded by maxStaleWait
+	staleLock   sync.Mutex               // Protects the stale header counters below
+	staleCounts map[string]int           // Per peer stale header counts to prevent verification exhaustion
+	staleHashes map[common.Hash]struct{} // Set of stale headers waiting for verification (to dedup)
+
 	// Callbacks
 	getHeader            HeaderRetrievalFn      // Retrieves a header from the local chain
 	getBlock             blockRetrievalFn       // Retrieves a block from the local chain
 	verifyHeader         headerVerifierFn       // Checks if a block's headers have a valid proof of work
+	observeHeader        headerObserverFn       // Observes every verified header (optional)
 	broadcastBlock       blockBroadcasterFn     // Broadcasts a block to connected peers
 	chainHeight          chainHeightFn          // Retrieves the current chain's height
 	chainFinalizedHeight chainFinalizedHeightFn // Retrieves the current chain's finalized height
@@ -207,7 +231,7 @@ type BlockFetcher struct {
 }
 
 // NewBlockFetcher creates a block fetcher to retrieve blocks based on hash announcements.
-func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetrievalFn, verifyHeader headerVerifierFn,
+func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetrievalFn, verifyHeader headerVerifierFn, observeHeader headerObserverFn,
 	broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, chainFinalizedHeight chainFinalizedHeightFn,
 	insertHeaders headersInsertFn, insertChain chainInsertFn, dropPeer peerDropFn) *BlockFetcher {
 	return &BlockFetcher{
@@ -227,9 +251,13 @@ func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetr
 		queue:                prque.New[int64, *blockOrHeaderInject](nil),
 		queues:               make(map[string]int),
 		queued:               make(map[common.Hash]*blockOrHeaderInject),
+		stale:                make(chan *staleHeader, maxStaleWait),
+		staleCounts:          make(map[string]int),
+		staleHashes:          make(map[common.Hash]struct{}),
 		getHeader:            getHeader,
 		getBlock:             getBlock,
 		verifyHeader:         verifyHeader,
+		observeHeader:        observeHeader,
 		broadcastBlock:       broadcastBlock,
 		chainHeight:          chainHeight,
 		chainFinalizedHeight: chainFinalizedHeight,
@@ -243,6 +271,7 @@ func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetr
 // hash notifications and block fetches until termination requested.
 func (f *BlockFetcher) Start() {
 	go f.loop()
+	go f.staleLoop()
 }
 
 // Stop terminates the announcement based synchroniser, canceling all pending
@@ -809,6 +838,7 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 	if header != nil {
 		hash, number = header.Hash(), header.Number.Uint64()
 	} else {
+		header = block.Header()
 		hash, number = block.Hash(), block.NumberU64()
 	}
 	// Ensure the peer isn't DOSing us
@@ -824,6 +854,9 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 		log.Debug("Discarded delivered header or block, too far away", "peer", peer, "number", number, "hash", hash, "distance", dist)
 		blockBroadcastDropMeter.Mark(1)
 		f.forgetHash(hash)
+		if dist < 0 && dist >= -maxQueueDist {
+			f.observeStale(peer, header)
+		}
 		return
 	}
 	// Discard any block that is below the current finalized height
@@ -832,15 +865,16 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 		log.Debug("Discarded delivered header or block, below or equal to finalized", "peer", peer, "number", number, "hash", hash, "finalized", finalizedHeight)
 		blockBroadcastDropMeter.Mark(1)
 		f.forgetHash(hash)
+		f.observeStale(peer, header)
 		return
 	}
 	// Schedule the block for future importing
 	if _, ok := f.queued[hash]; !ok {
 		op := &blockOrHeaderInject{origin: peer}
-		if header != nil {
-			op.header = header
-		} else {
+		if block != nil {
 			op.block = block
+		} else {
+			op.header = header
 		}
 		f.queues[peer] = count
 		f.queued[hash] = op
@@ -852,6 +886,84 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 	}
 }
 
+// observe passes a verified header to the header observer, if any.
+func (f *BlockFetcher) observe(header *types.Header) {
+	if f.observeHeader != nil {
+		f.observeHeader(header)
+	}
+}
+
+// observeStale schedules a header which is discarded for being too old to import
+// for verification, so that headers of competing forks arriving late are still
+// observed. Headers already waiting are skipped, and the ones exceeding the
+// allowance of the peer or the capacity of the verification queue are dropped.
+func (f *BlockFetcher) observeStale(peer string, header *types.Header) {
+	if f.observeHeader == nil {
+		return
+	}
+	hash := header.Hash()
+
+	f.staleLock.Lock()
+	defer f.staleLock.Unlock()
+
+	if _, ok := f.staleHashes[hash]; ok {
+		return
+	}
+	if f.staleCounts[peer] >= staleLimit {
+		log.Debug("Discarded stale header, exceeded allowance", "peer", peer, "number", header.Number, "hash", hash, "limit", staleLimit)
+		staleHeaderDropMeter.Mark(1)
+		return
+	}
+	select {
+	case f.stale <- &staleHeader{origin: peer, header: header}:
+		staleHeaderInMeter.Mark(1)
+		f.staleCounts[peer]++
+		f.staleHashes[hash] = struct{}{}
+	default:
+		log.Debug("Discarded stale header, verification queue full", "peer", peer, "number", header.Number, "hash", hash)
+		staleHeaderDropMeter.Mark(1)
+	}
+}
+
+// staleLoop verifies the scheduled stale headers one by one and passes them to
+// the header observer.
+func (f *BlockFetcher) staleLoop() {
+	for {
+		select {
+		case op := <-f.stale:
+			f.verifyStale(op.header)
+
+			f.staleLock.Lock()
+			if f.staleCounts[op.origin]--; f.staleCounts[op.origin] <= 0 {
+				delete(f.staleCounts, op.origin)
+			}
+			delete(f.staleHashes, op.header.Hash())
+			f.staleLock.Unlock()
+
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// verifyStale verifies a stale header and passes it to the header observer.
+// Verification failures are not punished since the header may simply have lost
+// the fork race.
+func (f *BlockFetcher) verifyStale(header *types.Header) {
+	if f.light {
+		if f.getHeader(header.ParentHash) == nil {
+			return
+		}
+	} else if f.getBlock(header.ParentHash) == nil {
+		return
+	}
+	if err := f.verifyHeader(header); err != nil {
+		log.Debug("Stale header verification failed", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	f.observeHeader(header)
+}
+
 // importHeaders spawns a new goroutine to run a header insertion into the chain.
 // If the header's number is at the same height as the current import phase, it
 // updates the phase states accordingly.
@@ -880,6 +992,7 @@ func (f *BlockFetcher) importHeaders(op *blockOrHeaderInject) {
 			f.dropPeer(peer)
 			return
 		}
+		f.observe(header)
 		// Run the actual import and log any issues
 		if _, err := f.insertHeaders([]*types.Header{header}); err != nil {
 			log.Debug("Propagated header import failed", "peer", peer, "number", header.Number, "hash", hash, "err", err)
@@ -921,6 +1034,7 @@ func (f *BlockFetcher) importBlocks(op *blockOrHeaderInject) {
 			// All ok, quickly propagate to our peers
 			blockBroadcastOutTimer.UpdateSince(block.ReceivedAt)
 			go f.broadcastBlock(block, true)
+			f.observe(block.Header())
 
 		case consensus.ErrFutureBlock:
 			log.Error("Received future block", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
@@ -103,7 +103,7 @@ func newTester(light bool) *fetcherTester {
 		drops:   make(map[string]bool),
 	}
 	tester.fetcher = NewBlockFetcher(light, tester.getHeader, tester.getBlock, tester.verifyHeader,
-		tester.broadcastBlock, tester.chainHeight, tester.chainFinalizedHeight, tester.insertHeaders,
+		nil, tester.broadcastBlock, tester.chainHeight, tester.chainFinalizedHeight, tester.insertHeaders,
 		tester.insertChain, tester.dropPeer)
 	tester.fetcher.Start()
 
@@ -706,6 +706,71 @@ func TestDistantPropagationDiscarding(t *testing.T) {
 	}
 }
 
+// Tests that propagated blocks discarded for being below the finalized height are
+// still verified and observed, so that competing forks can be monitored.
+func TestStalePropagationObserving(t *testing.T) {
+	hashes, blocks := makeChain(maxUncleDist, 0, genesis)
+
+	// Create a tester with the whole chain imported
+	tester := newTester(false)
+	observed := make(chan *types.Header, 1)
+	tester.fetcher.observeHeader = func(header *types.Header) { observed <- header }
+
+	tester.lock.Lock()
+	tester.hashes = make([]common.Hash, 0, len(hashes))
+	for i := len(hashes) - 1; i >= 0; i-- {
+		tester.hashes = append(tester.hashes, hashes[i])
+	}
+	tester.blocks = blocks
+	tester.lock.Unlock()
+
+	// Propagate a competing block below the finalized height
+	_, forks := makeChain(1, 1, blocks[hashes[len(hashes)/2]])
+	var fork *types.Block
+	for _, block := range forks {
+		if block.NumberU64() > blocks[hashes[len(hashes)/2]].NumberU64() {
+			fork = block
+		}
+	}
+	tester.fetcher.Enqueue("stale", fork)
+
+	select {
+	case header := <-observed:
+		if header.Hash() != fork.Hash() {
+			t.Fatalf("observed header mismatch: have %x, want %x", header.Hash(), fork.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("stale block not observed")
+	}
+	if !tester.fetcher.queue.Empty() {
+		t.Fatalf("fetcher queued stale block")
+	}
+}
+
+// Tests that the stale headers waiting for verification are deduplicated and
+// bounded per peer.
+func TestStalePropagationLimit(t *testing.T) {
+	// The fetcher isn't started to keep the stale headers waiting
+	fetcher := NewBlockFetcher(false, nil, nil, nil, func(header *types.Header) {}, nil, nil, nil, nil, nil, nil)
+
+	newHeader := func(number int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(2)}
+	}
+	for i := 0; i < staleLimit+2; i++ {
+		fetcher.observeStale("spam", newHeader(int64(i)))
+		fetcher.observeStale("spam", newHeader(int64(i)))
+	}
+	if have := len(fetcher.stale); have != staleLimit {
+		t.Fatalf("stale headers waiting mismatch: have %d, want %d", have, staleLimit)
+	}
+	// Headers already waiting are skipped, others from other peers still accepted
+	fetcher.observeStale("other", newHeader(0))
+	fetcher.observeStale("other", newHeader(staleLimit+2))
+	if have := len(fetcher.stale); have != staleLimit+1 {
+		t.Fatalf("stale headers waiting mismatch: have %d, want %d", have, staleLimit+1)
+	}
+}
+
 // Tests that announcements with numbers much lower or higher than out current
 // head get discarded to prevent wasting resources on useless blocks from faulty
 // peers.
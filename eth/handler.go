@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"math"
 	"math/big"
@@ -37,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/eth/protocols/bsc"
+	"github.com/ethereum/go-ethereum/eth/protocols/diff"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/eth/protocols/trust"
@@ -124,6 +126,8 @@ type handlerConfig struct {
 	DirectBroadcast        bool
 	DisablePeerTxBroadcast bool
 	PeerSet                *peerSet
+	NodeKey                *ecdsa.PrivateKey // Node key to sign the roots served over `trust`, unsigned if nil
+	DiffSync               bool              // Whether to fetch the diff layers of the blocks to import over `diff`
 }
 
 type handler struct {
@@ -150,6 +154,7 @@ type handler struct {
 	txFetcher    *fetcher.TxFetcher
 	peers        *peerSet
 	merger       *consensus.Merger
+	nodeKey      *ecdsa.PrivateKey
 
 	eventMux       *event.TypeMux
 	txsCh          chan core.NewTxsEvent
@@ -194,6 +199,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		chain:                  config.Chain,
 		peers:                  config.PeerSet,
 		merger:                 config.Merger,
+		nodeKey:                config.NodeKey,
 		peersPerIP:             make(map[string]int),
 		requiredBlocks:         config.RequiredBlocks,
 		directBroadcast:        config.DirectBroadcast,
@@ -238,7 +244,12 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		return nil, errors.New("snap sync not supported with snapshots disabled")
 	}
 	// Construct the downloader (long sync)
-	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, nil, h.removePeer, h.enableSyncedFeatures)
+	var downloadOptions []downloader.DownloadOption
+	if config.DiffSync {
+		downloadOptions = append(downloadOptions, downloader.EnableDiffFetchOp(h.fetchDiffLayers))
+		h.chain.SetDiffPeerDropper(h.removePeer)
+	}
+	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, nil, h.removePeer, h.enableSyncedFeatures, downloadOptions...)
 
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
@@ -259,6 +270,12 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return h.chain.Engine().VerifyHeader(h.chain, header)
 	}
+	// Feed every verified propagated header into the double sign monitor, so that
+	// equivocation on competing forks is caught even if they are never imported.
+	var observer func(header *types.Header)
+	if dsm := h.chain.DoubleSignMonitor(); dsm != nil {
+		observer = dsm.Verify
+	}
 	heighter := func() uint64 {
 		return h.chain.CurrentBlock().Number.Uint64()
 	}
@@ -318,7 +335,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return h.chain.InsertChain(blocks)
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock,
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, observer, h.BroadcastBlock,
 		heighter, finalizeHeighter, nil, inserter, h.removePeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
@@ -410,6 +427,11 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		peer.Log().Error("Bsc extension barrier failed", "err", err)
 		return err
 	}
+	diff, err := h.peers.waitDiffExtension(peer)
+	if err != nil {
+		peer.Log().Error("Diff extension barrier failed", "err", err)
+		return err
+	}
 
 	// Execute the Ethereum handshake
 	var (
@@ -463,7 +485,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	peer.Log().Debug("Ethereum peer connected", "name", peer.Name())
 
 	// Register the peer locally
-	if err := h.peers.registerPeer(peer, snap, trust, bsc); err != nil {
+	if err := h.peers.registerPeer(peer, snap, trust, bsc, diff); err != nil {
 		peer.Log().Error("Ethereum peer registration failed", "err", err)
 		return err
 	}
@@ -615,6 +637,30 @@ func (h *handler) runBscExtension(peer *bsc.Peer, handler bsc.Handler) error {
 	return handler(peer)
 }
 
+// runDiffExtension registers a `diff` peer into the joint eth/diff peerset and
+// starts handling inbound messages. As `diff` is only a satellite protocol to
+// `eth`, all subsystem registrations and lifecycle management will be done by
+// the main `eth` handler to prevent strange races.
+func (h *handler) runDiffExtension(peer *diff.Peer, handler diff.Handler) error {
+	if !h.incHandlers() {
+		return p2p.DiscQuitting
+	}
+	defer h.decHandlers()
+
+	if err := h.peers.registerDiffExtension(peer); err != nil {
+		if metrics.Enabled {
+			if peer.Inbound() {
+				diff.IngressRegistrationErrorMeter.Mark(1)
+			} else {
+				diff.EgressRegistrationErrorMeter.Mark(1)
+			}
+		}
+		peer.Log().Error("Diff extension registration failed", "err", err)
+		return err
+	}
+	return handler(peer)
+}
+
 // removePeer requests disconnection of a peer.
 func (h *handler) removePeer(id string) {
 	peer := h.peers.peer(id)
@@ -0,0 +1,66 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/eth/protocols/diff"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// errNoDiffPeer is returned if no connected peer serves the diff layers.
+var errNoDiffPeer = errors.New("no peer serving diff layers")
+
+// diffHandler implements the diff.Backend interface to handle the various network
+// packets that are sent as replies or broadcasts.
+type diffHandler handler
+
+func (h *diffHandler) Chain() *core.BlockChain { return h.chain }
+
+// RunPeer is invoked when a peer joins on the `diff` protocol.
+func (h *diffHandler) RunPeer(peer *diff.Peer, hand diff.Handler) error {
+	return (*handler)(h).runDiffExtension(peer, hand)
+}
+
+// PeerInfo retrieves all known `diff` information about a peer.
+func (h *diffHandler) PeerInfo(id enode.ID) interface{} {
+	if p := h.peers.peer(id.String()); p != nil {
+		if p.diffExt != nil {
+			return p.diffExt.info()
+		}
+	}
+	return nil
+}
+
+// Handle is invoked from a peer's message handler when it receives a new remote
+// message that the handler couldn't consume and serve itself.
+func (h *diffHandler) Handle(peer *diff.Peer, packet diff.Packet) error {
+	switch packet := packet.(type) {
+	case *diff.DiffLayersPacket:
+		diffs, err := packet.Unpack()
+		if err != nil {
+			return err
+		}
+		for _, d := range diffs {
+			if err := h.chain.HandleDiffLayer(d, peer.ID()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unexpected diff packet type: %T", packet)
+	}
+}
+
+// fetchDiffLayers requests the diff layers of the blocks from the peer delivered
+// them if it serves the diff layers, otherwise from any other peer serving them.
+func (h *handler) fetchDiffLayers(pid string, hashes []common.Hash) error {
+	peer := h.peers.GetDiffPeer(pid)
+	if peer == nil {
+		return errNoDiffPeer
+	}
+	return peer.RequestDiffLayers(hashes)
+}
@@ -1,6 +1,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 
@@ -15,6 +16,9 @@ type trustHandler handler
 
 func (h *trustHandler) Chain() *core.BlockChain { return h.chain }
 
+// NodeKey retrieves the key the served roots are signed with.
+func (h *trustHandler) NodeKey() *ecdsa.PrivateKey { return h.nodeKey }
+
 // RunPeer is invoked when a peer joins on the `snap` protocol.
 func (h *trustHandler) RunPeer(peer *trust.Peer, hand trust.Handler) error {
 	return (*handler)(h).runTrustExtension(peer, hand)
@@ -42,7 +46,14 @@ func (h *trustHandler) Handle(peer *trust.Peer, packet trust.Packet) error {
 			Root:        packet.Root,
 		}
 		if vm := h.Chain().Validator().RemoteVerifyManager(); vm != nil {
-			vm.HandleRootResponse(verifyResult, peer.ID())
+			vm.HandleRootResponse(verifyResult, peer.ID(), packet.Signature())
+			return nil
+		}
+		return errors.New("verify manager is nil which is unexpected")
+
+	case *trust.ProofResponsePacket:
+		if vm := h.Chain().Validator().RemoteVerifyManager(); vm != nil {
+			vm.HandleProofResponse(packet.BlockHash, peer.ID(), packet.Proofs)
 			return nil
 		}
 		return errors.New("verify manager is nil which is unexpected")
@@ -20,6 +20,7 @@ import (
 	"net"
 
 	"github.com/ethereum/go-ethereum/eth/protocols/bsc"
+	"github.com/ethereum/go-ethereum/eth/protocols/diff"
 	"github.com/ethereum/go-ethereum/eth/protocols/trust"
 
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
@@ -37,7 +38,8 @@ type ethPeer struct {
 	*eth.Peer
 	snapExt  *snapPeer // Satellite `snap` connection
 	trustExt *trustPeer
-	bscExt   *bscPeer // Satellite `bsc` connection
+	bscExt   *bscPeer  // Satellite `bsc` connection
+	diffExt  *diffPeer // Satellite `diff` connection
 }
 
 // info gathers and returns some `eth` protocol metadata known about a peer.
@@ -72,6 +74,12 @@ type bscPeerInfo struct {
 	Version uint `json:"version"` // bsc protocol version negotiated
 }
 
+// diffPeerInfo represents a short summary of the `diff` sub-protocol metadata known
+// about a connected peer.
+type diffPeerInfo struct {
+	Version uint `json:"version"` // diff protocol version negotiated
+}
+
 // snapPeer is a wrapper around snap.Peer to maintain a few extra metadata.
 type snapPeer struct {
 	*snap.Peer
@@ -87,6 +95,11 @@ type bscPeer struct {
 	*bsc.Peer
 }
 
+// diffPeer is a wrapper around diff.Peer to maintain a few extra metadata.
+type diffPeer struct {
+	*diff.Peer
+}
+
 // info gathers and returns some `snap` protocol metadata known about a peer.
 func (p *snapPeer) info() *snapPeerInfo {
 	return &snapPeerInfo{
@@ -107,3 +120,10 @@ func (p *bscPeer) info() *bscPeerInfo {
 		Version: p.Version(),
 	}
 }
+
+// info gathers and returns some `diff` protocol metadata known about a peer.
+func (p *diffPeer) info() *diffPeerInfo {
+	return &diffPeerInfo{
+		Version: p.Version(),
+	}
+}
@@ -0,0 +1,212 @@
+package diff
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Handler is a callback to invoke from an outside runner after the boilerplate
+// exchanges have passed.
+type Handler func(peer *Peer) error
+
+type Backend interface {
+	// Chain retrieves the blockchain object to serve data.
+	Chain() *core.BlockChain
+
+	// RunPeer is invoked when a peer joins on the `eth` protocol. The handler
+	// should do any peer maintenance work, handshakes and validations. If all
+	// is passed, control should be given back to the `handler` to process the
+	// inbound messages going forward.
+	RunPeer(peer *Peer, handler Handler) error
+
+	PeerInfo(id enode.ID) interface{}
+
+	Handle(peer *Peer, packet Packet) error
+}
+
+// MakeProtocols constructs the P2P protocol definitions for `diff`.
+func MakeProtocols(backend Backend, dnsdisc enode.Iterator) []p2p.Protocol {
+	// Filter the discovery iterator for nodes advertising diff support.
+	dnsdisc = enode.Filter(dnsdisc, func(n *enode.Node) bool {
+		var diff enrEntry
+		return n.Load(&diff) == nil
+	})
+
+	protocols := make([]p2p.Protocol, len(ProtocolVersions))
+	for i, version := range ProtocolVersions {
+		version := version // Closure
+
+		protocols[i] = p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return backend.RunPeer(NewPeer(version, p, rw), func(peer *Peer) error {
+					defer peer.Close()
+					return Handle(backend, peer)
+				})
+			},
+			NodeInfo: func() interface{} {
+				return nodeInfo(backend.Chain())
+			},
+			PeerInfo: func(id enode.ID) interface{} {
+				return backend.PeerInfo(id)
+			},
+			Attributes:     []enr.Entry{&enrEntry{}},
+			DialCandidates: dnsdisc,
+		}
+	}
+	return protocols
+}
+
+// Handle is the callback invoked to manage the life cycle of a `diff` peer.
+// When this function terminates, the peer is disconnected.
+func Handle(backend Backend, peer *Peer) error {
+	for {
+		if err := handleMessage(backend, peer); err != nil {
+			peer.Log().Debug("Message handling failed in `diff`", "err", err)
+			return err
+		}
+	}
+}
+
+// handleMessage is invoked whenever an inbound message is received from a
+// remote peer on the `diff` protocol. The remote connection is torn down upon
+// returning any error.
+func handleMessage(backend Backend, peer *Peer) error {
+	// Read the next message from the remote peer, and ensure it's fully consumed
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+
+	// Track the amount of time it takes to serve the request and run the handler
+	if metrics.Enabled {
+		h := fmt.Sprintf("%s/%s/%d/%#02x", p2p.HandleHistName, ProtocolName, peer.Version(), msg.Code)
+		defer func(start time.Time) {
+			sampler := func() metrics.Sample {
+				return metrics.ResettingSample(
+					metrics.NewExpDecaySample(1028, 0.015),
+				)
+			}
+			metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(time.Since(start).Microseconds())
+		}(time.Now())
+	}
+	// Handle the message depending on its contents
+	switch {
+	case msg.Code == GetDiffLayerMsg:
+		return handleGetDiffLayers(backend, msg, peer)
+
+	case msg.Code == DiffLayerMsg:
+		return handleDiffLayers(backend, msg, peer)
+
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+type Decoder interface {
+	Decode(val interface{}) error
+	Time() time.Time
+}
+
+func handleGetDiffLayers(backend Backend, msg Decoder, peer *Peer) error {
+	req := new(GetDiffLayersPacket)
+	if err := msg.Decode(req); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if len(req.BlockHashes) > MaxDiffLayerServe {
+		return fmt.Errorf("%w: %d", errTooManyHashes, len(req.BlockHashes))
+	}
+	return p2p.Send(peer.rw, DiffLayerMsg, DiffLayersPacket{
+		RequestId:  req.RequestId,
+		DiffLayers: answerGetDiffLayers(backend.Chain(), req, peer),
+	})
+}
+
+// answerGetDiffLayers collects the known diff layers of the requested blocks,
+// up to the size limit of the response and the bandwidth limit of the peer.
+func answerGetDiffLayers(chain *core.BlockChain, req *GetDiffLayersPacket, peer *Peer) []rlp.RawValue {
+	var (
+		diffs []rlp.RawValue
+		bytes int
+		now   = time.Now()
+	)
+	for i, hash := range req.BlockHashes {
+		diff := chain.GetTrustedDiffLayer(hash)
+		if diff == nil {
+			missingDiffLayerMeter.Mark(1)
+			continue
+		}
+		blob, err := rlp.EncodeToBytes(diff)
+		if err != nil {
+			peer.Log().Error("Failed to encode diff layer", "hash", hash, "err", err)
+			continue
+		}
+		if bytes+len(blob) > softResponseLimit && len(diffs) > 0 {
+			break
+		}
+		if !peer.limiter.AllowN(now, len(blob)) {
+			throttledDiffLayerMeter.Mark(int64(len(req.BlockHashes) - i))
+			break
+		}
+		diffs = append(diffs, blob)
+		bytes += len(blob)
+	}
+	servedDiffLayerMeter.Mark(int64(len(diffs)))
+	servedDiffBytesMeter.Mark(int64(bytes))
+	return diffs
+}
+
+func handleDiffLayers(backend Backend, msg Decoder, peer *Peer) error {
+	res := new(DiffLayersPacket)
+	if err := msg.Decode(res); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if len(res.DiffLayers) > MaxDiffLayerServe {
+		return fmt.Errorf("%w: %d diff layers", errDecode, len(res.DiffLayers))
+	}
+	requestTracker.Fulfil(peer.id, peer.version, DiffLayerMsg, res.RequestId)
+
+	// Only accept the diff layers of the blocks requested from the peer, as the
+	// blocks are imported from them
+	req := peer.fulfil(res.RequestId)
+	if req == nil {
+		return fmt.Errorf("%w: %d", errUnsolicitedResponse, res.RequestId)
+	}
+	diffs, err := res.Unpack()
+	if err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	for _, diff := range diffs {
+		if _, ok := req.hashes[diff.BlockHash]; !ok {
+			return fmt.Errorf("%w: %x", errUnrequestedDiff, diff.BlockHash)
+		}
+		delete(req.hashes, diff.BlockHash)
+	}
+	for _, blob := range res.DiffLayers {
+		receivedDiffBytesMeter.Mark(int64(len(blob)))
+	}
+	receivedDiffLayerMeter.Mark(int64(len(res.DiffLayers)))
+	return backend.Handle(peer, res)
+}
+
+// NodeInfo represents a short summary of the `diff` sub-protocol metadata
+// known about the host peer.
+type NodeInfo struct{}
+
+// nodeInfo retrieves some `diff` protocol metadata about the running host node.
+func nodeInfo(chain *core.BlockChain) *NodeInfo {
+	return &NodeInfo{}
+}
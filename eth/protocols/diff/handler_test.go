@@ -0,0 +1,279 @@
+package diff
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// testKey is a private key to use for funding a tester account.
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+	// testAddr is the Ethereum address of the tester account.
+	testAddr = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// testBackend is a mock implementation of the live Ethereum message handler. Its
+// purpose is to allow testing the request/reply workflows and wire serialization
+// in the `diff` protocol without actually doing any data processing.
+type testBackend struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+// newTestBackend creates a chain with a number of blocks, each with a transfer,
+// and wraps it into a mock backend.
+func newTestBackend(blocks int) *testBackend {
+	signer := types.HomesteadSigner{}
+	db := rawdb.NewMemoryDatabase()
+	engine := clique.New(params.AllCliqueProtocolChanges.Clique, db)
+	genspec := &core.Genesis{
+		Config:    params.AllCliqueProtocolChanges,
+		ExtraData: make([]byte, 32+common.AddressLength+65),
+		Alloc:     types.GenesisAlloc{testAddr: {Balance: big.NewInt(100000000000000000)}},
+		BaseFee:   big.NewInt(0),
+	}
+	copy(genspec.ExtraData[32:], testAddr[:])
+	genesis := genspec.MustCommit(db, triedb.NewDatabase(db, nil))
+
+	chain, _ := core.NewBlockChain(db, nil, genspec, nil, engine, vm.Config{}, nil, nil)
+	generator := func(i int, block *core.BlockGen) {
+		block.SetDifficulty(big.NewInt(2))
+
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(testAddr), common.Address{0x01}, big.NewInt(1), params.TxGas, nil, nil), signer, testKey)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTxWithChain(chain, tx)
+	}
+
+	bs, _ := core.GenerateChain(params.AllCliqueProtocolChanges, genesis, engine, db, blocks, generator)
+	for i, block := range bs {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = bs[i-1].Hash()
+		}
+		header.Extra = make([]byte, 32+65)
+		header.Difficulty = big.NewInt(2)
+
+		sig, _ := crypto.Sign(clique.SealHash(header).Bytes(), testKey)
+		copy(header.Extra[len(header.Extra)-65:], sig)
+		bs[i] = block.WithSeal(header)
+	}
+
+	if _, err := chain.InsertChain(bs); err != nil {
+		panic(err)
+	}
+	// The diff layers are cached asynchronously after the import
+	for _, block := range bs {
+		for chain.GetTrustedDiffLayer(block.Hash()) == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return &testBackend{
+		db:    db,
+		chain: chain,
+	}
+}
+
+// close tears down the chain behind the mock backend.
+func (b *testBackend) close() {
+	b.chain.Stop()
+}
+
+func (b *testBackend) Chain() *core.BlockChain { return b.chain }
+
+func (b *testBackend) RunPeer(peer *Peer, handler Handler) error {
+	// Normally the backend would do peer mainentance and handshakes. All that
+	// is omitted and we will just give control back to the handler.
+	return handler(peer)
+}
+func (b *testBackend) PeerInfo(enode.ID) interface{} { panic("not implemented") }
+
+func (b *testBackend) Handle(*Peer, Packet) error {
+	panic("data processing tests should be done in the handler package")
+}
+
+// encodeDiffLayers encodes the diff layers of the blocks as served.
+func encodeDiffLayers(t *testing.T, chain *core.BlockChain, numbers ...uint64) []rlp.RawValue {
+	var diffs []rlp.RawValue
+	for _, number := range numbers {
+		blob, err := rlp.EncodeToBytes(chain.GetTrustedDiffLayer(chain.GetHeaderByNumber(number).Hash()))
+		if err != nil {
+			t.Fatalf("failed to encode diff layer %d: %v", number, err)
+		}
+		diffs = append(diffs, blob)
+	}
+	return diffs
+}
+
+func TestGetDiffLayers(t *testing.T) {
+	backend := newTestBackend(16)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	chain := backend.Chain()
+	hashes := []common.Hash{
+		chain.GetHeaderByNumber(1).Hash(),
+		{0x01}, // unknown block, skipped
+		chain.GetHeaderByNumber(8).Hash(),
+		chain.GetHeaderByNumber(16).Hash(),
+	}
+	p2p.Send(peer.app, GetDiffLayerMsg, GetDiffLayersPacket{RequestId: 1, BlockHashes: hashes})
+	res := DiffLayersPacket{RequestId: 1, DiffLayers: encodeDiffLayers(t, chain, 1, 8, 16)}
+	if err := p2p.ExpectMsg(peer.app, DiffLayerMsg, res); err != nil {
+		t.Fatalf("diff layers response not expected: %v", err)
+	}
+	diffs, err := res.Unpack()
+	if err != nil {
+		t.Fatalf("failed to unpack diff layers: %v", err)
+	}
+	for i, number := range []uint64{1, 8, 16} {
+		if diffs[i].Number != number || diffs[i].BlockHash != chain.GetHeaderByNumber(number).Hash() {
+			t.Fatalf("diff layer %d mismatch: have block %d %x", i, diffs[i].Number, diffs[i].BlockHash)
+		}
+		if len(diffs[i].Receipts) != 1 || len(diffs[i].Accounts) == 0 {
+			t.Fatalf("diff layer %d is incomplete: %d receipts, %d accounts", i, len(diffs[i].Receipts), len(diffs[i].Accounts))
+		}
+	}
+}
+
+func TestGetDiffLayersThrottled(t *testing.T) {
+	backend := newTestBackend(4)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	// Only leave the bandwidth to serve the first two diff layers
+	chain := backend.Chain()
+	served := encodeDiffLayers(t, chain, 1, 2)
+	peer.Peer.limiter = rate.NewLimiter(rate.Limit(1), len(served[0])+len(served[1]))
+
+	var hashes []common.Hash
+	for i := uint64(1); i <= 4; i++ {
+		hashes = append(hashes, chain.GetHeaderByNumber(i).Hash())
+	}
+	p2p.Send(peer.app, GetDiffLayerMsg, GetDiffLayersPacket{RequestId: 1, BlockHashes: hashes})
+	if err := p2p.ExpectMsg(peer.app, DiffLayerMsg, DiffLayersPacket{RequestId: 1, DiffLayers: served}); err != nil {
+		t.Fatalf("diff layers response not expected: %v", err)
+	}
+}
+
+func TestGetDiffLayersTooMany(t *testing.T) {
+	backend := newTestBackend(1)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	hashes := make([]common.Hash, MaxDiffLayerServe+1)
+	if err := peer.Peer.RequestDiffLayers(hashes); !errors.Is(err, errTooManyHashes) {
+		t.Fatalf("request error mismatch: have %v, want %v", err, errTooManyHashes)
+	}
+	p2p.Send(peer.app, GetDiffLayerMsg, GetDiffLayersPacket{RequestId: 1, BlockHashes: hashes})
+	if err := <-errc; !errors.Is(err, errTooManyHashes) {
+		t.Fatalf("serve error mismatch: have %v, want %v", err, errTooManyHashes)
+	}
+}
+
+// requestDiffLayers sends the request from the local peer, and returns the id of
+// the request received by the remote side.
+func requestDiffLayers(t *testing.T, peer *testPeer, hashes []common.Hash) uint64 {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- peer.Peer.RequestDiffLayers(hashes)
+	}()
+	msg, err := peer.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read request: %v", err)
+	}
+	defer msg.Discard()
+
+	req := new(GetDiffLayersPacket)
+	if err := msg.Decode(req); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	return req.RequestId
+}
+
+func TestDiffLayersUnsolicited(t *testing.T) {
+	backend := newTestBackend(2)
+	defer backend.close()
+
+	// The response to a request never sent is rejected
+	peer, errc := newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	chain := backend.Chain()
+	p2p.Send(peer.app, DiffLayerMsg, DiffLayersPacket{RequestId: 1, DiffLayers: encodeDiffLayers(t, chain, 1)})
+	if err := <-errc; !errors.Is(err, errUnsolicitedResponse) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnsolicitedResponse)
+	}
+
+	// The diff layer of a block not requested is rejected
+	peer, errc = newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	id := requestDiffLayers(t, peer, []common.Hash{chain.GetHeaderByNumber(1).Hash()})
+	p2p.Send(peer.app, DiffLayerMsg, DiffLayersPacket{RequestId: id, DiffLayers: encodeDiffLayers(t, chain, 2)})
+	if err := <-errc; !errors.Is(err, errUnrequestedDiff) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnrequestedDiff)
+	}
+
+	// The same diff layer is only accepted once
+	peer, errc = newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	id = requestDiffLayers(t, peer, []common.Hash{chain.GetHeaderByNumber(1).Hash()})
+	p2p.Send(peer.app, DiffLayerMsg, DiffLayersPacket{RequestId: id, DiffLayers: encodeDiffLayers(t, chain, 1, 1)})
+	if err := <-errc; !errors.Is(err, errUnrequestedDiff) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnrequestedDiff)
+	}
+}
+
+func TestDiffLayersPendingRequests(t *testing.T) {
+	backend := newTestBackend(1)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", Diff1, backend)
+	defer peer.close()
+
+	hashes := []common.Hash{backend.Chain().GetHeaderByNumber(1).Hash()}
+	for i := 0; i < maxPendingRequests; i++ {
+		requestDiffLayers(t, peer, hashes)
+	}
+	if err := peer.Peer.RequestDiffLayers(hashes); !errors.Is(err, errTooManyRequests) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManyRequests)
+	}
+	// The requests never answered expire
+	for _, req := range peer.Peer.requests {
+		req.sent = req.sent.Add(-requestTimeout - time.Second)
+	}
+	requestDiffLayers(t, peer, hashes)
+	if len(peer.Peer.requests) != 1 {
+		t.Fatalf("pending requests mismatch: have %d, want 1", len(peer.Peer.requests))
+	}
+}
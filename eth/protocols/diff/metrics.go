@@ -0,0 +1,22 @@
+package diff
+
+import (
+	metrics "github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	ingressRegistrationErrorName = "eth/protocols/diff/ingress/registration/error"
+	egressRegistrationErrorName  = "eth/protocols/diff/egress/registration/error"
+
+	IngressRegistrationErrorMeter = metrics.NewRegisteredMeter(ingressRegistrationErrorName, nil)
+	EgressRegistrationErrorMeter  = metrics.NewRegisteredMeter(egressRegistrationErrorName, nil)
+
+	// Meters of the diff layers served to and received from the peers
+	servedDiffLayerMeter    = metrics.NewRegisteredMeter("eth/protocols/diff/served/layers", nil)
+	servedDiffBytesMeter    = metrics.NewRegisteredMeter("eth/protocols/diff/served/bytes", nil)
+	missingDiffLayerMeter   = metrics.NewRegisteredMeter("eth/protocols/diff/served/missing", nil)
+	throttledDiffLayerMeter = metrics.NewRegisteredMeter("eth/protocols/diff/served/throttled", nil)
+	receivedDiffLayerMeter  = metrics.NewRegisteredMeter("eth/protocols/diff/received/layers", nil)
+	receivedDiffBytesMeter  = metrics.NewRegisteredMeter("eth/protocols/diff/received/bytes", nil)
+	requestedDiffLayerMeter = metrics.NewRegisteredMeter("eth/protocols/diff/requested/layers", nil)
+)
@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"golang.org/x/time/rate"
+)
+
+// Peer is a collection of relevant information we have about a `diff` peer.
+type Peer struct {
+	id string // Unique ID for the peer, cached
+
+	*p2p.Peer                   // The embedded P2P package peer
+	rw        p2p.MsgReadWriter // Input/output streams for diff
+	version   uint              // Protocol version negotiated
+	limiter   *rate.Limiter     // Limiter of the bandwidth serving the peer
+	logger    log.Logger        // Contextual logger with the peer id injected
+
+	requests map[uint64]*diffRequest // Requests sent to the peer and not answered yet
+	lock     sync.Mutex              // Mutex protecting the pending requests
+}
+
+// diffRequest is a request for diff layers sent to the peer, only the diff layers
+// of the requested blocks are accepted in the response.
+type diffRequest struct {
+	hashes map[common.Hash]struct{}
+	sent   time.Time
+}
+
+// NewPeer create a wrapper for a network connection and negotiated  protocol
+// version.
+func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
+	id := p.ID().String()
+	peer := &Peer{
+		id:       id,
+		Peer:     p,
+		rw:       rw,
+		version:  version,
+		limiter:  rate.NewLimiter(serveBandwidth, serveBandwidth),
+		logger:   log.New("peer", id[:8]),
+		requests: make(map[uint64]*diffRequest),
+	}
+	return peer
+}
+
+// ID retrieves the peer's unique identifier.
+func (p *Peer) ID() string {
+	return p.id
+}
+
+// Version retrieves the peer's negotiated `diff` protocol version.
+func (p *Peer) Version() uint {
+	return p.version
+}
+
+// Log overrides the P2P logget with the higher level one containing only the id.
+func (p *Peer) Log() log.Logger {
+	return p.logger
+}
+
+// Close signals the broadcast goroutine to terminate. Only ever call this if
+// you created the peer yourself via NewPeer. Otherwise let whoever created it
+// clean it up!
+func (p *Peer) Close() {
+}
+
+// RequestDiffLayers fetches the diff layers of a batch of blocks.
+func (p *Peer) RequestDiffLayers(hashes []common.Hash) error {
+	if len(hashes) > MaxDiffLayerServe {
+		return fmt.Errorf("%w: %d", errTooManyHashes, len(hashes))
+	}
+	req := &diffRequest{
+		hashes: make(map[common.Hash]struct{}, len(hashes)),
+		sent:   time.Now(),
+	}
+	for _, hash := range hashes {
+		req.hashes[hash] = struct{}{}
+	}
+	id, err := p.track(req)
+	if err != nil {
+		return err
+	}
+	requestedDiffLayerMeter.Mark(int64(len(hashes)))
+	requestTracker.Track(p.id, p.version, GetDiffLayerMsg, DiffLayerMsg, id)
+	return p2p.Send(p.rw, GetDiffLayerMsg, GetDiffLayersPacket{
+		RequestId:   id,
+		BlockHashes: hashes,
+	})
+}
+
+// track records the request pending for a response, after dropping the requests
+// the peer never answered.
+func (p *Peer) track(req *diffRequest) (uint64, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for id, pending := range p.requests {
+		if req.sent.Sub(pending.sent) > requestTimeout {
+			delete(p.requests, id)
+		}
+	}
+	if len(p.requests) >= maxPendingRequests {
+		return 0, fmt.Errorf("%w: %d", errTooManyRequests, len(p.requests))
+	}
+	id := rand.Uint64()
+	for _, ok := p.requests[id]; ok; _, ok = p.requests[id] {
+		id = rand.Uint64()
+	}
+	p.requests[id] = req
+	return id, nil
+}
+
+// fulfil removes the request answered by the response, nil if the request was
+// never sent or has timed out.
+func (p *Peer) fulfil(id uint64) *diffRequest {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	req := p.requests[id]
+	if req == nil || time.Since(req.sent) > requestTimeout {
+		return nil
+	}
+	delete(p.requests, id)
+	return req
+}
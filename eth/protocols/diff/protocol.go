@@ -0,0 +1,113 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	Diff1 = 1
+)
+
+// ProtocolName is the official short name of the `diff` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "diff"
+
+// ProtocolVersions are the supported versions of the `diff` protocol (first
+// is primary).
+var ProtocolVersions = []uint{Diff1}
+
+// protocolLengths are the number of implemented message corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{Diff1: 2}
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 10 * 1024 * 1024
+
+const (
+	GetDiffLayerMsg = 0x00
+	DiffLayerMsg    = 0x01
+)
+
+const (
+	// MaxDiffLayerServe is the max number of diff layers requested or served in
+	// a single message.
+	MaxDiffLayerServe = 128
+
+	// softResponseLimit is the target max size of a response, the diff layers
+	// beyond it are left out.
+	softResponseLimit = 2 * 1024 * 1024
+
+	// serveBandwidth is the max number of bytes of diff layers served to a peer
+	// per second, the diff layers beyond it are left out.
+	serveBandwidth = 4 * 1024 * 1024
+
+	// maxPendingRequests is the max number of requests waiting for a response
+	// from a peer.
+	maxPendingRequests = 16
+
+	// requestTimeout is the time after which a response to the request is not
+	// accepted anymore.
+	requestTimeout = time.Minute
+)
+
+var (
+	errMsgTooLarge         = errors.New("message too long")
+	errDecode              = errors.New("invalid message")
+	errInvalidMsgCode      = errors.New("invalid message code")
+	errTooManyHashes       = errors.New("too many block hashes requested")
+	errTooManyRequests     = errors.New("too many pending requests")
+	errUnsolicitedResponse = errors.New("response to unknown request")
+	errUnrequestedDiff     = errors.New("diff layer of block not requested")
+)
+
+// Packet represents a p2p message in the `diff` protocol.
+type Packet interface {
+	Name() string // Name returns a string corresponding to the message type.
+	Kind() byte   // Kind returns the message type.
+}
+
+// GetDiffLayersPacket requests the diff layers of a range of blocks.
+type GetDiffLayersPacket struct {
+	RequestId   uint64
+	BlockHashes []common.Hash
+}
+
+// DiffLayersPacket is the response to GetDiffLayersPacket, with the diff layers
+// available in the order of the requested blocks. The unknown ones are skipped,
+// and so are the trailing ones over the size or bandwidth limits.
+type DiffLayersPacket struct {
+	RequestId  uint64
+	DiffLayers []rlp.RawValue
+
+	diffs []*types.DiffLayer // Diff layers decoded already, not sent over the wire
+}
+
+// Unpack decodes the diff layers carried in the packet.
+func (p *DiffLayersPacket) Unpack() ([]*types.DiffLayer, error) {
+	if p.diffs != nil {
+		return p.diffs, nil
+	}
+	diffs := make([]*types.DiffLayer, 0, len(p.DiffLayers))
+	for i, blob := range p.DiffLayers {
+		diff := new(types.DiffLayer)
+		if err := rlp.DecodeBytes(blob, diff); err != nil {
+			return nil, fmt.Errorf("diff layer %d: %v", i, err)
+		}
+		diffs = append(diffs, diff)
+	}
+	p.diffs = diffs
+	return diffs, nil
+}
+
+func (*GetDiffLayersPacket) Name() string { return "GetDiffLayers" }
+func (*GetDiffLayersPacket) Kind() byte   { return GetDiffLayerMsg }
+
+func (*DiffLayersPacket) Name() string { return "DiffLayers" }
+func (*DiffLayersPacket) Kind() byte   { return DiffLayerMsg }
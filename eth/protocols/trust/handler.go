@@ -1,14 +1,18 @@
 package trust
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // Handler is a callback to invoke from an outside runner after the boilerplate
@@ -19,6 +23,10 @@ type Backend interface {
 	// Chain retrieves the blockchain object to serve data.
 	Chain() *core.BlockChain
 
+	// NodeKey retrieves the node key the served roots are signed with, the
+	// roots are served unsigned if nil.
+	NodeKey() *ecdsa.PrivateKey
+
 	// RunPeer is invoked when a peer joins on the `eth` protocol. The handler
 	// should do any peer maintenance work, handshakes and validations. If all
 	// is passed, control should be given back to the `handler` to process the
@@ -110,6 +118,12 @@ func handleMessage(backend Backend, peer *Peer) error {
 	case msg.Code == RespondRootMsg:
 		return handleRootResponse(backend, msg, peer)
 
+	case msg.Code == RequestProofMsg && peer.Version() >= Trust2:
+		return handleProofRequest(backend, msg, peer)
+
+	case msg.Code == RespondProofMsg && peer.Version() >= Trust2:
+		return handleProofResponse(backend, msg, peer)
+
 	default:
 		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
 	}
@@ -133,10 +147,26 @@ func handleRootRequest(backend Backend, msg Decoder, peer *Peer) error {
 		BlockNumber: req.BlockNumber,
 		BlockHash:   req.BlockHash,
 		Root:        res.Root,
-		Extra:       defaultExtra,
+		Extra:       signRoot(backend.NodeKey(), res),
 	})
 }
 
+// signRoot signs the verify result with the node key, so that the requester can
+// prove which node attested the root. The signature is carried in the extra field
+// of the response, which is ignored by the nodes not checking it.
+func signRoot(key *ecdsa.PrivateKey, res *core.VerifyResult) rlp.RawValue {
+	if key == nil {
+		return defaultExtra
+	}
+	sig, err := crypto.Sign(res.SigHash().Bytes(), key)
+	if err != nil {
+		log.Error("Failed to sign verify result", "hash", res.BlockHash, "err", err)
+		return defaultExtra
+	}
+	extra, _ := rlp.EncodeToBytes(sig)
+	return extra
+}
+
 func handleRootResponse(backend Backend, msg Decoder, peer *Peer) error {
 	res := new(RootResponsePacket)
 	if err := msg.Decode(res); err != nil {
@@ -147,6 +177,45 @@ func handleRootResponse(backend Backend, msg Decoder, peer *Peer) error {
 	return backend.Handle(peer, res)
 }
 
+func handleProofRequest(backend Backend, msg Decoder, peer *Peer) error {
+	req := new(ProofRequestPacket)
+	if err := msg.Decode(req); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if len(req.Accounts) > maxProofAccounts {
+		return fmt.Errorf("%w: %d accounts to prove", errDecode, len(req.Accounts))
+	}
+	var slots int
+	for _, query := range req.Accounts {
+		slots += len(query.Slots)
+	}
+	if slots > maxProofSlots {
+		return fmt.Errorf("%w: %d slots to prove", errDecode, slots)
+	}
+
+	proofs, err := backend.Chain().GetStateProofs(req.BlockHash, req.Accounts)
+	if err != nil {
+		peer.Log().Debug("Failed to prove state", "hash", req.BlockHash, "err", err)
+		// An empty response tells the requester to ask another peer
+		proofs = nil
+	}
+	return p2p.Send(peer.rw, RespondProofMsg, ProofResponsePacket{
+		RequestId: req.RequestId,
+		BlockHash: req.BlockHash,
+		Proofs:    proofs,
+	})
+}
+
+func handleProofResponse(backend Backend, msg Decoder, peer *Peer) error {
+	res := new(ProofResponsePacket)
+	if err := msg.Decode(res); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+
+	requestTracker.Fulfil(peer.id, peer.version, RespondProofMsg, res.RequestId)
+	return backend.Handle(peer, res)
+}
+
 // NodeInfo represents a short summary of the `trust` sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct{}
@@ -1,6 +1,8 @@
 package trust
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -16,6 +18,9 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/triedb"
 )
 
@@ -34,6 +39,7 @@ type testBackend struct {
 	db     ethdb.Database
 	chain  *core.BlockChain
 	txpool *legacypool.LegacyPool
+	key    *ecdsa.PrivateKey
 }
 
 // newTestBackend creates an empty chain and wraps it into a mock backend.
@@ -108,6 +114,8 @@ func (b *testBackend) close() {
 
 func (b *testBackend) Chain() *core.BlockChain { return b.chain }
 
+func (b *testBackend) NodeKey() *ecdsa.PrivateKey { return b.key }
+
 func (b *testBackend) RunPeer(peer *Peer, handler Handler) error {
 	// Normally the backend would do peer mainentance and handshakes. All that
 	// is omitted and we will just give control back to the handler.
@@ -119,7 +127,8 @@ func (b *testBackend) Handle(*Peer, Packet) error {
 	panic("data processing tests should be done in the handler package")
 }
 
-func TestRequestRoot(t *testing.T) { testRequestRoot(t, Trust1) }
+func TestRequestRoot(t *testing.T)  { testRequestRoot(t, Trust1) }
+func TestRequestRoot2(t *testing.T) { testRequestRoot(t, Trust2) }
 
 func testRequestRoot(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -271,3 +280,113 @@ func testRequestRoot(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+func TestSignedRootResponse(t *testing.T) {
+	backend := newTestBackend(16)
+	defer backend.close()
+	backend.key, _ = crypto.GenerateKey()
+
+	peer, _ := newTestPeer("peer", Trust1, backend)
+	defer peer.close()
+
+	header := backend.Chain().GetHeaderByNumber(8)
+	diffHash, _ := core.CalculateDiffHash(backend.Chain().GetTrustedDiffLayer(header.Hash()))
+	p2p.Send(peer.app, RequestRootMsg, RootRequestPacket{RequestId: 1, BlockNumber: 8, BlockHash: header.Hash(), DiffHash: diffHash})
+
+	msg, err := peer.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read root response: %v", err)
+	}
+	res := new(RootResponsePacket)
+	if err := msg.Decode(res); err != nil {
+		t.Fatalf("failed to decode root response: %v", err)
+	}
+	if res.Status != types.StatusFullVerified || res.Root != header.Root {
+		t.Fatalf("root response mismatch: have %v %x, want %v %x", res.Status, res.Root, types.StatusFullVerified, header.Root)
+	}
+	result := &core.VerifyResult{Status: res.Status, BlockNumber: res.BlockNumber, BlockHash: res.BlockHash, Root: res.Root}
+	signer, err := result.Signer(res.Signature())
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if want := enode.PubkeyToIDV4(&backend.key.PublicKey); signer != want {
+		t.Fatalf("signer mismatch: have %v, want %v", signer, want)
+	}
+
+	// The unsigned responses carry the default extra
+	if sig := (&RootResponsePacket{Extra: defaultExtra}).Signature(); sig != nil {
+		t.Fatalf("unsigned response has signature %x", sig)
+	}
+}
+
+func TestRequestProof(t *testing.T) {
+	backend := newTestBackend(16)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", Trust2, backend)
+	defer peer.close()
+
+	var (
+		header  = backend.Chain().GetHeaderByNumber(8)
+		queries = []core.AccountProofQuery{
+			{Account: crypto.Keccak256Hash(testAddr[:])},
+			{Account: crypto.Keccak256Hash(common.Address{0x01}.Bytes())},
+			{Account: crypto.Keccak256Hash(common.Address{0x02}.Bytes())},
+		}
+	)
+	p2p.Send(peer.app, RequestProofMsg, ProofRequestPacket{RequestId: 1, BlockNumber: 8, BlockHash: header.Hash(), Accounts: queries})
+
+	msg, err := peer.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read proof response: %v", err)
+	}
+	res := new(ProofResponsePacket)
+	if err := msg.Decode(res); err != nil {
+		t.Fatalf("failed to decode proof response: %v", err)
+	}
+	if res.RequestId != 1 || len(res.Proofs) != len(queries) {
+		t.Fatalf("proof response mismatch: have request %d with %d proofs, want request 1 with %d proofs", res.RequestId, len(res.Proofs), len(queries))
+	}
+	statedb, _ := backend.Chain().StateAt(header.Root)
+	for i, query := range queries {
+		proof := make(trienode.ProofList, len(res.Proofs[i].Proof))
+		for j, node := range res.Proofs[i].Proof {
+			proof[j] = node
+		}
+		value, err := trie.VerifyProof(header.Root, query.Account[:], proof.Set())
+		if err != nil {
+			t.Fatalf("account %d: invalid proof: %v", i, err)
+		}
+		if i == 2 {
+			if value != nil {
+				t.Fatalf("account %d: missing account proven present", i)
+			}
+			continue
+		}
+		account := new(types.StateAccount)
+		if err := rlp.DecodeBytes(value, account); err != nil {
+			t.Fatalf("account %d: failed to decode proven account: %v", i, err)
+		}
+		addr := []common.Address{testAddr, {0x01}}[i]
+		if balance := statedb.GetBalance(addr); account.Balance.Cmp(balance) != 0 {
+			t.Fatalf("account %d: balance mismatch: have %v, want %v", i, account.Balance, balance)
+		}
+	}
+}
+
+func TestRequestProofTrust1(t *testing.T) {
+	backend := newTestBackend(4)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", Trust1, backend)
+	defer peer.close()
+
+	header := backend.Chain().GetHeaderByNumber(2)
+	p2p.Send(peer.app, RequestProofMsg, ProofRequestPacket{RequestId: 1, BlockNumber: 2, BlockHash: header.Hash()})
+	if err := <-errc; !errors.Is(err, errInvalidMsgCode) {
+		t.Fatalf("trust/1 proof request error mismatch: have %v, want %v", err, errInvalidMsgCode)
+	}
+	if err := peer.Peer.RequestProof(2, header.Hash(), nil); !errors.Is(err, errNoProofSupport) {
+		t.Fatalf("trust/1 proof request error mismatch: have %v, want %v", err, errNoProofSupport)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math/rand"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
 )
@@ -64,3 +65,20 @@ func (p *Peer) RequestRoot(blockNumber uint64, blockHash common.Hash, diffHash c
 		DiffHash:    diffHash,
 	})
 }
+
+// RequestProof requests the proofs of the accounts and storage slots against the
+// state root of the block, which is only served by the trust/2 peers.
+func (p *Peer) RequestProof(blockNumber uint64, blockHash common.Hash, queries []core.AccountProofQuery) error {
+	if p.version < Trust2 {
+		return errNoProofSupport
+	}
+	id := rand.Uint64()
+
+	requestTracker.Track(p.id, p.version, RequestProofMsg, RespondProofMsg, id)
+	return p2p.Send(p.rw, RequestProofMsg, ProofRequestPacket{
+		RequestId:   id,
+		BlockNumber: blockNumber,
+		BlockHash:   blockHash,
+		Accounts:    queries,
+	})
+}
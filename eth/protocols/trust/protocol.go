@@ -3,7 +3,9 @@ package trust
 import (
 	"errors"
 
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -12,6 +14,7 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	Trust1 = 1
+	Trust2 = 2
 )
 
 // ProtocolName is the official short name of the `trust` protocol used during
@@ -20,11 +23,11 @@ const ProtocolName = "trust"
 
 // ProtocolVersions are the supported versions of the `trust` protocol (first
 // is primary).
-var ProtocolVersions = []uint{Trust1}
+var ProtocolVersions = []uint{Trust2, Trust1}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{Trust1: 2}
+var protocolLengths = map[uint]uint64{Trust2: 4, Trust1: 2}
 
 // maxMessageSize is the maximum cap on the size of a protocol message.
 const maxMessageSize = 10 * 1024 * 1024
@@ -32,6 +35,17 @@ const maxMessageSize = 10 * 1024 * 1024
 const (
 	RequestRootMsg = 0x00
 	RespondRootMsg = 0x01
+
+	// Protocol messages introduced in trust/2
+	RequestProofMsg = 0x02
+	RespondProofMsg = 0x03
+)
+
+const (
+	// maxProofAccounts is the max number of accounts proven in a response.
+	maxProofAccounts = 64
+	// maxProofSlots is the max number of storage slots proven in a response.
+	maxProofSlots = 256
 )
 
 var defaultExtra = []byte{0x00}
@@ -40,6 +54,7 @@ var (
 	errMsgTooLarge    = errors.New("message too long")
 	errDecode         = errors.New("invalid message")
 	errInvalidMsgCode = errors.New("invalid message code")
+	errNoProofSupport = errors.New("peer does not serve state proofs")
 )
 
 // Packet represents a p2p message in the `trust` protocol.
@@ -64,8 +79,41 @@ type RootResponsePacket struct {
 	Extra       rlp.RawValue // for extension
 }
 
+// Signature returns the signature of the responding node over the result carried
+// in the extra field, nil if the response is not signed.
+func (p *RootResponsePacket) Signature() []byte {
+	var sig []byte
+	if err := rlp.DecodeBytes(p.Extra, &sig); err != nil || len(sig) != crypto.SignatureLength {
+		return nil
+	}
+	return sig
+}
+
+// ProofRequestPacket requests the Merkle proofs of some accounts and storage
+// slots against the state root of a block.
+type ProofRequestPacket struct {
+	RequestId   uint64
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Accounts    []core.AccountProofQuery
+}
+
+// ProofResponsePacket is the response to ProofRequestPacket, with the proofs in
+// the order of the requested accounts. It's empty if the state is not available.
+type ProofResponsePacket struct {
+	RequestId uint64
+	BlockHash common.Hash
+	Proofs    []*core.AccountProof
+}
+
 func (*RootRequestPacket) Name() string { return "RequestRoot" }
 func (*RootRequestPacket) Kind() byte   { return RequestRootMsg }
 
 func (*RootResponsePacket) Name() string { return "RootResponse" }
 func (*RootResponsePacket) Kind() byte   { return RespondRootMsg }
+
+func (*ProofRequestPacket) Name() string { return "RequestProof" }
+func (*ProofRequestPacket) Kind() byte   { return RequestProofMsg }
+
+func (*ProofResponsePacket) Name() string { return "ProofResponse" }
+func (*ProofResponsePacket) Kind() byte   { return RespondProofMsg }
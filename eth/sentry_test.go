@@ -0,0 +1,131 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newSentryTestNode starts a node serving the given genesis, configured by the
+// given function before the ethereum service is created.
+func newSentryTestNode(t *testing.T, genesis *core.Genesis, nodeConfig *node.Config, configure func(*node.Node, *ethconfig.Config)) (*node.Node, *Ethereum) {
+	stack, err := node.New(nodeConfig)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+
+	config := ethconfig.Defaults
+	config.Genesis = genesis
+	if configure != nil {
+		configure(stack, &config)
+	}
+	ethservice, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	return stack, ethservice
+}
+
+// TestSentryForwardBidToValidator runs a validator and a sentry node, checking the
+// builders added by admin_addBuilder on the sentry get their bids forwarded to the
+// validator, and the errors of the validator are relayed back as they are.
+func TestSentryForwardBidToValidator(t *testing.T) {
+	var (
+		payKey, _     = crypto.GenerateKey()
+		builderKey, _ = crypto.GenerateKey()
+		builder       = crypto.PubkeyToAddress(builderKey.PublicKey)
+		userKey, _    = crypto.GenerateKey()
+		genesis       = &core.Genesis{
+			Config:  params.AllEthashProtocolChanges,
+			Alloc:   types.GenesisAlloc{crypto.PubkeyToAddress(userKey.PublicKey): {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	validatorNode, validator := newSentryTestNode(t, genesis, &node.Config{
+		HTTPHost:    "127.0.0.1",
+		HTTPModules: []string{"eth", "mev"},
+	}, nil)
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	payAccount, err := ks.ImportECDSA(payKey, "")
+	if err != nil {
+		t.Fatalf("failed to import pay bid account: %v", err)
+	}
+	if err := ks.Unlock(payAccount, ""); err != nil {
+		t.Fatalf("failed to unlock pay bid account: %v", err)
+	}
+	sentryNode, _ := newSentryTestNode(t, genesis, &node.Config{}, func(stack *node.Node, config *ethconfig.Config) {
+		stack.AccountManager().AddBackend(ks)
+		config.Miner.Mev.Sentry = miner.SentryConfig{
+			Enabled:       true,
+			Upstreams:     []miner.SentryUpstreamConfig{{URL: validatorNode.HTTPEndpoint()}},
+			PayBidAccount: payAccount.Address,
+		}
+	})
+	client := sentryNode.Attach()
+	defer client.Close()
+
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x1}, big.NewInt(1), params.TxGas, big.NewInt(params.InitialBaseFee), nil),
+		types.LatestSigner(genesis.Config), userKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	txBytes, _ := tx.MarshalBinary()
+	rawBid := &types.RawBid{
+		BlockNumber: 1,
+		ParentHash:  validator.BlockChain().Genesis().Hash(),
+		Txs:         []hexutil.Bytes{txBytes},
+		GasUsed:     params.TxGas,
+		GasFee:      big.NewInt(params.GWei),
+		BuilderFee:  big.NewInt(1000),
+	}
+	sig, err := crypto.Sign(rawBid.Hash().Bytes(), builderKey)
+	if err != nil {
+		t.Fatalf("failed to sign bid: %v", err)
+	}
+	bid := types.BidArgs{RawBid: rawBid, Signature: sig}
+
+	sendBid := func() int {
+		var hash common.Hash
+		err := client.CallContext(context.Background(), &hash, "mev_sendBid", bid)
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("unexpected result of bid: %v", err)
+		}
+		return rpcErr.ErrorCode()
+	}
+	// The bids of unknown builders are rejected by the sentry itself
+	if code := sendBid(); code != types.InvalidBidParamError {
+		t.Fatalf("bid of unregistered builder: have error code %d, want %d", code, types.InvalidBidParamError)
+	}
+	// The builders added by the admin API reach the validator, which is not mining
+	if err := client.Call(nil, "admin_addBuilder", builder, ""); err != nil {
+		t.Fatalf("failed to add builder: %v", err)
+	}
+	if code := sendBid(); code != types.MevNotRunningError {
+		t.Fatalf("bid of added builder: have error code %d, want %d", code, types.MevNotRunningError)
+	}
+	if err := client.Call(nil, "admin_removeBuilder", builder); err != nil {
+		t.Fatalf("failed to remove builder: %v", err)
+	}
+	if code := sendBid(); code != types.InvalidBidParamError {
+		t.Fatalf("bid of removed builder: have error code %d, want %d", code, types.InvalidBidParamError)
+	}
+}
@@ -96,6 +96,10 @@ type AncientReaderOp interface {
 	// This number can also be interpreted as the total deleted item numbers.
 	Tail() (uint64, error)
 
+	// TableTail returns the number of first stored item in the specified table,
+	// which may be ahead of the freezer tail if the table is pruned on its own.
+	TableTail(kind string) (uint64, error)
+
 	// AncientSize returns the ancient size of the specified category.
 	AncientSize(kind string) (uint64, error)
 
@@ -151,6 +155,8 @@ type AncientWriter interface {
 type FreezerEnv struct {
 	ChainCfg         *params.ChainConfig
 	BlobExtraReserve uint64
+	BodyHistory      uint64 // Number of recent blocks whose bodies are kept in the freezer, 0 keeps all
+	ReceiptHistory   uint64 // Number of recent blocks whose receipts are kept in the freezer, 0 keeps all
 }
 
 // AncientFreezer defines the help functions for freezing ancient data
@@ -98,6 +98,10 @@ func (db *Database) Tail() (uint64, error) {
 	panic("not supported")
 }
 
+func (db *Database) TableTail(kind string) (uint64, error) {
+	panic("not supported")
+}
+
 func (db *Database) AncientSize(kind string) (uint64, error) {
 	panic("not supported")
 }
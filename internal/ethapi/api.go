@@ -1494,6 +1494,16 @@ func (s *BlockChainAPI) GetVerifyResult(ctx context.Context, blockNr rpc.BlockNu
 	return s.b.Chain().GetVerifyResult(uint64(blockNr), blockHash, diffHash)
 }
 
+// GetRemoteVerifyOutcome returns the outcome of the remote verification of the block
+// on a fast node, along with the roots attested by the verify nodes.
+func (s *BlockChainAPI) GetRemoteVerifyOutcome(ctx context.Context, blockHash common.Hash) (*core.RemoteVerifyOutcome, error) {
+	outcome := s.b.Chain().GetRemoteVerifyOutcome(blockHash)
+	if outcome == nil {
+		return nil, fmt.Errorf("no remote verify outcome of block %#x", blockHash)
+	}
+	return outcome, nil
+}
+
 // RPCMarshalHeader converts the given header to the RPC output .
 func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 	result := map[string]interface{}{
@@ -0,0 +1,203 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/gopool"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BundleAPI offers the methods for the searchers to submit and simulate the
+// transaction bundles.
+type BundleAPI struct {
+	b Backend
+}
+
+// NewBundleAPI creates a new BundleAPI.
+func NewBundleAPI(b Backend) *BundleAPI {
+	return &BundleAPI{b}
+}
+
+// CallBundleArgs represents the arguments to simulate a bundle.
+type CallBundleArgs struct {
+	Txs []hexutil.Bytes `json:"txs"`
+	// BlockNumber is the number of the block the bundle is simulated in, the block
+	// after the state block by default.
+	BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	// StateBlockNumberOrHash is the block whose state the bundle is executed on,
+	// the pending state by default.
+	StateBlockNumberOrHash *rpc.BlockNumberOrHash `json:"stateBlockNumber"`
+	Timestamp              *hexutil.Uint64        `json:"timestamp"`
+}
+
+// CallBundleTxResult is the execution result of a transaction in the bundle.
+type CallBundleTxResult struct {
+	TxHash   common.Hash     `json:"txHash"`
+	From     common.Address  `json:"fromAddress"`
+	To       *common.Address `json:"toAddress"`
+	GasUsed  hexutil.Uint64  `json:"gasUsed"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	GasFees  *hexutil.Big    `json:"gasFees"`
+	Value    hexutil.Bytes   `json:"value,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Revert   hexutil.Bytes   `json:"revert,omitempty"`
+}
+
+// CallBundleResult is the execution result of a bundle.
+type CallBundleResult struct {
+	BundleHash       common.Hash           `json:"bundleHash"`
+	BundleGasPrice   *hexutil.Big          `json:"bundleGasPrice"`
+	GasFees          *hexutil.Big          `json:"gasFees"`
+	TotalGasUsed     hexutil.Uint64        `json:"totalGasUsed"`
+	StateBlockNumber hexutil.Uint64        `json:"stateBlockNumber"`
+	Results          []*CallBundleTxResult `json:"results"`
+}
+
+// SendBundle submits the bundle into the bundle pool, the bundle is included as a
+// whole by the local blocks within its block range if it improves the block value.
+func (api *BundleAPI) SendBundle(ctx context.Context, args types.SendBundleArgs) (common.Hash, error) {
+	if len(args.Txs) == 0 {
+		return common.Hash{}, errors.New("bundle missing txs")
+	}
+	bundle, err := args.ToBundle()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.b.SendBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// CallBundle simulates the bundle on top of the given state, the transactions are
+// executed in order and the state changes of each are visible to the next ones.
+func (api *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (*CallBundleResult, error) {
+	if len(args.Txs) == 0 {
+		return nil, errors.New("bundle missing txs")
+	}
+	bundle, err := (&types.SendBundleArgs{Txs: args.Txs}).ToBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	stateBlock := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	if args.StateBlockNumberOrHash != nil {
+		stateBlock = *args.StateBlockNumberOrHash
+	}
+	state, parent, err := api.b.StateAndHeaderByNumberOrHash(ctx, stateBlock)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	config := api.b.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 1,
+		Difficulty: parent.Difficulty,
+		Coinbase:   parent.Coinbase,
+	}
+	if config.Parlia != nil {
+		header.Time = parent.Time + config.Parlia.Period
+	}
+	if args.BlockNumber != nil {
+		header.Number = new(big.Int).SetUint64(uint64(*args.BlockNumber))
+	}
+	if args.Timestamp != nil {
+		header.Time = uint64(*args.Timestamp)
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = eip1559.CalcBaseFee(config, parent)
+	}
+
+	// Setup context so it may be cancelled when the call has completed
+	var cancel context.CancelFunc
+	if timeout := api.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		signer   = types.MakeSigner(config, header.Number, header.Time)
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, api.b), nil)
+		gp       = new(core.GasPool).AddGas(header.GasLimit)
+		gasFees  = new(big.Int)
+		gasUsed  uint64
+		results  = make([]*CallBundleTxResult, 0, len(bundle.Txs))
+	)
+	for i, tx := range bundle.Txs {
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
+		}
+		state.SetTxContext(tx.Hash(), i)
+		evm := api.b.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true}, &blockCtx)
+
+		// Wait for the context to be done and cancel the evm
+		done := make(chan struct{})
+		gopool.Submit(func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel()
+			case <-done:
+			}
+		})
+		result, err := core.ApplyMessage(evm, msg, gp)
+		close(done)
+		if err := state.Error(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", api.b.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("err: %w; txhash %s", err, tx.Hash())
+		}
+		state.Finalise(config.IsEIP158(header.Number))
+
+		gasPrice := tx.EffectiveGasTipValue(header.BaseFee)
+		txGasFees := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(result.UsedGas))
+		txResult := &CallBundleTxResult{
+			TxHash:   tx.Hash(),
+			From:     msg.From,
+			To:       tx.To(),
+			GasUsed:  hexutil.Uint64(result.UsedGas),
+			GasPrice: (*hexutil.Big)(gasPrice),
+			GasFees:  (*hexutil.Big)(txGasFees),
+		}
+		if result.Failed() {
+			txResult.Error = result.Err.Error()
+			txResult.Revert = result.Revert()
+		} else {
+			txResult.Value = result.Return()
+		}
+		results = append(results, txResult)
+		gasFees.Add(gasFees, txGasFees)
+		gasUsed += result.UsedGas
+	}
+
+	bundleGasPrice := new(big.Int)
+	if gasUsed > 0 {
+		bundleGasPrice.Div(gasFees, new(big.Int).SetUint64(gasUsed))
+	}
+	return &CallBundleResult{
+		BundleHash:       bundle.Hash(),
+		BundleGasPrice:   (*hexutil.Big)(bundleGasPrice),
+		GasFees:          (*hexutil.Big)(gasFees),
+		TotalGasUsed:     hexutil.Uint64(gasUsed),
+		StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+		Results:          results,
+	}, nil
+}
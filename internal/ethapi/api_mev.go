@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/gopool"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // MevAPI implements the interfaces that defined in the BEP-322.
@@ -83,6 +88,84 @@ func (m *MevAPI) BestBidGasFee(_ context.Context, parentHash common.Hash) *big.I
 	return m.b.BestBidGasFee(parentHash)
 }
 
+// GetBidHistory returns the records of the bids received for the given block, explaining
+// the outcome of each bid.
+func (m *MevAPI) GetBidHistory(_ context.Context, blockNumber hexutil.Uint64) ([]*types.BidRecord, error) {
+	return m.b.BidHistory(uint64(blockNumber))
+}
+
+// GetBuilderReputation returns the reputation of the builder, including whether
+// the builder is penalized for sending bad bids.
+func (m *MevAPI) GetBuilderReputation(_ context.Context, builder common.Address) *types.BuilderReputation {
+	return m.b.BuilderReputation(builder)
+}
+
+// GetBlockDecision returns the packed rewards, gas used and tx counts of the local
+// block and the best bid for the in-turn block with the given number, explaining
+// which one the validator sealed.
+func (m *MevAPI) GetBlockDecision(_ context.Context, number hexutil.Uint64) *types.BlockDecision {
+	return m.b.BlockDecision(uint64(number))
+}
+
+// BidStatus subscribes to the status events of the bids of the builder, served as
+// mev_subscribe("bidStatus", builder, timestamp, signature) over WebSocket. The
+// builder signs types.BidStatusAuthHash at the current unix time in seconds.
+func (m *MevAPI) BidStatus(ctx context.Context, builder common.Address, timestamp hexutil.Uint64, signature hexutil.Bytes) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	auth, err := verifyBidStatusAuth(builder, uint64(timestamp), signature)
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+
+	statuses := make(chan *types.BidStatus, 128)
+	statusSub, err := m.b.SubscribeBidStatus(builder, auth, statuses)
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	gopool.Submit(func() {
+		defer statusSub.Unsubscribe()
+
+		for {
+			select {
+			case status := <-statuses:
+				notifier.Notify(rpcSub.ID, status)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	})
+
+	return rpcSub, nil
+}
+
+// verifyBidStatusAuth checks the signature of the builder subscribing to the status
+// of its bids, returning the signed hash. To prevent replays, the signed time must
+// be recent, and the subscription accepts the hash once only.
+func verifyBidStatusAuth(builder common.Address, timestamp uint64, signature []byte) (common.Hash, error) {
+	signed := time.Unix(int64(timestamp), 0)
+	if diff := time.Since(signed); diff > types.BidStatusAuthWindow || diff < -types.BidStatusAuthWindow {
+		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("signed time %v is off by %v", signed, common.PrettyDuration(diff)))
+	}
+	hash := types.BidStatusAuthHash(builder, timestamp)
+	pk, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("invalid signature: %v", err))
+	}
+	if crypto.PubkeyToAddress(*pk) != builder {
+		return common.Hash{}, types.NewInvalidBidError("signature does not match the builder")
+	}
+	return hash, nil
+}
+
 func (m *MevAPI) Params() *types.MevParams {
 	return m.b.MevParams()
 }
@@ -612,6 +612,9 @@ func (b testBackend) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.Su
 func (b testBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	panic("implement me")
 }
+func (b testBackend) SendBundle(ctx context.Context, bundle *types.Bundle) error {
+	panic("implement me")
+}
 func (b testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error) {
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
 	return true, tx, blockHash, blockNumber, index, nil
@@ -666,6 +669,18 @@ func (b *testBackend) BestBidGasFee(parentHash common.Hash) *big.Int {
 	//TODO implement me
 	panic("implement me")
 }
+func (b *testBackend) BidHistory(blockNumber uint64) ([]*types.BidRecord, error) {
+	panic("implement me")
+}
+func (b *testBackend) BuilderReputation(builder common.Address) *types.BuilderReputation {
+	panic("implement me")
+}
+func (b *testBackend) BlockDecision(number uint64) *types.BlockDecision {
+	panic("implement me")
+}
+func (b *testBackend) SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error) {
+	panic("implement me")
+}
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -2265,3 +2280,35 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 	}
 	require.JSONEqf(t, string(want), string(data), "test %d: json not match, want: %s, have: %s", testid, string(want), string(data))
 }
+
+func TestVerifyBidStatusAuth(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _  = crypto.GenerateKey()
+		builder = crypto.PubkeyToAddress(key.PublicKey)
+		now     = uint64(time.Now().Unix())
+	)
+	sign := func(builder common.Address, timestamp uint64) []byte {
+		sig, err := crypto.Sign(types.BidStatusAuthHash(builder, timestamp).Bytes(), key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return sig
+	}
+	if hash, err := verifyBidStatusAuth(builder, now, sign(builder, now)); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	} else if hash != types.BidStatusAuthHash(builder, now) {
+		t.Fatalf("auth hash mismatch: have %x", hash)
+	}
+	if _, err := verifyBidStatusAuth(common.Address{0x1}, now, sign(common.Address{0x1}, now)); err == nil {
+		t.Fatalf("signature of another builder accepted")
+	}
+	if _, err := verifyBidStatusAuth(builder, now, sign(builder, now-1)); err == nil {
+		t.Fatalf("signature of another time accepted")
+	}
+	stale := now - uint64(2*types.BidStatusAuthWindow/time.Second)
+	if _, err := verifyBidStatusAuth(builder, stale, sign(builder, stale)); err == nil {
+		t.Fatalf("stale signature accepted")
+	}
+}
@@ -78,6 +78,7 @@ type Backend interface {
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendBundle(ctx context.Context, bundle *types.Bundle) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
@@ -119,6 +120,15 @@ type Backend interface {
 	SendBid(ctx context.Context, bid *types.BidArgs) (common.Hash, error)
 	// BestBidGasFee returns the gas fee of the best bid for the given parent hash.
 	BestBidGasFee(parentHash common.Hash) *big.Int
+	// BidHistory returns the audit records of the bids received for the given block.
+	BidHistory(blockNumber uint64) ([]*types.BidRecord, error)
+	// BuilderReputation returns the reputation of the builder kept by the bid simulator.
+	BuilderReputation(builder common.Address) *types.BuilderReputation
+	// BlockDecision returns the choice between the local block and the best bid for the in-turn block.
+	BlockDecision(number uint64) *types.BlockDecision
+	// SubscribeBidStatus subscribes to the status events of the bids of the registered builder,
+	// authorized by the given hash signed by the builder. Each hash is accepted once only.
+	SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error)
 	// MinerInTurn returns true if the validator is in turn to propose the block.
 	MinerInTurn() bool
 }
@@ -388,6 +388,7 @@ func (b *backendMock) SubscribeNewVoteEvent(ch chan<- core.NewVoteEvent) event.S
 	return nil
 }
 func (b *backendMock) SendTx(ctx context.Context, signedTx *types.Transaction) error { return nil }
+func (b *backendMock) SendBundle(ctx context.Context, bundle *types.Bundle) error    { return nil }
 func (b *backendMock) GetTransaction(ctx context.Context, txHash common.Hash) (bool, *types.Transaction, common.Hash, uint64, uint64, error) {
 	return false, nil, [32]byte{}, 0, 0, nil
 }
@@ -431,3 +432,15 @@ func (b *backendMock) MinerInTurn() bool { return false }
 func (b *backendMock) BestBidGasFee(parentHash common.Hash) *big.Int {
 	panic("implement me")
 }
+func (b *backendMock) BidHistory(blockNumber uint64) ([]*types.BidRecord, error) {
+	panic("implement me")
+}
+func (b *backendMock) BuilderReputation(builder common.Address) *types.BuilderReputation {
+	panic("implement me")
+}
+func (b *backendMock) BlockDecision(number uint64) *types.BlockDecision {
+	panic("implement me")
+}
+func (b *backendMock) SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error) {
+	panic("implement me")
+}
@@ -0,0 +1,199 @@
+package miner
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// bidHistoryCacheBlocks is the number of recent blocks whose bid records are kept in memory
+	bidHistoryCacheBlocks = 1024
+
+	// bidLogMaxSize is the size in megabytes of the bid log before it gets rotated
+	bidLogMaxSize = 100
+	// bidLogMaxBackups is the number of rotated bid logs to retain
+	bidLogMaxBackups = 10
+
+	// bidLogBlockSlack is the number of blocks the records of a block may be written
+	// after the records of later blocks, e.g. when the outcome of a bid is known.
+	bidLogBlockSlack = 16
+)
+
+// bidHistory keeps the audit records of the received bids, the records of recent
+// blocks are cached in memory and all of them are appended to a rotating log file
+// as json lines, so that the outcome of a bid can be explained after the slot passes.
+type bidHistory struct {
+	path   string
+	writer *lumberjack.Logger // nil if the bid log is disabled
+
+	mu    sync.Mutex
+	cache *lru.Cache[uint64, []*types.BidRecord] // blockNumber -> records
+}
+
+// newBidHistory creates the bid history, the records are only kept in memory if path is empty.
+func newBidHistory(path string) *bidHistory {
+	h := &bidHistory{
+		path:  path,
+		cache: lru.NewCache[uint64, []*types.BidRecord](bidHistoryCacheBlocks),
+	}
+	if path != "" {
+		h.writer = &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    bidLogMaxSize,
+			MaxBackups: bidLogMaxBackups,
+		}
+	}
+	return h
+}
+
+// record adds the record into the history, replacing the former record of the same bid.
+func (h *bidHistory) record(record *types.BidRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records, _ := h.cache.Get(record.BlockNumber)
+	h.cache.Add(record.BlockNumber, mergeBidRecord(records, record))
+
+	if h.writer == nil {
+		return
+	}
+	blob, err := json.Marshal(record)
+	if err != nil {
+		log.Error("BidSimulator: failed to encode bid record", "bidHash", record.BidHash, "err", err)
+		return
+	}
+	if _, err := h.writer.Write(append(blob, '\n')); err != nil {
+		log.Error("BidSimulator: failed to write bid log", "path", h.path, "err", err)
+	}
+}
+
+// get returns the records of the bids received for the given block, the bid log is
+// searched if the block is not cached anymore.
+func (h *bidHistory) get(blockNumber uint64) ([]*types.BidRecord, error) {
+	h.mu.Lock()
+	records, ok := h.cache.Get(blockNumber)
+	h.mu.Unlock()
+
+	if ok || h.writer == nil {
+		return append([]*types.BidRecord{}, records...), nil
+	}
+	return readBidLog(h.path, blockNumber)
+}
+
+// close closes the bid log.
+func (h *bidHistory) close() error {
+	if h.writer == nil {
+		return nil
+	}
+	return h.writer.Close()
+}
+
+// mergeBidRecord adds the record into the records of a block, the later record of a
+// bid overrides the former one.
+func mergeBidRecord(records []*types.BidRecord, record *types.BidRecord) []*types.BidRecord {
+	for i, r := range records {
+		if r.BidHash == record.BidHash {
+			merged := append([]*types.BidRecord{}, records...)
+			merged[i] = record
+			return merged
+		}
+	}
+	return append(append([]*types.BidRecord{}, records...), record)
+}
+
+// readBidLog searches the rotated bid logs and the current one for the records of the
+// given block, in the order they are written. The records are written as the blocks
+// are built, so the logs are ordered by block number apart from bidLogBlockSlack.
+// Only the logs which may hold the block are scanned, up to the records past it.
+func readBidLog(path string, blockNumber uint64) ([]*types.BidRecord, error) {
+	// lumberjack names the rotated logs as <name>-<timestamp><ext> in the same directory
+	ext := filepath.Ext(path)
+	backups, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(backups)
+
+	var (
+		files  = append(backups, path)
+		firsts = make([]uint64, 0, len(files))
+		logs   = make([]string, 0, len(files))
+	)
+	for _, file := range files {
+		first, err := readBidLogFirstBlock(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		firsts, logs = append(firsts, first), append(logs, file)
+	}
+	records := []*types.BidRecord{}
+	for i, file := range logs {
+		if firsts[i] > blockNumber+bidLogBlockSlack {
+			break // this log and the later ones only hold later blocks
+		}
+		if i+1 < len(logs) && blockNumber > bidLogBlockSlack && blockNumber-bidLogBlockSlack > firsts[i+1] {
+			continue // the block is written after the next log starts
+		}
+		if err := scanBidLog(file, blockNumber, func(record *types.BidRecord) {
+			records = mergeBidRecord(records, record)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// readBidLogFirstBlock returns the block number of the first record in the bid
+// log, math.MaxUint64 if the log holds no record.
+func readBidLogFirstBlock(file string) (uint64, error) {
+	first := uint64(math.MaxUint64)
+	err := scanBidLog(file, math.MaxUint64, func(record *types.BidRecord) {
+		first = record.BlockNumber
+	})
+	return first, err
+}
+
+// scanBidLog calls fn with the records of the given block in the bid log, reading
+// up to the first record past the block and bidLogBlockSlack. fn is called with the
+// first record of the log if blockNumber is math.MaxUint64.
+func scanBidLog(file string, blockNumber uint64, fn func(record *types.BidRecord)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record := new(types.BidRecord)
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			continue // skip the line torn by a crash
+		}
+		if blockNumber == math.MaxUint64 {
+			fn(record)
+			return nil
+		}
+		if record.BlockNumber == blockNumber {
+			fn(record)
+		} else if record.BlockNumber > blockNumber+bidLogBlockSlack {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,102 @@
+package miner
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBidHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.log")
+	history := newBidHistory(path)
+
+	newRecord := func(number uint64, hash common.Hash, outcome string) *types.BidRecord {
+		return &types.BidRecord{
+			BlockNumber: number,
+			BidHash:     hash,
+			GasFee:      big.NewInt(100),
+			BuilderFee:  big.NewInt(1),
+			Outcome:     outcome,
+		}
+	}
+	history.record(newRecord(1, common.Hash{0x1}, types.BidOutcomeIgnored))
+	history.record(newRecord(1, common.Hash{0x2}, types.BidOutcomeBest))
+	history.record(newRecord(2, common.Hash{0x3}, types.BidOutcomeFailed))
+	history.record(newRecord(1, common.Hash{0x2}, types.BidOutcomeWon))
+
+	check := func(history *bidHistory, source string) {
+		records, err := history.get(1)
+		if err != nil {
+			t.Fatalf("%s: failed to get bid history: %v", source, err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("%s: record count mismatch: have %d, want 2", source, len(records))
+		}
+		if records[0].BidHash != (common.Hash{0x1}) || records[0].Outcome != types.BidOutcomeIgnored {
+			t.Fatalf("%s: record 0 mismatch: have %x %s", source, records[0].BidHash, records[0].Outcome)
+		}
+		if records[1].BidHash != (common.Hash{0x2}) || records[1].Outcome != types.BidOutcomeWon {
+			t.Fatalf("%s: record 1 mismatch: have %x %s", source, records[1].BidHash, records[1].Outcome)
+		}
+		if records[1].GasFee.Cmp(big.NewInt(100)) != 0 {
+			t.Fatalf("%s: gas fee mismatch: have %v, want 100", source, records[1].GasFee)
+		}
+		if records, _ := history.get(3); len(records) != 0 {
+			t.Fatalf("%s: unexpected records of unknown block: %d", source, len(records))
+		}
+	}
+	check(history, "cache")
+	if err := history.close(); err != nil {
+		t.Fatalf("failed to close bid history: %v", err)
+	}
+	// the records of the blocks not cached anymore are read from the bid log
+	reopened := newBidHistory(path)
+	defer reopened.close()
+	check(reopened, "log")
+}
+
+func TestBidLogScan(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bids.log")
+
+	var bids int
+	writeLog := func(file string, numbers ...uint64) {
+		var blob []byte
+		for _, number := range numbers {
+			bids++
+			record, _ := json.Marshal(&types.BidRecord{BlockNumber: number, BidHash: common.Hash{byte(bids)}})
+			blob = append(append(blob, record...), '\n')
+		}
+		if err := os.WriteFile(file, blob, 0644); err != nil {
+			t.Fatalf("failed to write bid log: %v", err)
+		}
+	}
+	writeLog(filepath.Join(dir, "bids-2024-01-01T00-00-00.000.log"), 1, 2, 3, 100, 1, 300)
+	writeLog(filepath.Join(dir, "bids-2024-01-02T00-00-00.000.log"), 200, 201, 300)
+	writeLog(path, 301, 302, 300)
+
+	for _, tt := range []struct {
+		number uint64
+		count  int
+	}{
+		{1, 1},   // the records written far past the block aren't scanned
+		{2, 1},   // in the first rotated log
+		{100, 1}, // in the first rotated log, the later ones start past the block
+		{300, 2}, // across the later logs, the first one isn't scanned
+		{150, 0},
+		{1000, 0},
+	} {
+		records, err := readBidLog(path, tt.number)
+		if err != nil {
+			t.Fatalf("block %d: failed to read bid log: %v", tt.number, err)
+		}
+		if len(records) != tt.count {
+			t.Fatalf("block %d: record count mismatch: have %d, want %d", tt.number, len(records), tt.count)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package miner
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	bidMergedTxsCounter     = metrics.NewRegisteredCounter("bid/merge/txs", nil)       // mempool txs merged into the bids
+	bidMergeConflictCounter = metrics.NewRegisteredCounter("bid/merge/conflicts", nil) // mempool txs skipped for touching the state of the bids
+)
+
+// errBidStateConflict is returned if a mempool transaction touches the state the
+// bid it is merged into depends on.
+var errBidStateConflict = errors.New("transaction conflicts with the bid")
+
+// bidAccessSet is the state read or written by the transactions of a bid, the
+// mempool transactions merged into the bid must stay clear of it.
+//
+// The accounts are tracked along with the storage slots accessed, an account
+// accessed without any slot is taken as the balance, nonce or code of the account
+// being depended on, which conflicts with any access to the account.
+type bidAccessSet struct {
+	ignored  map[common.Address]struct{}                 // accounts every transaction touches
+	accessed map[common.Address]map[common.Hash]struct{} // nil slots if the account itself is accessed
+}
+
+// newBidAccessSet creates the access set of a bid built in the given environment.
+// The fee recipients and the precompiles are accessed by all the transactions
+// without depending on each other, so they are not tracked.
+func newBidAccessSet(env *environment, chainConfig *params.ChainConfig) *bidAccessSet {
+	set := &bidAccessSet{
+		ignored: map[common.Address]struct{}{
+			env.coinbase:            {},
+			consensus.SystemAddress: {},
+		},
+		accessed: make(map[common.Address]map[common.Hash]struct{}),
+	}
+	rules := chainConfig.Rules(env.header.Number, false, env.header.Time)
+	for _, addr := range vm.ActivePrecompiles(rules) {
+		set.ignored[addr] = struct{}{}
+	}
+	return set
+}
+
+// add adds the state accessed by a transaction of the bid.
+func (s *bidAccessSet) add(list types.AccessList) {
+	for _, tuple := range list {
+		if _, ok := s.ignored[tuple.Address]; ok {
+			continue
+		}
+		slots, ok := s.accessed[tuple.Address]
+		switch {
+		case ok && slots == nil:
+			// the account is accessed as a whole already
+		case len(tuple.StorageKeys) == 0:
+			s.accessed[tuple.Address] = nil
+		default:
+			if !ok {
+				slots = make(map[common.Hash]struct{})
+				s.accessed[tuple.Address] = slots
+			}
+			for _, key := range tuple.StorageKeys {
+				slots[key] = struct{}{}
+			}
+		}
+	}
+}
+
+// addAccount adds an account the bid depends on as a whole.
+func (s *bidAccessSet) addAccount(addr common.Address) {
+	s.add(types.AccessList{{Address: addr}})
+}
+
+// conflicts returns true if the state accessed by a transaction overlaps with the
+// state accessed by the bid.
+func (s *bidAccessSet) conflicts(list types.AccessList) bool {
+	for _, tuple := range list {
+		if _, ok := s.ignored[tuple.Address]; ok {
+			continue
+		}
+		slots, ok := s.accessed[tuple.Address]
+		if !ok {
+			continue
+		}
+		if slots == nil || len(tuple.StorageKeys) == 0 {
+			return true
+		}
+		for _, key := range tuple.StorageKeys {
+			if _, ok := slots[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check rejects the transaction just executed on the state if it conflicts with
+// the bid.
+func (s *bidAccessSet) check(statedb *state.StateDB) error {
+	if s.conflicts(statedb.AccessList()) {
+		return errBidStateConflict
+	}
+	return nil
+}
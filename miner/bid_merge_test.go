@@ -0,0 +1,108 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBidAccessSetConflicts(t *testing.T) {
+	var (
+		coinbase = common.Address{0xc0}
+		token    = common.Address{0x1}
+		user     = common.Address{0x2}
+		set      = &bidAccessSet{
+			ignored:  map[common.Address]struct{}{coinbase: {}},
+			accessed: make(map[common.Address]map[common.Hash]struct{}),
+		}
+	)
+	set.add(types.AccessList{
+		{Address: coinbase},
+		{Address: user},
+		{Address: token, StorageKeys: []common.Hash{{0x1}}},
+	})
+
+	tests := []struct {
+		name string
+		list types.AccessList
+		want bool
+	}{
+		{"disjoint", types.AccessList{{Address: common.Address{0x3}}}, false},
+		{"fee recipient", types.AccessList{{Address: coinbase}}, false},
+		{"other slot", types.AccessList{{Address: token, StorageKeys: []common.Hash{{0x2}}}}, false},
+		{"same slot", types.AccessList{{Address: token, StorageKeys: []common.Hash{{0x2}, {0x1}}}}, true},
+		{"whole contract", types.AccessList{{Address: token}}, true},
+		{"account", types.AccessList{{Address: user, StorageKeys: []common.Hash{{0x1}}}}, true},
+	}
+	for _, tt := range tests {
+		if have := set.conflicts(tt.list); have != tt.want {
+			t.Errorf("%s: conflict mismatch: have %v, want %v", tt.name, have, tt.want)
+		}
+	}
+
+	// an account accessed as a whole is never narrowed down to slots
+	set.addAccount(token)
+	if !set.conflicts(types.AccessList{{Address: token, StorageKeys: []common.Hash{{0x2}}}}) {
+		t.Errorf("account accessed as a whole: slot access should conflict")
+	}
+}
+
+func TestMergeTxsIntoBid(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	newEnv := func(accessed ...common.Address) *environment {
+		env, err := w.prepareWork(&generateParams{
+			parentHash: b.chain.CurrentBlock().Hash(),
+			timestamp:  uint64(time.Now().Unix()),
+			coinbase:   testBankAddress,
+			forceTime:  true,
+		})
+		if err != nil {
+			t.Fatalf("failed to prepare work: %v", err)
+		}
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit - params.SystemTxsGas)
+		env.accessed = newBidAccessSet(env, ethashChainConfig)
+		for _, addr := range accessed {
+			env.accessed.addAccount(addr)
+		}
+		return env
+	}
+
+	if err := b.txPool.Sync(); err != nil {
+		t.Fatalf("failed to sync txpool: %v", err)
+	}
+
+	// The pending transaction touches nothing of the bid
+	env := newEnv(common.Address{0x1})
+	defer env.discard()
+	if err := w.fillTransactions(nil, env, nil, nil); err != nil {
+		t.Fatalf("failed to fill transactions: %v", err)
+	}
+	if len(env.txs) != 1 || env.header.GasUsed != params.TxGas {
+		t.Fatalf("disjoint transaction not merged: txs %d, gas used %d", len(env.txs), env.header.GasUsed)
+	}
+
+	// The pending transaction pays the account the bid depends on
+	env = newEnv(testUserAddress)
+	defer env.discard()
+	gas := env.gasPool.Gas()
+	if err := w.fillTransactions(nil, env, nil, nil); err != nil {
+		t.Fatalf("failed to fill transactions: %v", err)
+	}
+	if len(env.txs) != 0 || env.header.GasUsed != 0 || env.gasPool.Gas() != gas {
+		t.Fatalf("conflicting transaction merged: txs %d, gas used %d", len(env.txs), env.header.GasUsed)
+	}
+	if balance := env.state.GetBalance(testUserAddress); !balance.IsZero() {
+		t.Fatalf("conflicting transaction not reverted: balance %v", balance)
+	}
+}
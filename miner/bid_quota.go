@@ -0,0 +1,63 @@
+package miner
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var bidRateLimitedCounter = metrics.NewRegisteredCounter("bid/ratelimited", nil)
+
+// builderQuota bounds the bids a builder may send, it is checked before decoding
+// the transactions of the bid so that a misbehaving builder is rejected cheaply.
+type builderQuota struct {
+	limiter         *rate.Limiter // nil if the bid rate is not limited
+	maxBidsPerBlock uint64
+}
+
+// newBuilderQuota creates the quota of the builder, the limits configured for the
+// builder take precedence over the global ones.
+func newBuilderQuota(config *MevConfig, builder common.Address) *builderQuota {
+	var (
+		maxBidsPerBlock = config.MaxBidsPerBlock
+		bidRate         = config.BidRateLimit
+		burst           = config.BidRateBurst
+	)
+	for _, v := range config.Builders {
+		if v.Address != builder {
+			continue
+		}
+		if v.MaxBidsPerBlock != 0 {
+			maxBidsPerBlock = v.MaxBidsPerBlock
+		}
+		if v.BidRateLimit != 0 {
+			bidRate = v.BidRateLimit
+		}
+		break
+	}
+	if maxBidsPerBlock == 0 {
+		maxBidsPerBlock = defaultMaxBidsPerBlock
+	}
+
+	quota := &builderQuota{maxBidsPerBlock: maxBidsPerBlock}
+	if bidRate > 0 {
+		if burst <= 0 {
+			burst = int(maxBidsPerBlock)
+		}
+		quota.limiter = rate.NewLimiter(rate.Limit(bidRate), burst)
+	}
+	return quota
+}
+
+// allow reports whether the builder may send a bid at the given time, consuming a
+// token if so.
+func (q *builderQuota) allow(now time.Time) bool {
+	if q.limiter == nil || q.limiter.AllowN(now, 1) {
+		return true
+	}
+	bidRateLimitedCounter.Inc(1)
+	return false
+}
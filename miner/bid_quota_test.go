@@ -0,0 +1,53 @@
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBuilderQuota(t *testing.T) {
+	var (
+		builder1 = common.Address{0x1}
+		builder2 = common.Address{0x2}
+		config   = &MevConfig{
+			MaxBidsPerBlock: 2,
+			BidRateLimit:    0.001,
+			Builders: []BuilderConfig{
+				{Address: builder1, MaxBidsPerBlock: 5, BidRateLimit: 1000},
+			},
+		}
+	)
+
+	// The builder config overrides the global limits
+	quota := newBuilderQuota(config, builder1)
+	if quota.maxBidsPerBlock != 5 {
+		t.Fatalf("max bids per block mismatch: have %d, want 5", quota.maxBidsPerBlock)
+	}
+	for i := 0; i < 5; i++ {
+		if !quota.allow(time.Now()) {
+			t.Fatalf("bid %d rate limited", i)
+		}
+	}
+
+	// The burst defaults to the max bids per block
+	quota = newBuilderQuota(config, builder2)
+	if quota.maxBidsPerBlock != 2 {
+		t.Fatalf("max bids per block mismatch: have %d, want 2", quota.maxBidsPerBlock)
+	}
+	for i := 0; i < 2; i++ {
+		if !quota.allow(time.Now()) {
+			t.Fatalf("bid %d rate limited", i)
+		}
+	}
+	if quota.allow(time.Now()) {
+		t.Fatalf("bid beyond the burst allowed")
+	}
+
+	// No limit if not configured
+	quota = newBuilderQuota(&MevConfig{}, builder2)
+	if quota.limiter != nil || quota.maxBidsPerBlock != defaultMaxBidsPerBlock {
+		t.Fatalf("unexpected default quota: %+v", quota)
+	}
+}
@@ -0,0 +1,325 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bidutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/systemcontracts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner/builderclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// defaultReplayBlockPeriod is the block period used to replay the bids of a chain
+// without a period in its consensus config.
+const defaultReplayBlockPeriod = 3
+
+// ReplayBid is a recorded bid to replay.
+type ReplayBid struct {
+	// Arrival is the time in milliseconds the bid arrives after the parent block timestamp
+	Arrival uint64 `json:"arrival"`
+	// SimulationTime is the time in milliseconds the simulation of the bid takes
+	SimulationTime uint64 `json:"simulationTime"`
+	// Bid is the bid as sent to mev_sendBid
+	Bid *types.BidArgs `json:"bid"`
+}
+
+// ReplayResult is the outcome of the replayed bids of a block.
+type ReplayResult struct {
+	BlockNumber           uint64             `json:"blockNumber"`
+	ParentHash            common.Hash        `json:"parentHash"`
+	BestBid               *common.Hash       `json:"bestBid,omitempty"` // nil if no bid is valid
+	Builder               *common.Address    `json:"builder,omitempty"`
+	PackedBlockReward     *big.Int           `json:"packedBlockReward,omitempty"`
+	PackedValidatorReward *big.Int           `json:"packedValidatorReward,omitempty"`
+	Bids                  []*types.BidRecord `json:"bids"` // outcome of the bids in the order of arrival
+}
+
+// ReplayBids replays the recorded bids through the bid simulator on top of the chain,
+// returning the best bid of each block and the outcome of every bid.
+//
+// The bids go through the admission checks and the scheduling of the production in
+// the order of arrival, on a virtual clock starting at the parent block timestamp.
+// Up to MaxSimulatingBids bids are in simulation at once, each taking its recorded
+// simulation time, so that the quotas and deadlines apply deterministically. The
+// builders of the bids are registered with the configured quotas.
+func ReplayBids(chain *core.BlockChain, config *Config, bids []*ReplayBid) ([]*ReplayResult, error) {
+	var (
+		worker = &replayWorker{chain: chain, chainConfig: chain.Config(), gasCeil: config.GasCeil, coinbase: config.Etherbase}
+		b      = newReplaySimulator(config, chain, worker)
+		blocks = make(map[common.Hash][]*ReplayBid)
+	)
+	defer b.history.close()
+
+	for _, bid := range bids {
+		if bid.Bid == nil || bid.Bid.RawBid == nil {
+			return nil, errors.New("missing raw bid")
+		}
+		parentHash := bid.Bid.RawBid.ParentHash
+		blocks[parentHash] = append(blocks[parentHash], bid)
+	}
+
+	// the blocks are replayed in order, the clock of the quotas only moves forward
+	parents := make([]*types.Header, 0, len(blocks))
+	for parentHash := range blocks {
+		parent := chain.GetHeaderByHash(parentHash)
+		if parent == nil {
+			return nil, fmt.Errorf("missing parent %x", parentHash)
+		}
+		parents = append(parents, parent)
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return parents[i].Number.Cmp(parents[j].Number) < 0
+	})
+
+	results := make([]*ReplayResult, 0, len(parents))
+	for _, parent := range parents {
+		result, err := b.replayBlock(parent, blocks[parent.Hash()], worker.period())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// newReplaySimulator creates a bid simulator replaying the bids synchronously, with
+// neither the background loops nor the persistent logs.
+func newReplaySimulator(config *Config, chain *core.BlockChain, worker bidWorker) *bidSimulator {
+	mev := config.Mev
+	mev.GreedyMergeTx = false
+	mev.BidLogFile = ""
+	mev.BuilderReputationFile = ""
+
+	b := &bidSimulator{
+		config:        &mev,
+		delayLeftOver: config.DelayLeftOver,
+		chain:         chain,
+		chainConfig:   chain.Config(),
+		engine:        chain.Engine(),
+		bidWorker:     worker,
+		exitCh:        make(chan struct{}),
+		builders:      make(map[common.Address]*builderclient.Client),
+		quotas:        make(map[common.Address]*builderQuota),
+		pending:       make(map[uint64]map[common.Address]map[common.Hash]struct{}),
+		bestBid:       make(map[common.Hash]*BidRuntime),
+		simulatingBid: make(map[common.Hash]map[common.Hash]*simBidReq),
+		history:       newBidHistory(""),
+		reputations:   newBuilderReputations(mev.BuilderPolicy, ""),
+	}
+	b.running.Store(true)
+	b.bidReceiving.Store(true)
+	return b
+}
+
+// replaySimulation is a bid in simulation on the virtual clock of the replay.
+type replaySimulation struct {
+	req  *simBidReq
+	done time.Duration // the time the simulation completes after the parent block timestamp
+}
+
+// replayBlock replays the bids of the block built on top of the parent.
+func (b *bidSimulator) replayBlock(parent *types.Header, bids []*ReplayBid, period uint64) (*ReplayResult, error) {
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Arrival < bids[j].Arrival
+	})
+
+	var (
+		parentTime   = time.Unix(int64(parent.Time), 0)
+		mustBefore   = bidutil.BidMustBefore(parent, period, b.delayLeftOver)
+		betterBefore = bidutil.BidBetterBefore(parent, period, b.delayLeftOver, b.config.BidSimulationLeftOver)
+		timings      = make(map[common.Hash]*ReplayBid)
+		simulating   []*replaySimulation
+	)
+	// complete runs the simulations completed by the given time in the order of
+	// completion, the interrupted ones stop at their first transaction
+	complete := func(until time.Duration) {
+		sort.SliceStable(simulating, func(i, j int) bool {
+			return simulating[i].done < simulating[j].done
+		})
+		for len(simulating) > 0 && simulating[0].done <= until {
+			bidRuntime := simulating[0].req.bid
+			b.simBid(simulating[0].req.interruptCh, bidRuntime)
+			bidRuntime.duration = time.Duration(timings[bidRuntime.bid.Hash()].SimulationTime) * time.Millisecond
+			simulating = simulating[1:]
+		}
+	}
+	for _, replay := range bids {
+		var (
+			args    = replay.Bid
+			rawBid  = args.RawBid
+			arrival = time.Duration(replay.Arrival) * time.Millisecond
+			now     = parentTime.Add(arrival)
+		)
+		// a duplicated bid would replace the record of the original one
+		if _, ok := timings[rawBid.Hash()]; ok {
+			continue
+		}
+		timings[rawBid.Hash()] = replay
+		complete(arrival)
+
+		reject := func(builder common.Address, err error) {
+			b.history.record(&types.BidRecord{
+				BlockNumber: rawBid.BlockNumber,
+				ParentHash:  rawBid.ParentHash,
+				Builder:     builder,
+				BidHash:     rawBid.Hash(),
+				GasUsed:     rawBid.GasUsed,
+				GasFee:      rawBid.GasFee,
+				BuilderFee:  rawBid.BuilderFee,
+				Outcome:     types.BidOutcomeIgnored,
+				Error:       err.Error(),
+			})
+		}
+		builder, err := args.EcrecoverSender()
+		if err != nil {
+			reject(common.Address{}, fmt.Errorf("invalid signature: %v", err))
+			continue
+		}
+		if !b.ExistBuilder(builder) {
+			if err := b.AddBuilder(builder, ""); err != nil {
+				return nil, err
+			}
+		}
+		bid, err := b.admitBid(builder, args, now, betterBefore)
+		if err != nil {
+			reject(builder, err)
+			continue
+		}
+		b.AddPending(bid.BlockNumber, bid.Builder, bid.Hash())
+
+		bidRuntime := newBidRuntime(bid, b.config.ValidatorCommission)
+		req, err := b.scheduleBid(bidRuntime, now, mustBefore)
+		if err != nil {
+			b.recordBid(bidRuntime, types.BidOutcomeIgnored, 0, err)
+			continue
+		}
+		simulating = append(simulating, &replaySimulation{
+			req:  req,
+			done: arrival + time.Duration(replay.SimulationTime)*time.Millisecond,
+		})
+	}
+	complete(math.MaxInt64)
+
+	result := &ReplayResult{
+		BlockNumber: parent.Number.Uint64() + 1,
+		ParentHash:  parent.Hash(),
+	}
+	bestBid := b.GetBestBid(parent.Hash())
+	if bestBid != nil {
+		hash, builder := bestBid.bid.Hash(), bestBid.bid.Builder
+		result.BestBid = &hash
+		result.Builder = &builder
+		result.PackedBlockReward = bestBid.packedBlockReward
+		result.PackedValidatorReward = bestBid.packedValidatorReward
+		bestBid.env.discard()
+	}
+
+	records, err := b.history.get(result.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.ParentHash != parent.Hash() {
+			continue
+		}
+		// the timing of the replay is virtual, the former best bids are beaten later
+		replay := timings[record.BidHash]
+		record.Time = parent.Time*1000 + replay.Arrival
+		if record.Outcome != types.BidOutcomeIgnored {
+			record.SimulationTime = time.Duration(replay.SimulationTime) * time.Millisecond
+		}
+		if record.Outcome == types.BidOutcomeBest && (bestBid == nil || record.BidHash != bestBid.bid.Hash()) {
+			record.Outcome = types.BidOutcomeSimulated
+			record.Error = "beaten by a later bid"
+		}
+		result.Bids = append(result.Bids, record)
+	}
+	// the bids simulated concurrently may complete out of the order of arrival
+	sort.SliceStable(result.Bids, func(i, j int) bool {
+		return result.Bids[i].Time < result.Bids[j].Time
+	})
+	return result, nil
+}
+
+// replayWorker prepares the environments to replay the bids on, the header of the
+// block is taken from the chain if it is already sealed so that the replay matches
+// the production.
+type replayWorker struct {
+	chain       *core.BlockChain
+	chainConfig *params.ChainConfig
+	gasCeil     uint64
+	coinbase    common.Address
+}
+
+// period returns the block period of the chain.
+func (w *replayWorker) period() uint64 {
+	switch {
+	case w.chainConfig.Parlia != nil:
+		return w.chainConfig.Parlia.Period
+	case w.chainConfig.Clique != nil:
+		return w.chainConfig.Clique.Period
+	default:
+		return defaultReplayBlockPeriod
+	}
+}
+
+func (w *replayWorker) prepareWork(params *generateParams) (*environment, error) {
+	parent := w.chain.GetHeaderByHash(params.parentHash)
+	if parent == nil {
+		return nil, errors.New("missing parent")
+	}
+	var header *types.Header
+	if sealed := w.chain.GetHeaderByNumber(parent.Number.Uint64() + 1); sealed != nil && sealed.ParentHash == parent.Hash() {
+		header = types.CopyHeader(sealed)
+		header.GasUsed = 0
+		if header.BlobGasUsed != nil {
+			header.BlobGasUsed = new(uint64)
+		}
+	} else {
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   core.CalcGasLimit(parent.GasLimit, w.gasCeil),
+			Time:       parent.Time + w.period(),
+			Coinbase:   w.coinbase,
+			Difficulty: new(big.Int).Set(diffInTurn),
+		}
+		if w.chainConfig.IsLondon(header.Number) {
+			header.BaseFee = eip1559.CalcBaseFee(w.chainConfig, parent)
+		}
+	}
+	state, err := w.chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	env := &environment{
+		signer:   types.MakeSigner(w.chainConfig, header.Number, header.Time),
+		state:    state,
+		coinbase: header.Coinbase,
+		header:   header,
+	}
+	if !w.chainConfig.IsFeynman(header.Number, header.Time) {
+		systemcontracts.UpgradeBuildInSystemContract(w.chainConfig, header.Number, parent.Time, header.Time, env.state)
+	}
+	return env, nil
+}
+
+func (w *replayWorker) etherbase() common.Address {
+	return w.coinbase
+}
+
+// fillTransactions does nothing, the replay has no mempool to merge.
+func (w *replayWorker) fillTransactions(interruptCh chan int32, env *environment, stopTimer *time.Timer, bidTxs mapset.Set[common.Hash]) error {
+	return nil
+}
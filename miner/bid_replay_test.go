@@ -0,0 +1,186 @@
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newReplayBid creates a bid of the builder on top of the parent, transferring from
+// the test bank with the given gas price.
+func newReplayBid(t *testing.T, parent *types.Header, builderKey *ecdsa.PrivateKey, gasFee int64, gasPrice int64) *types.BidArgs {
+	signer := types.LatestSigner(ethashChainConfig)
+	tx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    0,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(gasPrice),
+	})
+	payBidTx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    1,
+		To:       &testUserAddress,
+		Value:    big.NewInt(0),
+		Gas:      params.PayBidTxGasLimit,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+	txBytes, _ := tx.MarshalBinary()
+	payBidTxBytes, _ := payBidTx.MarshalBinary()
+	rawBid := &types.RawBid{
+		BlockNumber: parent.Number.Uint64() + 1,
+		ParentHash:  parent.Hash(),
+		Txs:         []hexutil.Bytes{txBytes},
+		GasUsed:     params.TxGas,
+		GasFee:      big.NewInt(gasFee),
+		BuilderFee:  big.NewInt(0),
+	}
+	sig, err := crypto.Sign(rawBid.Hash().Bytes(), builderKey)
+	if err != nil {
+		t.Fatalf("failed to sign bid: %v", err)
+	}
+	return &types.BidArgs{RawBid: rawBid, Signature: sig, PayBidTx: payBidTxBytes, PayBidTxGasUsed: params.TxGas}
+}
+
+func TestReplayBids(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	var (
+		backend       = newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		parent        = backend.chain.CurrentBlock()
+		builderKey, _ = crypto.GenerateKey()
+		config        = &Config{
+			GasCeil:       params.GenesisGasLimit,
+			Etherbase:     consensus.SystemAddress, // collect the fees like parlia does
+			DelayLeftOver: 50 * time.Millisecond,
+			Mev:           DefaultMevConfig,
+		}
+	)
+	newBid := func(gasFee int64, gasPrice int64) *types.BidArgs {
+		return newReplayBid(t, parent, builderKey, gasFee, gasPrice)
+	}
+
+	var (
+		best      = newBid(1e13, 2*params.InitialBaseFee)
+		lower     = newBid(5e12, 3*params.InitialBaseFee)
+		shortfall = newBid(1e18, 4*params.InitialBaseFee)
+		late      = newBid(1e14, 5*params.InitialBaseFee)
+	)
+	results, err := ReplayBids(backend.chain, config, []*ReplayBid{
+		{Arrival: 10000, SimulationTime: 100, Bid: late},
+		{Arrival: 300, SimulationTime: 100, Bid: shortfall},
+		{Arrival: 100, SimulationTime: 100, Bid: best},
+		{Arrival: 200, SimulationTime: 100, Bid: lower},
+	})
+	if err != nil {
+		t.Fatalf("failed to replay bids: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("result count mismatch: have %d, want 1", len(results))
+	}
+	result := results[0]
+	if result.BestBid == nil || *result.BestBid != best.RawBid.Hash() {
+		t.Fatalf("best bid mismatch: have %v, want %x", result.BestBid, best.RawBid.Hash())
+	}
+
+	// The bids are reported in the order of arrival with the virtual timing
+	want := []struct {
+		hash    common.Hash
+		outcome string
+		err     string
+	}{
+		{best.RawBid.Hash(), types.BidOutcomeBest, ""},
+		{lower.RawBid.Hash(), types.BidOutcomeIgnored, errLowerThanBestBid.Error()},
+		{shortfall.RawBid.Hash(), types.BidOutcomeFailed, errRewardShortfall.Error()},
+		{late.RawBid.Hash(), types.BidOutcomeIgnored, ""},
+	}
+	if len(result.Bids) != len(want) {
+		t.Fatalf("bid record count mismatch: have %d, want %d", len(result.Bids), len(want))
+	}
+	for i, record := range result.Bids {
+		if record.BidHash != want[i].hash || record.Outcome != want[i].outcome {
+			t.Errorf("bid %d: have %x %s, want %x %s", i, record.BidHash, record.Outcome, want[i].hash, want[i].outcome)
+		}
+		if want[i].err != "" && record.Error != want[i].err {
+			t.Errorf("bid %d: error mismatch: have %q, want %q", i, record.Error, want[i].err)
+		}
+	}
+	if result.Bids[0].SimulationTime != 100*time.Millisecond {
+		t.Errorf("simulation time mismatch: have %v, want %v", result.Bids[0].SimulationTime, 100*time.Millisecond)
+	}
+}
+
+// TestReplayBidsProductionPath checks the replay goes through the admission checks
+// and the scheduling of the production.
+func TestReplayBidsProductionPath(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	var (
+		backend        = newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+		parent         = backend.chain.CurrentBlock()
+		builderKey, _  = crypto.GenerateKey()
+		limitedKey, _  = crypto.GenerateKey()
+		limitedBuilder = crypto.PubkeyToAddress(limitedKey.PublicKey)
+		config         = &Config{
+			GasCeil:       params.GenesisGasLimit,
+			Etherbase:     consensus.SystemAddress,
+			DelayLeftOver: 50 * time.Millisecond,
+			Mev:           DefaultMevConfig,
+		}
+	)
+	// a single bid is simulated at once, the limited builder may send one bid
+	config.Mev.MaxSimulatingBids = 1
+	config.Mev.Builders = []BuilderConfig{{Address: limitedBuilder, BidRateLimit: 0.001}}
+	config.Mev.BidRateBurst = 1
+
+	var (
+		slow    = newReplayBid(t, parent, builderKey, 1e13, 2*params.InitialBaseFee)
+		better  = newReplayBid(t, parent, builderKey, 2e13, 3*params.InitialBaseFee)
+		first   = newReplayBid(t, parent, limitedKey, 1e12, 4*params.InitialBaseFee)
+		limited = newReplayBid(t, parent, limitedKey, 3e13, 5*params.InitialBaseFee)
+	)
+	results, err := ReplayBids(backend.chain, config, []*ReplayBid{
+		{Arrival: 100, SimulationTime: 500, Bid: slow},
+		{Arrival: 200, SimulationTime: 100, Bid: better},
+		{Arrival: 400, SimulationTime: 100, Bid: first},
+		{Arrival: 500, SimulationTime: 100, Bid: limited},
+	})
+	if err != nil {
+		t.Fatalf("failed to replay bids: %v", err)
+	}
+	result := results[0]
+	if result.BestBid == nil || *result.BestBid != better.RawBid.Hash() {
+		t.Fatalf("best bid mismatch: have %v, want %x", result.BestBid, better.RawBid.Hash())
+	}
+	want := []struct {
+		hash    common.Hash
+		outcome string
+		err     string
+	}{
+		// the slow bid is evicted by the better one for the only simulation slot
+		{slow.RawBid.Hash(), types.BidOutcomeInterrupted, errBetterBidArrived.Error()},
+		{better.RawBid.Hash(), types.BidOutcomeBest, ""},
+		{first.RawBid.Hash(), types.BidOutcomeIgnored, errLowerThanBestBid.Error()},
+		{limited.RawBid.Hash(), types.BidOutcomeIgnored, types.ErrBidRateLimited.Error()},
+	}
+	if len(result.Bids) != len(want) {
+		t.Fatalf("bid record count mismatch: have %d, want %d", len(result.Bids), len(want))
+	}
+	for i, record := range result.Bids {
+		if record.BidHash != want[i].hash || record.Outcome != want[i].outcome || record.Error != want[i].err {
+			t.Errorf("bid %d: have %x %s %q, want %x %s %q", i, record.BidHash, record.Outcome, record.Error,
+				want[i].hash, want[i].outcome, want[i].err)
+		}
+	}
+}
@@ -27,10 +27,14 @@ import (
 )
 
 const (
-	// maxBidPerBuilderPerBlock is the max bid number per builder
-	maxBidPerBuilderPerBlock = 3
+	// defaultMaxBidsPerBlock is the max bid number per builder per block if not configured
+	defaultMaxBidsPerBlock = 3
 
-	commitInterruptBetterBid = 1
+	commitInterruptBetterBid  = 1
+	commitInterruptBidNewHead = 2
+
+	// defaultMaxSimulatingBids is the number of bids simulated concurrently if not configured
+	defaultMaxSimulatingBids = 3
 
 	// leftOverTimeRate is the rate of left over time to simulate a bid
 	leftOverTimeRate = 11
@@ -40,6 +44,24 @@ const (
 
 var (
 	bidSimTimer = metrics.NewRegisteredTimer("bid/sim/duration", nil)
+
+	bidSimulatedCounter = metrics.NewRegisteredCounter("bid/sim/simulated", nil) // bids put into simulation
+	bidCompletedCounter = metrics.NewRegisteredCounter("bid/sim/completed", nil) // bids fully simulated with a valid reward
+	bidDiscardedCounter = metrics.NewRegisteredCounter("bid/sim/discarded", nil) // bids ignored or interrupted before completion
+)
+
+var (
+	errBetterBidArrived = errors.New("simulation abort due to better bid arrived")
+	errNewHeadArrived   = errors.New("simulation abort due to new head arrived")
+	errMinerExit        = errors.New("miner exit")
+
+	errNegativeValidatorReward = errors.New("validator reward is less than 0")
+	errLowerThanBestBid        = errors.New("lower reward than the best bid")
+	errNotEnoughSimulationTime = errors.New("not enough time to simulate")
+
+	errGasUsedExceedsLimit = errors.New("gas used exceeds gas limit")
+	errInvalidTxInBid      = errors.New("invalid tx in bid")
+	errRewardShortfall     = errors.New("reward does not achieve the expectation")
 )
 
 var (
@@ -73,6 +95,16 @@ type bidWorker interface {
 type simBidReq struct {
 	bid         *BidRuntime
 	interruptCh chan int32
+	interrupted sync.Once
+}
+
+// interrupt aborts the simulation of the bid with the given reason, it is safe
+// to be called several times.
+func (req *simBidReq) interrupt(reason int32) {
+	req.interrupted.Do(func() {
+		req.interruptCh <- reason
+		close(req.interruptCh)
+	})
 }
 
 // bidSimulator is in charge of receiving bid from builders, reporting issue to builders.
@@ -98,6 +130,7 @@ type bidSimulator struct {
 	// builder info (warning: only keep status in memory!)
 	buildersMu sync.RWMutex
 	builders   map[common.Address]*builderclient.Client
+	quotas     map[common.Address]*builderQuota
 
 	// channels
 	simBidCh chan *simBidReq
@@ -110,7 +143,12 @@ type bidSimulator struct {
 	bestBid   map[common.Hash]*BidRuntime // prevBlockHash -> bidRuntime
 
 	simBidMu      sync.RWMutex
-	simulatingBid map[common.Hash]*BidRuntime // prevBlockHash -> bidRuntime, in the process of simulation
+	simulatingBid map[common.Hash]map[common.Hash]*simBidReq // prevBlockHash -> bidHash -> simBidReq, in the process of simulation
+
+	history     *bidHistory         // audit records of the received bids
+	reputations *builderReputations // track records of the builders
+	statusFeed  bidStatusFeed       // status events of the bids for the builders
+	statusAuths bidStatusAuths      // auth hashes used to subscribe to the status events
 }
 
 func newBidSimulator(
@@ -131,11 +169,14 @@ func newBidSimulator(
 		exitCh:        make(chan struct{}),
 		chainHeadCh:   make(chan core.ChainHeadEvent, chainHeadChanSize),
 		builders:      make(map[common.Address]*builderclient.Client),
+		quotas:        make(map[common.Address]*builderQuota),
 		simBidCh:      make(chan *simBidReq),
 		newBidCh:      make(chan *types.Bid, 100),
 		pending:       make(map[uint64]map[common.Address]map[common.Hash]struct{}),
 		bestBid:       make(map[common.Hash]*BidRuntime),
-		simulatingBid: make(map[common.Hash]*BidRuntime),
+		simulatingBid: make(map[common.Hash]map[common.Hash]*simBidReq),
+		history:       newBidHistory(config.BidLogFile),
+		reputations:   newBuilderReputations(config.BuilderPolicy, config.BuilderReputationFile),
 	}
 
 	b.chainHeadSub = chain.SubscribeChainHeadEvent(b.chainHeadCh)
@@ -185,6 +226,11 @@ func (b *bidSimulator) stop() {
 func (b *bidSimulator) close() {
 	b.running.Store(false)
 	close(b.exitCh)
+
+	if err := b.history.close(); err != nil {
+		log.Error("BidSimulator: failed to close bid log", "err", err)
+	}
+	b.reputations.close()
 }
 
 func (b *bidSimulator) isRunning() bool {
@@ -225,6 +271,7 @@ func (b *bidSimulator) AddBuilder(builder common.Address, url string) error {
 
 		b.builders[builder] = builderCli
 	}
+	b.quotas[builder] = newBuilderQuota(b.config, builder)
 
 	return nil
 }
@@ -234,6 +281,7 @@ func (b *bidSimulator) RemoveBuilder(builder common.Address) error {
 	defer b.buildersMu.Unlock()
 
 	delete(b.builders, builder)
+	delete(b.quotas, builder)
 
 	return nil
 }
@@ -247,6 +295,27 @@ func (b *bidSimulator) ExistBuilder(builder common.Address) bool {
 	return ok
 }
 
+// AllowBid reports whether the rate limit of the builder allows a new bid at the
+// given time.
+func (b *bidSimulator) AllowBid(builder common.Address, now time.Time) bool {
+	b.buildersMu.RLock()
+	quota, ok := b.quotas[builder]
+	b.buildersMu.RUnlock()
+
+	return ok && quota.allow(now)
+}
+
+// maxBidsPerBlock returns the max bid number of the builder per block.
+func (b *bidSimulator) maxBidsPerBlock(builder common.Address) uint64 {
+	b.buildersMu.RLock()
+	defer b.buildersMu.RUnlock()
+
+	if quota, ok := b.quotas[builder]; ok {
+		return quota.maxBidsPerBlock
+	}
+	return defaultMaxBidsPerBlock
+}
+
 func (b *bidSimulator) SetBestBid(prevBlockHash common.Hash, bid *BidRuntime) {
 	b.bestBidMu.Lock()
 	defer b.bestBidMu.Unlock()
@@ -254,6 +323,26 @@ func (b *bidSimulator) SetBestBid(prevBlockHash common.Hash, bid *BidRuntime) {
 	b.bestBid[prevBlockHash] = bid
 }
 
+// trySetBestBid sets the simulated bid as the best bid if it packs more block reward
+// than the current best one, as the bids of a block are simulated concurrently.
+func (b *bidSimulator) trySetBestBid(bid *BidRuntime) bool {
+	b.bestBidMu.Lock()
+
+	// this is the simplest strategy: best for all the delegators.
+	bestBid := b.bestBid[bid.bid.ParentHash]
+	if bestBid != nil && bid.packedBlockReward.Cmp(bestBid.packedBlockReward) <= 0 {
+		b.bestBidMu.Unlock()
+		return false
+	}
+	b.bestBid[bid.bid.ParentHash] = bid
+	b.bestBidMu.Unlock()
+
+	if bestBid != nil {
+		b.notifyBidRuntime(bestBid, types.BidStatusBeaten, nil)
+	}
+	return true
+}
+
 func (b *bidSimulator) GetBestBid(prevBlockHash common.Hash) *BidRuntime {
 	b.bestBidMu.RLock()
 	defer b.bestBidMu.RUnlock()
@@ -261,38 +350,76 @@ func (b *bidSimulator) GetBestBid(prevBlockHash common.Hash) *BidRuntime {
 	return b.bestBid[prevBlockHash]
 }
 
-func (b *bidSimulator) SetSimulatingBid(prevBlockHash common.Hash, bid *BidRuntime) {
+func (b *bidSimulator) AddSimulatingBid(prevBlockHash common.Hash, req *simBidReq) {
 	b.simBidMu.Lock()
 	defer b.simBidMu.Unlock()
 
-	b.simulatingBid[prevBlockHash] = bid
+	if _, ok := b.simulatingBid[prevBlockHash]; !ok {
+		b.simulatingBid[prevBlockHash] = make(map[common.Hash]*simBidReq)
+	}
+	b.simulatingBid[prevBlockHash][req.bid.bid.Hash()] = req
 }
 
-func (b *bidSimulator) GetSimulatingBid(prevBlockHash common.Hash) *BidRuntime {
+// GetSimulatingBids returns the bids in the process of simulation on top of the given block.
+func (b *bidSimulator) GetSimulatingBids(prevBlockHash common.Hash) []*simBidReq {
 	b.simBidMu.RLock()
 	defer b.simBidMu.RUnlock()
 
-	return b.simulatingBid[prevBlockHash]
+	reqs := make([]*simBidReq, 0, len(b.simulatingBid[prevBlockHash]))
+	for _, req := range b.simulatingBid[prevBlockHash] {
+		reqs = append(reqs, req)
+	}
+	return reqs
 }
 
-func (b *bidSimulator) RemoveSimulatingBid(prevBlockHash common.Hash) {
+// RemoveSimulatingBid removes the bid from the simulating set, it returns false
+// if the bid is not simulating anymore.
+func (b *bidSimulator) RemoveSimulatingBid(prevBlockHash common.Hash, bidHash common.Hash) bool {
 	b.simBidMu.Lock()
 	defer b.simBidMu.Unlock()
 
-	delete(b.simulatingBid, prevBlockHash)
+	if _, ok := b.simulatingBid[prevBlockHash][bidHash]; !ok {
+		return false
+	}
+	delete(b.simulatingBid[prevBlockHash], bidHash)
+	if len(b.simulatingBid[prevBlockHash]) == 0 {
+		delete(b.simulatingBid, prevBlockHash)
+	}
+	return true
 }
 
+// maxSimulatingBids returns the number of bids of a block allowed to be simulated concurrently.
+func (b *bidSimulator) maxSimulatingBids() int {
+	if b.config.MaxSimulatingBids <= 0 {
+		return defaultMaxSimulatingBids
+	}
+	return b.config.MaxSimulatingBids
+}
+
+// mainLoop simulates the committed bids with a bounded pool of workers, each bid
+// is simulated against its own copy of the parent state.
 func (b *bidSimulator) mainLoop() {
 	defer b.chainHeadSub.Unsubscribe()
 
+	workers := make(chan struct{}, b.maxSimulatingBids())
+
 	for {
 		select {
 		case req := <-b.simBidCh:
 			if !b.isRunning() {
+				b.RemoveSimulatingBid(req.bid.bid.ParentHash, req.bid.bid.Hash())
 				continue
 			}
 
-			b.simBid(req.interruptCh, req.bid)
+			select {
+			case workers <- struct{}{}:
+			case <-b.exitCh:
+				return
+			}
+			go func() {
+				defer func() { <-workers }()
+				b.simBid(req.interruptCh, req.bid)
+			}()
 
 		// System stopped
 		case <-b.exitCh:
@@ -305,38 +432,6 @@ func (b *bidSimulator) mainLoop() {
 }
 
 func (b *bidSimulator) newBidLoop() {
-	var (
-		interruptCh chan int32
-	)
-
-	// commit aborts in-flight bid execution with given signal and resubmits a new one.
-	commit := func(reason int32, bidRuntime *BidRuntime) {
-		log.Debug("BidSimulator: start", "bidHash", bidRuntime.bid.Hash().Hex())
-
-		// if the left time is not enough to do simulation, return
-		var simDuration time.Duration
-		if lastBid := b.GetBestBid(bidRuntime.bid.ParentHash); lastBid != nil && lastBid.duration != 0 {
-			simDuration = lastBid.duration
-		}
-
-		if time.Until(b.bidMustBefore(bidRuntime.bid.ParentHash)) <= simDuration*leftOverTimeRate/leftOverTimeScale {
-			log.Debug("BidSimulator: abort commit, not enough time to simulate", "bidHash", bidRuntime.bid.Hash().Hex())
-			return
-		}
-
-		if interruptCh != nil {
-			// each commit work will have its own interruptCh to stop work with a reason
-			interruptCh <- reason
-			close(interruptCh)
-		}
-		interruptCh = make(chan int32, 1)
-		select {
-		case b.simBidCh <- &simBidReq{interruptCh: interruptCh, bid: bidRuntime}:
-		case <-b.exitCh:
-			return
-		}
-	}
-
 	for {
 		select {
 		case newBid := <-b.newBidCh:
@@ -344,61 +439,124 @@ func (b *bidSimulator) newBidLoop() {
 				continue
 			}
 
-			// check the block reward and validator reward of the newBid
-			expectedBlockReward := newBid.GasFee
-			expectedValidatorReward := new(big.Int).Mul(expectedBlockReward, big.NewInt(int64(b.config.ValidatorCommission)))
-			expectedValidatorReward.Div(expectedValidatorReward, big.NewInt(10000))
-			expectedValidatorReward.Sub(expectedValidatorReward, newBid.BuilderFee)
-
-			if expectedValidatorReward.Cmp(big.NewInt(0)) < 0 {
-				// damage self profit, ignore
-				log.Debug("BidSimulator: invalid bid, validator reward is less than 0, ignore", "bidHash", newBid.Hash().Hex())
+			bidRuntime := newBidRuntime(newBid, b.config.ValidatorCommission)
+			req, err := b.scheduleBid(bidRuntime, time.Now(), b.bidMustBefore(newBid.ParentHash))
+			if err != nil {
+				log.Debug("BidSimulator: ignore bid", "bidHash", newBid.Hash().Hex(), "err", err)
+				bidDiscardedCounter.Inc(1)
+				b.recordBid(bidRuntime, types.BidOutcomeIgnored, 0, err)
 				continue
 			}
 
-			bidRuntime := &BidRuntime{
-				bid:                     newBid,
-				expectedBlockReward:     expectedBlockReward,
-				expectedValidatorReward: expectedValidatorReward,
-				packedBlockReward:       big.NewInt(0),
-				packedValidatorReward:   big.NewInt(0),
+			select {
+			case b.simBidCh <- req:
+			case <-b.exitCh:
+				return
 			}
+		case <-b.exitCh:
+			return
+		}
+	}
+}
 
-			simulatingBid := b.GetSimulatingBid(newBid.ParentHash)
-			// simulatingBid is nil means there is no bid in simulation
-			if simulatingBid == nil {
-				// bestBid is nil means bid is the first bid
-				bestBid := b.GetBestBid(newBid.ParentHash)
-				if bestBid == nil {
-					commit(commitInterruptBetterBid, bidRuntime)
-					continue
-				}
+// scheduleBid puts the new bid into simulation at the given time if it is better
+// than the best bid and joins the top bids in simulation, evicting the worst of them
+// if there is no free slot. A deprioritized builder takes free slots only.
+func (b *bidSimulator) scheduleBid(bidRuntime *BidRuntime, now time.Time, mustBefore time.Time) (*simBidReq, error) {
+	if err := b.checkNewBid(bidRuntime); err != nil {
+		return nil, err
+	}
 
-				// if bestBid is not nil, check if newBid is better than bestBid
-				if bidRuntime.expectedBlockReward.Cmp(bestBid.expectedBlockReward) > 0 &&
-					bidRuntime.expectedValidatorReward.Cmp(bestBid.expectedValidatorReward) > 0 {
-					// if both reward are better than last simulating newBid, commit for simulation
-					commit(commitInterruptBetterBid, bidRuntime)
-					continue
-				}
+	newBid := bidRuntime.bid
+	simulating := b.GetSimulatingBids(newBid.ParentHash)
+	if b.reputations.deprioritized(newBid.Builder) && len(simulating) >= b.maxSimulatingBids() {
+		return nil, errBuilderDeprioritized
+	}
+	evict, ok := selectEvictedBid(simulating, bidRuntime, b.maxSimulatingBids())
+	if !ok {
+		return nil, errLowerThanSimulatingBids
+	}
 
-				log.Debug("BidSimulator: lower reward, ignore", "bidHash", newBid.Hash().Hex())
-				continue
-			}
+	// if the left time is not enough to do simulation, return
+	var simDuration time.Duration
+	if lastBid := b.GetBestBid(newBid.ParentHash); lastBid != nil && lastBid.duration != 0 {
+		simDuration = lastBid.duration
+	}
+	if !enoughSimulationTime(mustBefore.Sub(now), simDuration) {
+		return nil, errNotEnoughSimulationTime
+	}
 
-			// simulatingBid must be better than bestBid, if newBid is better than simulatingBid, commit for simulation
-			if bidRuntime.expectedBlockReward.Cmp(simulatingBid.expectedBlockReward) > 0 &&
-				bidRuntime.expectedValidatorReward.Cmp(simulatingBid.expectedValidatorReward) > 0 {
-				// if both reward are better than last simulating newBid, commit for simulation
-				commit(commitInterruptBetterBid, bidRuntime)
-				continue
-			}
+	if evict != nil && b.RemoveSimulatingBid(evict.bid.bid.ParentHash, evict.bid.bid.Hash()) {
+		log.Debug("BidSimulator: interrupt simulation for better bid", "bidHash", evict.bid.bid.Hash().Hex(),
+			"betterBidHash", newBid.Hash().Hex())
+		evict.interrupt(commitInterruptBetterBid)
+	}
 
-			log.Debug("BidSimulator: lower reward, ignore", "bidHash", newBid.Hash().Hex())
-		case <-b.exitCh:
-			return
+	// each commit work will have its own interruptCh to stop work with a reason
+	log.Debug("BidSimulator: start", "bidHash", newBid.Hash().Hex())
+	req := &simBidReq{interruptCh: make(chan int32, 1), bid: bidRuntime}
+	b.AddSimulatingBid(newBid.ParentHash, req)
+	bidSimulatedCounter.Inc(1)
+	b.notifyBidRuntime(bidRuntime, types.BidStatusSimulating, nil)
+	return req, nil
+}
+
+// newBidRuntime creates the runtime of the bid with the rewards it expects.
+func newBidRuntime(newBid *types.Bid, validatorCommission uint64) *BidRuntime {
+	expectedBlockReward := newBid.GasFee
+	expectedValidatorReward := new(big.Int).Mul(expectedBlockReward, big.NewInt(int64(validatorCommission)))
+	expectedValidatorReward.Div(expectedValidatorReward, big.NewInt(10000))
+	expectedValidatorReward.Sub(expectedValidatorReward, newBid.BuilderFee)
+
+	return &BidRuntime{
+		bid:                     newBid,
+		expectedBlockReward:     expectedBlockReward,
+		expectedValidatorReward: expectedValidatorReward,
+		packedBlockReward:       big.NewInt(0),
+		packedValidatorReward:   big.NewInt(0),
+	}
+}
+
+// checkNewBid checks the block reward and validator reward of the new bid, which
+// must be better than the best bid if any.
+func (b *bidSimulator) checkNewBid(bidRuntime *BidRuntime) error {
+	if bidRuntime.expectedValidatorReward.Cmp(big.NewInt(0)) < 0 {
+		// damage self profit, ignore
+		b.reputations.record(bidRuntime.bid.Builder, builderInvalidBid)
+		return errNegativeValidatorReward
+	}
+	if bestBid := b.GetBestBid(bidRuntime.bid.ParentHash); bestBid != nil && !bidRuntime.betterThan(bestBid) {
+		return errLowerThanBestBid
+	}
+	return nil
+}
+
+// enoughSimulationTime returns true if the time left is enough to simulate a bid,
+// given the simulation duration of the best bid.
+func enoughSimulationTime(left time.Duration, simDuration time.Duration) bool {
+	return left > simDuration*leftOverTimeRate/leftOverTimeScale
+}
+
+// selectEvictedBid checks if newBid should be simulated along with the simulating bids.
+// If the simulating bids reach the limit, the worst of them is returned to be interrupted,
+// provided newBid is better than it.
+func selectEvictedBid(simulating []*simBidReq, newBid *BidRuntime, limit int) (*simBidReq, bool) {
+	if len(simulating) < limit {
+		return nil, true
+	}
+
+	var worst *simBidReq
+	for _, req := range simulating {
+		if worst == nil || req.bid.expectedBlockReward.Cmp(worst.bid.expectedBlockReward) < 0 {
+			worst = req
 		}
 	}
+
+	if worst == nil || !newBid.betterThan(worst.bid) {
+		return nil, false
+	}
+
+	return worst, true
 }
 
 func (b *bidSimulator) bidMustBefore(parentHash common.Hash) time.Time {
@@ -430,14 +588,20 @@ func (b *bidSimulator) clearLoop() {
 		}
 		b.bestBidMu.Unlock()
 
+		// the environment of an interrupted simulation is discarded by itself
 		b.simBidMu.Lock()
-		if bid, ok := b.simulatingBid[parentHash]; ok {
-			bid.env.discard()
+		for _, req := range b.simulatingBid[parentHash] {
+			req.interrupt(commitInterruptBidNewHead)
 		}
 		delete(b.simulatingBid, parentHash)
-		for k, v := range b.simulatingBid {
-			if v.bid.BlockNumber <= blockNumber-core.TriesInMemory {
-				v.env.discard()
+		for k, reqs := range b.simulatingBid {
+			for hash, req := range reqs {
+				if req.bid.bid.BlockNumber <= blockNumber-core.TriesInMemory {
+					req.interrupt(commitInterruptBidNewHead)
+					delete(reqs, hash)
+				}
+			}
+			if len(reqs) == 0 {
 				delete(b.simulatingBid, k)
 			}
 		}
@@ -461,13 +625,47 @@ func (b *bidSimulator) sendBid(_ context.Context, bid *types.Bid) error {
 	select {
 	case b.newBidCh <- bid:
 		b.AddPending(bid.BlockNumber, bid.Builder, bid.Hash())
+		b.notifyBidRuntime(&BidRuntime{bid: bid}, types.BidStatusQueued, nil)
 		return nil
 	case <-timer.C:
 		return types.ErrMevBusy
 	}
 }
 
+// admitBid runs the checks a bid of the registered builder must pass at the given
+// time before it is queued for simulation, betterBefore is the deadline of the bids
+// of the block.
+func (b *bidSimulator) admitBid(builder common.Address, bidArgs *types.BidArgs, now time.Time, betterBefore time.Time) (*types.Bid, error) {
+	if until, suspended := b.BuilderSuspended(builder); suspended {
+		return nil, types.NewInvalidBidError(fmt.Sprintf("builder is suspended until %v", until))
+	}
+
+	// quotas are enforced before decoding the txs, which recovers the sender of each
+	if !b.AllowBid(builder, now) {
+		return nil, types.ErrBidRateLimited
+	}
+
+	if err := b.CheckPending(bidArgs.RawBid.BlockNumber, builder, bidArgs.RawBid.Hash()); err != nil {
+		return nil, err
+	}
+
+	signer := types.MakeSigner(b.chainConfig, big.NewInt(int64(bidArgs.RawBid.BlockNumber)), uint64(now.Unix()))
+	bid, err := bidArgs.ToBid(builder, signer)
+	if err != nil {
+		b.RecordInvalidBid(builder)
+		return nil, types.NewInvalidBidError(fmt.Sprintf("fail to convert bidArgs to bid, %v", err))
+	}
+
+	if timeout := betterBefore.Sub(now); timeout <= 0 {
+		return nil, types.NewBidTooLateError(fmt.Sprintf("too late, expected befor %s, appeared %s later", betterBefore,
+			common.PrettyDuration(timeout)))
+	}
+	return bid, nil
+}
+
 func (b *bidSimulator) CheckPending(blockNumber uint64, builder common.Address, bidHash common.Hash) error {
+	maxBids := b.maxBidsPerBlock(builder)
+
 	b.pendingMu.Lock()
 	defer b.pendingMu.Unlock()
 
@@ -484,7 +682,7 @@ func (b *bidSimulator) CheckPending(blockNumber uint64, builder common.Address,
 		return errors.New("bid already exists")
 	}
 
-	if len(b.pending[blockNumber][builder]) >= maxBidPerBuilderPerBlock {
+	if uint64(len(b.pending[blockNumber][builder])) >= maxBids {
 		return errors.New("too many bids")
 	}
 
@@ -503,6 +701,7 @@ func (b *bidSimulator) AddPending(blockNumber uint64, builder common.Address, bi
 func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	// prevent from stopping happen in time interval from sendBid to simBid
 	if !b.isRunning() || !b.receivingBid() {
+		b.RemoveSimulatingBid(bidRuntime.bid.ParentHash, bidRuntime.bid.Hash())
 		return
 	}
 
@@ -519,8 +718,6 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		success bool
 	)
 
-	// ensure simulation exited then start next simulation
-	b.SetSimulatingBid(parentHash, bidRuntime)
 	start := time.Now()
 
 	defer func(simStart time.Time) {
@@ -550,7 +747,34 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 			bidRuntime.duration = time.Since(simStart)
 		}
 
-		b.RemoveSimulatingBid(parentHash)
+		if err != nil {
+			bidDiscardedCounter.Inc(1)
+		} else {
+			bidCompletedCounter.Inc(1)
+		}
+
+		switch {
+		case errors.Is(err, errBetterBidArrived) || errors.Is(err, errNewHeadArrived) || errors.Is(err, errMinerExit):
+			b.recordBid(bidRuntime, types.BidOutcomeInterrupted, time.Since(simStart), err)
+		case err != nil:
+			b.recordBid(bidRuntime, types.BidOutcomeFailed, time.Since(simStart), err)
+		case success:
+			b.recordBid(bidRuntime, types.BidOutcomeBest, time.Since(simStart), nil)
+		default:
+			b.recordBid(bidRuntime, types.BidOutcomeSimulated, time.Since(simStart), errors.New("lower packed reward than the best bid"))
+		}
+
+		// only the faults of the builder count against its reputation
+		switch {
+		case errors.Is(err, errGasUsedExceedsLimit):
+			b.reputations.record(builder, builderInvalidBid)
+		case errors.Is(err, errInvalidTxInBid):
+			b.reputations.record(builder, builderFailedSimulation)
+		case errors.Is(err, errRewardShortfall):
+			b.reputations.record(builder, builderRewardShortfall)
+		}
+
+		b.RemoveSimulatingBid(parentHash, bidRuntime.bid.Hash())
 		bidSimTimer.UpdateSince(start)
 	}(time.Now())
 
@@ -562,6 +786,10 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	}); err != nil {
 		return
 	}
+	bidRuntime.env.bidHash = bidRuntime.bid.Hash()
+	if b.config.GreedyMergeTx {
+		bidRuntime.accessed = newBidAccessSet(bidRuntime.env, b.chainConfig)
+	}
 
 	gasLimit := bidRuntime.env.header.GasLimit
 	if bidRuntime.env.gasPool == nil {
@@ -571,18 +799,22 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	}
 
 	if bidRuntime.bid.GasUsed > bidRuntime.env.gasPool.Gas() {
-		err = errors.New("gas used exceeds gas limit")
+		err = errGasUsedExceedsLimit
 		return
 	}
 
 	for _, tx := range bidRuntime.bid.Txs {
 		select {
-		case <-interruptCh:
-			err = errors.New("simulation abort due to better bid arrived")
+		case reason := <-interruptCh:
+			if reason == commitInterruptBidNewHead {
+				err = errNewHeadArrived
+			} else {
+				err = errBetterBidArrived
+			}
 			return
 
 		case <-b.exitCh:
-			err = errors.New("miner exit")
+			err = errMinerExit
 			return
 
 		default:
@@ -595,7 +827,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 		err = bidRuntime.commitTransaction(b.chain, b.chainConfig, tx)
 		if err != nil {
 			log.Error("BidSimulator: failed to commit tx", "bidHash", bidRuntime.bid.Hash(), "tx", tx.Hash(), "err", err)
-			err = fmt.Errorf("invalid tx in bid, %v", err)
+			err = fmt.Errorf("%w, %v", errInvalidTxInBid, err)
 			return
 		}
 	}
@@ -604,7 +836,7 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 
 	// return if bid is invalid, reportIssue issue to mev-sentry/builder if simulation is fully done
 	if !bidRuntime.validReward() {
-		err = errors.New("reward does not achieve the expectation")
+		err = errRewardShortfall
 		return
 	}
 
@@ -623,12 +855,26 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 				bidTxsSet.Add(tx.Hash())
 			}
 
+			// the pay bid tx is committed after the merged txs, its accounts must stay untouched
+			if from, err := types.Sender(bidRuntime.env.signer, payBidTx); err == nil {
+				bidRuntime.accessed.addAccount(from)
+			}
+			if payBidTx.To() != nil {
+				bidRuntime.accessed.addAccount(*payBidTx.To())
+			}
+			bidRuntime.env.accessed = bidRuntime.accessed
 			fillErr := b.bidWorker.fillTransactions(interruptCh, bidRuntime.env, stopTimer, bidTxsSet)
-			log.Info("BidSimulator: greedy merge tx fill transactions", "block", bidRuntime.env.header.Number,
-				"tx count", bidRuntime.env.tcount-bidTxLen+1, "err", fillErr)
+			bidRuntime.env.accessed = nil
 
-			// recalculate the packed reward
+			// recalculate the packed reward, the merged reward is kept apart from the one of the builder
+			bidReward := bidRuntime.packedBlockReward
 			bidRuntime.packReward(b.config.ValidatorCommission)
+			bidRuntime.mergedTxs = bidRuntime.env.tcount - bidTxLen + 1
+			bidRuntime.mergedBlockReward = new(big.Int).Sub(bidRuntime.packedBlockReward, bidReward)
+			bidMergedTxsCounter.Inc(int64(bidRuntime.mergedTxs))
+
+			log.Info("BidSimulator: greedy merge tx fill transactions", "block", bidRuntime.env.header.Number,
+				"tx count", bidRuntime.mergedTxs, "reward", bidRuntime.mergedBlockReward, "err", fillErr)
 		}
 	}
 
@@ -636,24 +882,107 @@ func (b *bidSimulator) simBid(interruptCh chan int32, bidRuntime *BidRuntime) {
 	err = bidRuntime.commitTransaction(b.chain, b.chainConfig, payBidTx)
 	if err != nil {
 		log.Error("BidSimulator: failed to commit tx", "bidHash", bidRuntime.bid.Hash(), "tx", payBidTx.Hash(), "err", err)
-		err = fmt.Errorf("invalid tx in bid, %v", err)
+		err = fmt.Errorf("%w, %v", errInvalidTxInBid, err)
 		return
 	}
 
-	bestBid := b.GetBestBid(parentHash)
+	// the bids of a block are simulated concurrently, the best completed one wins
+	success = b.trySetBestBid(bidRuntime)
+}
 
-	if bestBid == nil {
-		b.SetBestBid(bidRuntime.bid.ParentHash, bidRuntime)
-		success = true
-		return
+// recordBid records the outcome of the bid into the bid history.
+func (b *bidSimulator) recordBid(bidRuntime *BidRuntime, outcome string, simDuration time.Duration, err error) {
+	bid := bidRuntime.bid
+	record := &types.BidRecord{
+		BlockNumber:             bid.BlockNumber,
+		ParentHash:              bid.ParentHash,
+		Builder:                 bid.Builder,
+		BidHash:                 bid.Hash(),
+		GasUsed:                 bid.GasUsed,
+		GasFee:                  bid.GasFee,
+		BuilderFee:              bid.BuilderFee,
+		ExpectedBlockReward:     bidRuntime.expectedBlockReward,
+		ExpectedValidatorReward: bidRuntime.expectedValidatorReward,
+		PackedBlockReward:       bidRuntime.packedBlockReward,
+		PackedValidatorReward:   bidRuntime.packedValidatorReward,
+		MergedTxCount:           uint64(bidRuntime.mergedTxs),
+		MergedBlockReward:       bidRuntime.mergedBlockReward,
+		SimulationTime:          simDuration,
+		Outcome:                 outcome,
+		Time:                    uint64(time.Now().UnixMilli()),
+	}
+	if err != nil {
+		record.Error = err.Error()
 	}
+	b.history.record(record)
+	b.notifyBidOutcome(bidRuntime, outcome, err)
+}
 
-	// this is the simplest strategy: best for all the delegators.
-	if bidRuntime.packedBlockReward.Cmp(bestBid.packedBlockReward) > 0 {
-		b.SetBestBid(bidRuntime.bid.ParentHash, bidRuntime)
-		success = true
+// RecordSealedBid records the bid the block is sealed with as the winning bid.
+func (b *bidSimulator) RecordSealedBid(bidHash common.Hash, block *types.Block) {
+	records, err := b.history.get(block.NumberU64())
+	if err != nil {
+		log.Error("BidSimulator: failed to read bid history", "block", block.NumberU64(), "err", err)
+		return
+	}
+	for _, record := range records {
+		if record.BidHash != bidHash {
+			continue
+		}
+		sealed := *record
+		sealed.Outcome = types.BidOutcomeWon
+		sealed.Error = ""
+		sealed.Time = uint64(time.Now().UnixMilli())
+		hash := block.Hash()
+		sealed.SealedBlockHash = &hash
+		b.history.record(&sealed)
+
+		b.notifyBidStatus(&types.BidStatus{
+			BidHash:               sealed.BidHash,
+			BlockNumber:           sealed.BlockNumber,
+			ParentHash:            sealed.ParentHash,
+			Builder:               sealed.Builder,
+			Status:                types.BidStatusSelected,
+			PackedBlockReward:     sealed.PackedBlockReward,
+			PackedValidatorReward: sealed.PackedValidatorReward,
+			Time:                  sealed.Time,
+		}, nil)
 		return
 	}
+	log.Warn("BidSimulator: sealed bid not found in bid history", "block", block.NumberU64(), "bidHash", bidHash)
+}
+
+// BidHistory returns the records of the bids received for the given block.
+func (b *bidSimulator) BidHistory(blockNumber uint64) ([]*types.BidRecord, error) {
+	return b.history.get(blockNumber)
+}
+
+// RecordInvalidBid counts the bid of the builder failing the validation against
+// its reputation.
+func (b *bidSimulator) RecordInvalidBid(builder common.Address) {
+	b.reputations.record(builder, builderInvalidBid)
+}
+
+// BuilderSuspended returns the end of the suspension if the builder is suspended.
+func (b *bidSimulator) BuilderSuspended(builder common.Address) (time.Time, bool) {
+	return b.reputations.suspended(builder)
+}
+
+// BuilderReputation returns the reputation of the builder.
+func (b *bidSimulator) BuilderReputation(builder common.Address) *types.BuilderReputation {
+	return b.reputations.get(builder)
+}
+
+// SuspendBuilder suspends the builder for the given duration.
+func (b *bidSimulator) SuspendBuilder(builder common.Address, duration time.Duration) {
+	b.reputations.suspend(builder, duration)
+	log.Info("BidSimulator: builder suspended", "builder", builder, "duration", duration)
+}
+
+// ResumeBuilder lifts the penalty of the builder and resets its reputation counters.
+func (b *bidSimulator) ResumeBuilder(builder common.Address) {
+	b.reputations.resume(builder)
+	log.Info("BidSimulator: builder resumed", "builder", builder)
 }
 
 // reportIssue reports the issue to the mev-sentry
@@ -686,9 +1015,20 @@ type BidRuntime struct {
 	packedBlockReward     *big.Int
 	packedValidatorReward *big.Int
 
+	accessed          *bidAccessSet // state accessed by the bid txs, tracked if greedy merging
+	mergedTxs         int           // number of the mempool txs merged into the bid
+	mergedBlockReward *big.Int      // block reward of the merged txs, nil if not merged
+
 	duration time.Duration
 }
 
+// betterThan returns true if both the block reward and validator reward expected
+// by the bid are higher than the other one.
+func (r *BidRuntime) betterThan(other *BidRuntime) bool {
+	return r.expectedBlockReward.Cmp(other.expectedBlockReward) > 0 &&
+		r.expectedValidatorReward.Cmp(other.expectedValidatorReward) > 0
+}
+
 func (r *BidRuntime) validReward() bool {
 	return r.packedBlockReward.Cmp(r.expectedBlockReward) >= 0 &&
 		r.packedValidatorReward.Cmp(r.expectedValidatorReward) >= 0
@@ -746,6 +1086,9 @@ func (r *BidRuntime) commitTransaction(chain *core.BlockChain, chainConfig *para
 		env.txs = append(env.txs, tx)
 		env.receipts = append(env.receipts, receipt)
 	}
+	if r.accessed != nil {
+		r.accessed.add(env.state.AccessList())
+	}
 
 	r.env.tcount++
 
@@ -0,0 +1,79 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func newTestBidRuntime(parentHash common.Hash, blockReward, validatorReward int64) *BidRuntime {
+	return &BidRuntime{
+		bid:                     &types.Bid{ParentHash: parentHash, GasFee: big.NewInt(blockReward), BuilderFee: big.NewInt(0)},
+		expectedBlockReward:     big.NewInt(blockReward),
+		expectedValidatorReward: big.NewInt(validatorReward),
+		packedBlockReward:       big.NewInt(blockReward),
+		packedValidatorReward:   big.NewInt(validatorReward),
+	}
+}
+
+func TestSelectEvictedBid(t *testing.T) {
+	parent := common.Hash{0x1}
+
+	var simulating []*simBidReq
+	for _, reward := range []int64{300, 100, 200} {
+		simulating = append(simulating, &simBidReq{bid: newTestBidRuntime(parent, reward, reward/10), interruptCh: make(chan int32, 1)})
+	}
+
+	// a free slot is left, nothing is evicted
+	if evict, ok := selectEvictedBid(simulating, newTestBidRuntime(parent, 50, 5), 4); !ok || evict != nil {
+		t.Fatalf("free slot: have (%v, %v), want (nil, true)", evict, ok)
+	}
+	// the limit is reached and the new bid is not better than the worst one
+	if evict, ok := selectEvictedBid(simulating, newTestBidRuntime(parent, 50, 5), 3); ok || evict != nil {
+		t.Fatalf("lower bid: have (%v, %v), want (nil, false)", evict, ok)
+	}
+	// a higher block reward with a lower validator reward is not better
+	if _, ok := selectEvictedBid(simulating, newTestBidRuntime(parent, 150, 5), 3); ok {
+		t.Fatalf("lower validator reward: bid should not be simulated")
+	}
+	// the worst simulating bid is evicted for a better one
+	evict, ok := selectEvictedBid(simulating, newTestBidRuntime(parent, 150, 15), 3)
+	if !ok || evict != simulating[1] {
+		t.Fatalf("better bid: have (%v, %v), want (%v, true)", evict, ok, simulating[1])
+	}
+}
+
+func TestTrySetBestBid(t *testing.T) {
+	b := &bidSimulator{bestBid: make(map[common.Hash]*BidRuntime)}
+	parent := common.Hash{0x1}
+
+	first := newTestBidRuntime(parent, 100, 10)
+	if !b.trySetBestBid(first) {
+		t.Fatalf("first completed bid should be the best")
+	}
+	if b.trySetBestBid(newTestBidRuntime(parent, 100, 10)) || b.trySetBestBid(newTestBidRuntime(parent, 90, 9)) {
+		t.Fatalf("bid not packing more reward should not be the best")
+	}
+	if best := b.GetBestBid(parent); best != first {
+		t.Fatalf("best bid mismatch: have %v, want %v", best, first)
+	}
+	better := newTestBidRuntime(parent, 110, 11)
+	if !b.trySetBestBid(better) || b.GetBestBid(parent) != better {
+		t.Fatalf("bid packing more reward should be the best")
+	}
+}
+
+func TestSimBidReqInterrupt(t *testing.T) {
+	req := &simBidReq{interruptCh: make(chan int32, 1)}
+	req.interrupt(commitInterruptBetterBid)
+	req.interrupt(commitInterruptBidNewHead)
+
+	if reason := <-req.interruptCh; reason != commitInterruptBetterBid {
+		t.Fatalf("interrupt reason mismatch: have %d, want %d", reason, commitInterruptBetterBid)
+	}
+	if _, ok := <-req.interruptCh; ok {
+		t.Fatalf("interrupt channel should be closed")
+	}
+}
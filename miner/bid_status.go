@@ -0,0 +1,205 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	errLowerThanSimulatingBids = errors.New("lower reward than the simulating bids")
+	errBuilderDeprioritized    = errors.New("builder is deprioritized")
+)
+
+var bidStatusDroppedCounter = metrics.NewRegisteredCounter("bid/status/dropped", nil) // status events dropped for slow subscribers
+
+// bidStatusFeed delivers the status events of the bids to the subscribers of their
+// builder. The events are sent without blocking the simulation, those a subscriber
+// has no room for are dropped. The zero value is ready to use.
+type bidStatusFeed struct {
+	mu   sync.RWMutex
+	subs map[common.Address]map[*bidStatusSub]struct{}
+}
+
+// bidStatusSub is a subscription to the status events of the bids of a builder.
+type bidStatusSub struct {
+	feed    *bidStatusFeed
+	builder common.Address
+	ch      chan<- *types.BidStatus
+	once    sync.Once
+	err     chan error
+}
+
+// subscribe subscribes the channel to the status events of the bids of the builder.
+func (f *bidStatusFeed) subscribe(builder common.Address, ch chan<- *types.BidStatus) event.Subscription {
+	sub := &bidStatusSub{feed: f, builder: builder, ch: ch, err: make(chan error)}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[common.Address]map[*bidStatusSub]struct{})
+	}
+	if f.subs[builder] == nil {
+		f.subs[builder] = make(map[*bidStatusSub]struct{})
+	}
+	f.subs[builder][sub] = struct{}{}
+	return sub
+}
+
+// send delivers the event to the subscribers of the builder of the bid.
+func (f *bidStatusFeed) send(status *types.BidStatus) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for sub := range f.subs[status.Builder] {
+		select {
+		case sub.ch <- status:
+		default:
+			bidStatusDroppedCounter.Inc(1)
+		}
+	}
+}
+
+// Unsubscribe implements event.Subscription.
+func (sub *bidStatusSub) Unsubscribe() {
+	sub.once.Do(func() {
+		sub.feed.mu.Lock()
+		delete(sub.feed.subs[sub.builder], sub)
+		if len(sub.feed.subs[sub.builder]) == 0 {
+			delete(sub.feed.subs, sub.builder)
+		}
+		sub.feed.mu.Unlock()
+		close(sub.err)
+	})
+}
+
+// Err implements event.Subscription.
+func (sub *bidStatusSub) Err() <-chan error {
+	return sub.err
+}
+
+// bidStatusAuth is an auth hash accepted to subscribe to the status events.
+type bidStatusAuth struct {
+	hash    common.Hash
+	expires time.Time
+}
+
+// bidStatusAuths is the set of the auth hashes used to subscribe to the status
+// events, kept until the signed time can no longer be accepted. The zero value
+// is ready to use.
+type bidStatusAuths struct {
+	mu    sync.Mutex
+	used  map[common.Hash]struct{}
+	queue []bidStatusAuth // ordered by expiry
+}
+
+// use marks the auth hash used, returning false if it already is. A signed time
+// is accepted within the auth window around the local time, so the hash is kept
+// twice the window from now.
+func (a *bidStatusAuths) use(hash common.Hash, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for len(a.queue) > 0 && now.After(a.queue[0].expires) {
+		delete(a.used, a.queue[0].hash)
+		a.queue = a.queue[1:]
+	}
+	if _, ok := a.used[hash]; ok {
+		return false
+	}
+	if a.used == nil {
+		a.used = make(map[common.Hash]struct{})
+	}
+	a.used[hash] = struct{}{}
+	a.queue = append(a.queue, bidStatusAuth{hash: hash, expires: now.Add(2 * types.BidStatusAuthWindow)})
+	return true
+}
+
+// bidErrorCode returns the code of core/types/bid_error.go the bid is rejected with.
+func bidErrorCode(err error) int {
+	var bidErr interface{ ErrorCode() int }
+	switch {
+	case errors.As(err, &bidErr):
+		return bidErr.ErrorCode()
+	case errors.Is(err, errNotEnoughSimulationTime) || errors.Is(err, errNewHeadArrived):
+		return types.BidTooLateError
+	case errors.Is(err, errMinerExit):
+		return types.MevNotRunningError
+	case errors.Is(err, errBuilderDeprioritized):
+		return types.MevBusyError
+	case errors.Is(err, errInvalidTxInBid) || errors.Is(err, errGasUsedExceedsLimit) || errors.Is(err, errRewardShortfall):
+		return types.BidSimFailedError
+	default:
+		return types.InvalidBidParamError
+	}
+}
+
+// newBidStatus creates the status event of the bid.
+func newBidStatus(builder common.Address, rawBid *types.RawBid, status string) *types.BidStatus {
+	return &types.BidStatus{
+		BidHash:     rawBid.Hash(),
+		BlockNumber: rawBid.BlockNumber,
+		ParentHash:  rawBid.ParentHash,
+		Builder:     builder,
+		Status:      status,
+		Time:        uint64(time.Now().UnixMilli()),
+	}
+}
+
+// SubscribeBidStatus subscribes to the status events of the bids of the builder,
+// the auth hash signed by the builder is accepted once only.
+func (b *bidSimulator) SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error) {
+	if !b.statusAuths.use(auth, time.Now()) {
+		return nil, types.NewInvalidBidError("signature already used, sign a new time")
+	}
+	return b.statusFeed.subscribe(builder, ch), nil
+}
+
+// notifyBidStatus notifies the status of the bid, err is set if the bid is rejected.
+func (b *bidSimulator) notifyBidStatus(status *types.BidStatus, err error) {
+	if err != nil {
+		status.ErrorCode = bidErrorCode(err)
+		status.Error = err.Error()
+	}
+	b.statusFeed.send(status)
+}
+
+// notifyBidRuntime notifies the status of the bid in simulation, along with the
+// rewards packed by the simulation if any.
+func (b *bidSimulator) notifyBidRuntime(bidRuntime *BidRuntime, status string, err error) {
+	bid := bidRuntime.bid
+	bidStatus := &types.BidStatus{
+		BidHash:     bid.Hash(),
+		BlockNumber: bid.BlockNumber,
+		ParentHash:  bid.ParentHash,
+		Builder:     bid.Builder,
+		Status:      status,
+		Time:        uint64(time.Now().UnixMilli()),
+	}
+	if bidRuntime.packedBlockReward != nil && bidRuntime.packedBlockReward.Sign() > 0 {
+		bidStatus.PackedBlockReward = new(big.Int).Set(bidRuntime.packedBlockReward)
+		bidStatus.PackedValidatorReward = new(big.Int).Set(bidRuntime.packedValidatorReward)
+	}
+	b.notifyBidStatus(bidStatus, err)
+}
+
+// notifyBidOutcome notifies the status of the bid according to its outcome
+// recorded in the bid history.
+func (b *bidSimulator) notifyBidOutcome(bidRuntime *BidRuntime, outcome string, err error) {
+	switch {
+	case outcome == types.BidOutcomeBest:
+		b.notifyBidRuntime(bidRuntime, types.BidStatusSimulated, nil)
+	case outcome == types.BidOutcomeSimulated,
+		errors.Is(err, errLowerThanBestBid), errors.Is(err, errLowerThanSimulatingBids), errors.Is(err, errBetterBidArrived):
+		b.notifyBidRuntime(bidRuntime, types.BidStatusBeaten, nil)
+	default:
+		b.notifyBidRuntime(bidRuntime, types.BidStatusRejected, err)
+	}
+}
@@ -0,0 +1,147 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBidStatusEvents(t *testing.T) {
+	var (
+		b = &bidSimulator{
+			bestBid: make(map[common.Hash]*BidRuntime),
+			history: newBidHistory(""),
+		}
+		parent   = common.Hash{0x1}
+		statuses = make(chan *types.BidStatus, 10)
+	)
+	defer b.history.close()
+
+	sub, err := b.SubscribeBidStatus(common.Address{}, common.Hash{0x1}, statuses)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+	if _, err := b.SubscribeBidStatus(common.Address{}, common.Hash{0x1}, statuses); err == nil {
+		t.Fatalf("used auth accepted")
+	}
+
+	expect := func(status string, code int) *types.BidStatus {
+		t.Helper()
+		select {
+		case event := <-statuses:
+			if event.Status != status || event.ErrorCode != code {
+				t.Fatalf("status mismatch: have %s (%d), want %s (%d)", event.Status, event.ErrorCode, status, code)
+			}
+			return event
+		default:
+			t.Fatalf("no %s event", status)
+			return nil
+		}
+	}
+
+	// The simulated bids are notified with the packed reward
+	first := newTestBidRuntime(parent, 100, 10)
+	b.trySetBestBid(first)
+	b.recordBid(first, types.BidOutcomeBest, 0, nil)
+	if event := expect(types.BidStatusSimulated, 0); event.PackedBlockReward.Int64() != 100 {
+		t.Fatalf("packed reward mismatch: have %v, want 100", event.PackedBlockReward)
+	}
+
+	// The best bid is beaten once a better one is simulated
+	b.trySetBestBid(newTestBidRuntime(parent, 200, 20))
+	expect(types.BidStatusBeaten, 0)
+
+	// The bids not good enough are beaten rather than rejected
+	b.recordBid(newTestBidRuntime(parent, 50, 5), types.BidOutcomeIgnored, 0, errLowerThanBestBid)
+	expect(types.BidStatusBeaten, 0)
+	b.recordBid(newTestBidRuntime(parent, 50, 5), types.BidOutcomeInterrupted, 0, errBetterBidArrived)
+	expect(types.BidStatusBeaten, 0)
+
+	// The rejections carry the code of the error
+	tests := []struct {
+		outcome string
+		err     error
+		code    int
+	}{
+		{types.BidOutcomeFailed, fmt.Errorf("%w, %v", errInvalidTxInBid, errors.New("nonce too low")), types.BidSimFailedError},
+		{types.BidOutcomeFailed, errRewardShortfall, types.BidSimFailedError},
+		{types.BidOutcomeIgnored, errNotEnoughSimulationTime, types.BidTooLateError},
+		{types.BidOutcomeIgnored, errNegativeValidatorReward, types.InvalidBidParamError},
+		{types.BidOutcomeIgnored, errBuilderDeprioritized, types.MevBusyError},
+		{types.BidOutcomeInterrupted, errNewHeadArrived, types.BidTooLateError},
+	}
+	for _, tt := range tests {
+		b.recordBid(newTestBidRuntime(parent, 50, 5), tt.outcome, 0, tt.err)
+		if event := expect(types.BidStatusRejected, tt.code); event.Error != tt.err.Error() {
+			t.Fatalf("error mismatch: have %q, want %q", event.Error, tt.err.Error())
+		}
+	}
+	if code := bidErrorCode(types.ErrBidRateLimited); code != types.BidRateLimitedError {
+		t.Fatalf("bid error code mismatch: have %d, want %d", code, types.BidRateLimitedError)
+	}
+}
+
+func TestBidStatusFeed(t *testing.T) {
+	var (
+		feed     bidStatusFeed
+		builder  = common.Address{0x1}
+		statuses = make(chan *types.BidStatus, 1)
+		others   = make(chan *types.BidStatus, 1)
+	)
+	sub := feed.subscribe(builder, statuses)
+	otherSub := feed.subscribe(common.Address{0x2}, others)
+	defer otherSub.Unsubscribe()
+
+	// The events are delivered to the subscribers of the builder only, and dropped
+	// rather than blocking once the subscriber has no room for them
+	feed.send(&types.BidStatus{Builder: builder, Status: types.BidStatusReceived})
+	feed.send(&types.BidStatus{Builder: builder, Status: types.BidStatusQueued})
+	if event := <-statuses; event.Status != types.BidStatusReceived {
+		t.Fatalf("status mismatch: have %s, want %s", event.Status, types.BidStatusReceived)
+	}
+	if len(statuses) != 0 {
+		t.Fatalf("event beyond the room of the subscriber delivered")
+	}
+	if len(others) != 0 {
+		t.Fatalf("event of another builder delivered")
+	}
+
+	// No event is delivered once unsubscribed
+	sub.Unsubscribe()
+	if _, ok := <-sub.Err(); ok {
+		t.Fatalf("error channel not closed")
+	}
+	feed.send(&types.BidStatus{Builder: builder, Status: types.BidStatusQueued})
+	if len(statuses) != 0 {
+		t.Fatalf("event delivered after unsubscribe")
+	}
+}
+
+func TestBidStatusAuths(t *testing.T) {
+	var (
+		auths bidStatusAuths
+		now   = time.Now()
+	)
+	if !auths.use(common.Hash{0x1}, now) {
+		t.Fatalf("unused auth rejected")
+	}
+	if auths.use(common.Hash{0x1}, now.Add(types.BidStatusAuthWindow)) {
+		t.Fatalf("used auth accepted within the window")
+	}
+	if !auths.use(common.Hash{0x2}, now.Add(types.BidStatusAuthWindow)) {
+		t.Fatalf("unused auth rejected")
+	}
+	// The hashes are forgotten once their signed time can no longer be accepted
+	later := now.Add(2*types.BidStatusAuthWindow + time.Second)
+	if !auths.use(common.Hash{0x1}, later) {
+		t.Fatalf("expired auth not forgotten")
+	}
+	if len(auths.queue) != 2 || len(auths.used) != 2 {
+		t.Fatalf("expired auths kept: queue %d, used %d", len(auths.queue), len(auths.used))
+	}
+}
@@ -0,0 +1,101 @@
+package miner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// blockDecisionCacheLimit is the number of the recent block decisions kept in memory.
+const blockDecisionCacheLimit = 4096
+
+var (
+	decisionLocalCounter = metrics.NewRegisteredCounter("miner/decision/local", nil) // in-turn blocks sealed with the local work beating a bid
+	decisionBidCounter   = metrics.NewRegisteredCounter("miner/decision/bid", nil)   // in-turn blocks sealed with a bid
+	decisionNoBidCounter = metrics.NewRegisteredCounter("miner/decision/nobid", nil) // in-turn blocks sealed without any bid
+
+	// the reward of the best bid minus the one of the local work in gwei, negative if the local work is better
+	decisionBlockUpliftHist     = metrics.NewRegisteredHistogram("miner/decision/uplift/block", nil, metrics.NewExpDecaySample(1028, 0.015))
+	decisionValidatorUpliftHist = metrics.NewRegisteredHistogram("miner/decision/uplift/validator", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// newBlockDecision creates the decision between the local work and the best bid of
+// an in-turn block, bid is nil if no bid is received.
+func newBlockDecision(local *environment, localReward *uint256.Int, validatorCommission uint64, bid *BidRuntime, bidWins bool) *types.BlockDecision {
+	blockReward := localReward.ToBig()
+	validatorReward := new(big.Int).Mul(blockReward, new(big.Int).SetUint64(validatorCommission))
+	validatorReward.Div(validatorReward, big.NewInt(10000))
+
+	decision := &types.BlockDecision{
+		BlockNumber: local.header.Number.Uint64(),
+		ParentHash:  local.header.ParentHash,
+		Local: &types.BlockCandidate{
+			BlockReward:     blockReward,
+			ValidatorReward: validatorReward,
+			GasUsed:         local.header.GasUsed,
+			TxCount:         len(local.txs),
+		},
+		Winner: types.BlockCandidateLocal,
+	}
+	if bid == nil {
+		return decision
+	}
+	builder, bidHash := bid.bid.Builder, bid.bid.Hash()
+	decision.Bid = &types.BlockCandidate{
+		BlockReward:     new(big.Int).Set(bid.packedBlockReward),
+		ValidatorReward: new(big.Int).Set(bid.packedValidatorReward),
+		GasUsed:         bid.env.header.GasUsed,
+		TxCount:         len(bid.env.txs),
+		Builder:         &builder,
+		BidHash:         &bidHash,
+	}
+	decision.BlockRewardUplift = new(big.Int).Sub(decision.Bid.BlockReward, decision.Local.BlockReward)
+	decision.ValidatorRewardUplift = new(big.Int).Sub(decision.Bid.ValidatorReward, decision.Local.ValidatorReward)
+	if bidWins {
+		decision.Winner = types.BlockCandidateBid
+	}
+	return decision
+}
+
+// blockDecisions keeps the decisions of the recently sealed in-turn blocks.
+type blockDecisions struct {
+	cache *lru.Cache[uint64, *types.BlockDecision]
+}
+
+func newBlockDecisions() *blockDecisions {
+	return &blockDecisions{cache: lru.NewCache[uint64, *types.BlockDecision](blockDecisionCacheLimit)}
+}
+
+// sealed records the decision of the sealed block and updates the metrics.
+func (d *blockDecisions) sealed(decision *types.BlockDecision, block *types.Block) {
+	hash := block.Hash()
+	decision.BlockHash = &hash
+	decision.Time = uint64(time.Now().UnixMilli())
+	d.cache.Add(decision.BlockNumber, decision)
+
+	switch {
+	case decision.Bid == nil:
+		decisionNoBidCounter.Inc(1)
+	case decision.Winner == types.BlockCandidateBid:
+		decisionBidCounter.Inc(1)
+	default:
+		decisionLocalCounter.Inc(1)
+	}
+	if decision.Bid != nil {
+		gwei := big.NewInt(params.GWei)
+		decisionBlockUpliftHist.Update(new(big.Int).Quo(decision.BlockRewardUplift, gwei).Int64())
+		decisionValidatorUpliftHist.Update(new(big.Int).Quo(decision.ValidatorRewardUplift, gwei).Int64())
+	}
+}
+
+// get returns the decision of the sealed block with the given number.
+func (d *blockDecisions) get(number uint64) *types.BlockDecision {
+	decision, _ := d.cache.Get(number)
+	return decision
+}
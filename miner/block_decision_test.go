@@ -0,0 +1,56 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBlockDecision(t *testing.T) {
+	var (
+		header = &types.Header{Number: big.NewInt(10), ParentHash: common.Hash{0x1}, GasUsed: 100}
+		local  = &environment{header: header, txs: make([]*types.Transaction, 2)}
+		bid    = &BidRuntime{
+			bid:                   &types.Bid{Builder: common.Address{0x2}, BuilderFee: big.NewInt(0)},
+			env:                   &environment{header: &types.Header{Number: big.NewInt(10), GasUsed: 300}, txs: make([]*types.Transaction, 5)},
+			packedBlockReward:     big.NewInt(30000),
+			packedValidatorReward: big.NewInt(250),
+		}
+		decisions = newBlockDecisions()
+	)
+
+	// No bid received
+	decision := newBlockDecision(local, uint256.NewInt(20000), 100, nil, false)
+	if decision.Winner != types.BlockCandidateLocal || decision.Bid != nil || decision.ValidatorRewardUplift != nil {
+		t.Fatalf("unexpected decision without bid: %+v", decision)
+	}
+	if decision.Local.ValidatorReward.Int64() != 200 || decision.Local.TxCount != 2 || decision.Local.GasUsed != 100 {
+		t.Fatalf("unexpected local candidate: %+v", decision.Local)
+	}
+
+	// The bid wins
+	decision = newBlockDecision(local, uint256.NewInt(20000), 100, bid, true)
+	if decision.Winner != types.BlockCandidateBid {
+		t.Fatalf("winner mismatch: have %s, want %s", decision.Winner, types.BlockCandidateBid)
+	}
+	if decision.BlockRewardUplift.Int64() != 10000 || decision.ValidatorRewardUplift.Int64() != 50 {
+		t.Fatalf("uplift mismatch: block %v, validator %v", decision.BlockRewardUplift, decision.ValidatorRewardUplift)
+	}
+	if decision.Bid.TxCount != 5 || decision.Bid.GasUsed != 300 || *decision.Bid.Builder != bid.bid.Builder {
+		t.Fatalf("unexpected bid candidate: %+v", decision.Bid)
+	}
+
+	// Only the sealed decisions are served
+	if decisions.get(10) != nil {
+		t.Fatalf("decision served before sealed")
+	}
+	block := types.NewBlockWithHeader(header)
+	decisions.sealed(decision, block)
+	if got := decisions.get(10); got == nil || *got.BlockHash != block.Hash() {
+		t.Fatalf("sealed decision mismatch: %+v", got)
+	}
+}
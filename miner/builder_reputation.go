@@ -0,0 +1,255 @@
+package miner
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// The kinds of the builder misbehaviours tracked by the reputations.
+const (
+	builderInvalidBid = iota
+	builderFailedSimulation
+	builderRewardShortfall
+)
+
+var builderPenalizedCounter = metrics.NewRegisteredCounter("bid/builder/penalized", nil)
+
+// builderReputations keeps the track record of the builders and penalizes the ones
+// crossing the thresholds of the policy within its decay window. The penalties are
+// persisted into a file to survive restarts, the counters are saved along when the
+// bid simulator closes.
+type builderReputations struct {
+	policy BuilderPolicy
+	path   string
+
+	mu          sync.Mutex
+	reputations map[common.Address]*types.BuilderReputation
+	version     uint64 // bumped on every change to be saved
+
+	saveMu sync.Mutex // serializes the writes of the file, out of mu
+	saved  uint64     // version of the reputations in the file
+}
+
+// newBuilderReputations creates the builder reputations, loading the persisted
+// ones from the file if any.
+func newBuilderReputations(policy BuilderPolicy, path string) *builderReputations {
+	r := &builderReputations{
+		policy:      policy,
+		path:        path,
+		reputations: make(map[common.Address]*types.BuilderReputation),
+	}
+	if path == "" {
+		return r
+	}
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Error("BidSimulator: failed to read builder reputations", "path", path, "err", err)
+		}
+		return r
+	}
+	var reputations []*types.BuilderReputation
+	if err := json.Unmarshal(blob, &reputations); err != nil {
+		log.Error("BidSimulator: failed to decode builder reputations", "path", path, "err", err)
+		return r
+	}
+	for _, reputation := range reputations {
+		r.reputations[reputation.Builder] = reputation
+	}
+	log.Info("BidSimulator: loaded builder reputations", "builders", len(reputations))
+	return r
+}
+
+// reputation returns the reputation of the builder, the lock must be held.
+func (r *builderReputations) reputation(builder common.Address) *types.BuilderReputation {
+	reputation, ok := r.reputations[builder]
+	if !ok {
+		reputation = &types.BuilderReputation{Builder: builder}
+		r.reputations[builder] = reputation
+	}
+	return reputation
+}
+
+// record counts the misbehaviour of the builder, penalizing the builder if the
+// counter reaches the threshold of the policy.
+// The counters are reset once the decay window of the policy has passed since
+// the first misbehaviour counted.
+func (r *builderReputations) record(builder common.Address, kind int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		now        = time.Now()
+		reputation = r.reputation(builder)
+		count      uint64
+		threshold  uint64
+	)
+	if window := r.policy.DecayWindow; window > 0 && now.After(time.Unix(int64(reputation.WindowStart), 0).Add(window)) {
+		reputation.InvalidBids = 0
+		reputation.FailedSimulations = 0
+		reputation.RewardShortfalls = 0
+		reputation.WindowStart = uint64(now.Unix())
+	}
+	switch kind {
+	case builderInvalidBid:
+		reputation.InvalidBids++
+		count, threshold = reputation.InvalidBids, r.policy.MaxInvalidBids
+	case builderFailedSimulation:
+		reputation.FailedSimulations++
+		count, threshold = reputation.FailedSimulations, r.policy.MaxFailedSimulations
+	case builderRewardShortfall:
+		reputation.RewardShortfalls++
+		count, threshold = reputation.RewardShortfalls, r.policy.MaxRewardShortfalls
+	}
+	if threshold == 0 || count < threshold || reputation.Penalized(now) {
+		return
+	}
+	log.Warn("BidSimulator: builder penalized", "builder", builder, "invalidBids", reputation.InvalidBids,
+		"failedSimulations", reputation.FailedSimulations, "rewardShortfalls", reputation.RewardShortfalls,
+		"until", now.Add(r.policy.PenaltyDuration), "deprioritized", r.policy.Deprioritize)
+	r.penalize(reputation, now.Add(r.policy.PenaltyDuration), r.policy.Deprioritize)
+	r.saveAsync()
+}
+
+// penalize puts the builder under penalty until the given time and resets its
+// counters, the lock must be held.
+func (r *builderReputations) penalize(reputation *types.BuilderReputation, until time.Time, deprioritize bool) {
+	reputation.InvalidBids = 0
+	reputation.FailedSimulations = 0
+	reputation.RewardShortfalls = 0
+	reputation.WindowStart = 0
+	reputation.Penalties++
+	reputation.PenaltyUntil = uint64(until.Unix())
+	reputation.Deprioritized = deprioritize
+	builderPenalizedCounter.Inc(1)
+}
+
+// suspended returns the end of the suspension if the builder is suspended.
+func (r *builderReputations) suspended(builder common.Address) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reputation, ok := r.reputations[builder]
+	if !ok || reputation.Deprioritized || !reputation.Penalized(time.Now()) {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(reputation.PenaltyUntil), 0), true
+}
+
+// deprioritized returns true if the builder is deprioritized.
+func (r *builderReputations) deprioritized(builder common.Address) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reputation, ok := r.reputations[builder]
+	return ok && reputation.Deprioritized && reputation.Penalized(time.Now())
+}
+
+// get returns a copy of the reputation of the builder.
+func (r *builderReputations) get(builder common.Address) *types.BuilderReputation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reputation, ok := r.reputations[builder]; ok {
+		cpy := *reputation
+		return &cpy
+	}
+	return &types.BuilderReputation{Builder: builder}
+}
+
+// suspend suspends the builder for the given duration regardless of the policy.
+func (r *builderReputations) suspend(builder common.Address, duration time.Duration) {
+	r.mu.Lock()
+	r.penalize(r.reputation(builder), time.Now().Add(duration), false)
+	r.version++
+	r.mu.Unlock()
+
+	r.save()
+}
+
+// resume lifts the penalty of the builder and resets its counters.
+func (r *builderReputations) resume(builder common.Address) {
+	r.mu.Lock()
+	reputation, ok := r.reputations[builder]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	reputation.InvalidBids = 0
+	reputation.FailedSimulations = 0
+	reputation.RewardShortfalls = 0
+	reputation.WindowStart = 0
+	reputation.PenaltyUntil = 0
+	reputation.Deprioritized = false
+	r.version++
+	r.mu.Unlock()
+
+	r.save()
+}
+
+// close persists the reputations, the counters included.
+func (r *builderReputations) close() {
+	r.mu.Lock()
+	r.version++
+	r.mu.Unlock()
+
+	r.save()
+}
+
+// saveAsync bumps the version of the reputations and saves them in the background,
+// the lock must be held.
+func (r *builderReputations) saveAsync() {
+	r.version++
+	if r.path != "" {
+		go r.save()
+	}
+}
+
+// save writes the reputations into the file atomically, unless a later version is
+// saved already. The lock must not be held, the reputations are only encoded under
+// it and written out of it.
+func (r *builderReputations) save() {
+	if r.path == "" {
+		return
+	}
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	r.mu.Lock()
+	version := r.version
+	if version <= r.saved {
+		r.mu.Unlock()
+		return
+	}
+	reputations := make([]*types.BuilderReputation, 0, len(r.reputations))
+	for _, reputation := range r.reputations {
+		reputations = append(reputations, reputation)
+	}
+	sort.Slice(reputations, func(i, j int) bool {
+		return reputations[i].Builder.Cmp(reputations[j].Builder) < 0
+	})
+	blob, err := json.MarshalIndent(reputations, "", "  ")
+	r.mu.Unlock()
+
+	if err != nil {
+		log.Error("BidSimulator: failed to encode builder reputations", "err", err)
+		return
+	}
+	r.saved = version
+	if err := os.WriteFile(r.path+".new", blob, 0644); err != nil {
+		log.Error("BidSimulator: failed to write builder reputations", "path", r.path, "err", err)
+		return
+	}
+	if err := os.Rename(r.path+".new", r.path); err != nil {
+		log.Error("BidSimulator: failed to replace builder reputations", "path", r.path, "err", err)
+	}
+}
@@ -0,0 +1,105 @@
+package miner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBuilderReputations(t *testing.T) {
+	var (
+		path     = filepath.Join(t.TempDir(), "builder-reputation.json")
+		policy   = BuilderPolicy{MaxInvalidBids: 2, MaxRewardShortfalls: 1, PenaltyDuration: time.Hour}
+		builder1 = common.Address{0x1}
+		builder2 = common.Address{0x2}
+	)
+	reputations := newBuilderReputations(policy, path)
+
+	// The builder is suspended once the counter reaches the threshold
+	reputations.record(builder1, builderInvalidBid)
+	if _, suspended := reputations.suspended(builder1); suspended {
+		t.Fatalf("builder suspended below the threshold")
+	}
+	reputations.record(builder1, builderInvalidBid)
+	if _, suspended := reputations.suspended(builder1); !suspended {
+		t.Fatalf("builder not suspended after reaching the threshold")
+	}
+	if reputation := reputations.get(builder1); reputation.InvalidBids != 0 || reputation.Penalties != 1 {
+		t.Fatalf("counters not reset on penalty: %+v", reputation)
+	}
+
+	// Disabled thresholds never penalize
+	for i := 0; i < 10; i++ {
+		reputations.record(builder2, builderFailedSimulation)
+	}
+	if reputation := reputations.get(builder2); reputation.FailedSimulations != 10 || reputation.Penalties != 0 {
+		t.Fatalf("unexpected reputation with disabled threshold: %+v", reputation)
+	}
+
+	// The penalties survive restarts
+	reputations.close()
+	reputations = newBuilderReputations(policy, path)
+	if _, suspended := reputations.suspended(builder1); !suspended {
+		t.Fatalf("suspension lost after reopen")
+	}
+	if reputation := reputations.get(builder2); reputation.FailedSimulations != 10 {
+		t.Fatalf("counters lost after reopen: %+v", reputation)
+	}
+
+	// The penalty can be lifted manually
+	reputations.resume(builder1)
+	if _, suspended := reputations.suspended(builder1); suspended {
+		t.Fatalf("builder still suspended after resume")
+	}
+
+	// A deprioritizing policy does not suspend the builder
+	policy.Deprioritize = true
+	reputations = newBuilderReputations(policy, "")
+	reputations.record(builder2, builderRewardShortfall)
+	if _, suspended := reputations.suspended(builder2); suspended {
+		t.Fatalf("deprioritized builder suspended")
+	}
+	if !reputations.deprioritized(builder2) {
+		t.Fatalf("builder not deprioritized after reaching the threshold")
+	}
+
+	// Manual suspension overrides the policy
+	reputations.suspend(builder2, time.Hour)
+	if _, suspended := reputations.suspended(builder2); !suspended || reputations.deprioritized(builder2) {
+		t.Fatalf("builder not suspended manually")
+	}
+}
+
+func TestBuilderReputationsDecay(t *testing.T) {
+	var (
+		path        = filepath.Join(t.TempDir(), "builder-reputations.json")
+		policy      = BuilderPolicy{MaxInvalidBids: 2, PenaltyDuration: time.Hour, DecayWindow: time.Minute}
+		builder     = common.Address{0x1}
+		reputations = newBuilderReputations(policy, path)
+	)
+	reputations.record(builder, builderInvalidBid)
+
+	// The misbehaviour counted before the decay window is forgotten
+	reputations.mu.Lock()
+	reputations.reputations[builder].WindowStart -= uint64(2 * time.Minute / time.Second)
+	reputations.mu.Unlock()
+
+	reputations.record(builder, builderInvalidBid)
+	if _, suspended := reputations.suspended(builder); suspended {
+		t.Fatalf("builder suspended for decayed misbehaviours")
+	}
+	if have := reputations.get(builder); have.InvalidBids != 1 {
+		t.Fatalf("invalid bids mismatch: have %d, want 1", have.InvalidBids)
+	}
+	reputations.record(builder, builderInvalidBid)
+	if _, suspended := reputations.suspended(builder); !suspended {
+		t.Fatalf("builder not suspended within the decay window")
+	}
+	// The penalty is saved in the background
+	reputations.close()
+	if _, suspended := newBuilderReputations(policy, path).suspended(builder); !suspended {
+		t.Fatalf("suspension lost after reopen")
+	}
+}
@@ -8,12 +8,51 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 type BuilderConfig struct {
-	Address common.Address
-	URL     string
+	Address         common.Address
+	URL             string
+	MaxBidsPerBlock uint64  // Overrides MevConfig.MaxBidsPerBlock if not 0
+	BidRateLimit    float64 // Overrides MevConfig.BidRateLimit if not 0
+}
+
+// BidServerConfig is the config of the dedicated endpoint receiving the bids, the
+// builders are authenticated by JWT, mutual TLS or both on top of the bid signature.
+type BidServerConfig struct {
+	ListenAddr   string // The listening address of the endpoint, e.g. "0.0.0.0:8555", disabled if empty
+	JWTSecret    string // The file of the hex-encoded JWT secret shared with the builders, optional
+	TLSCertFile  string // The certificate of the endpoint, TLS is enabled if set
+	TLSKeyFile   string // The private key of the certificate
+	ClientCAFile string // The CA verifying the client certificates, mutual TLS is enabled if set
+}
+
+// BuilderPolicy is the policy penalizing the builders sending bad bids, a builder
+// is penalized once any of its counters reaches the threshold. All thresholds are
+// disabled by default.
+type BuilderPolicy struct {
+	MaxInvalidBids       uint64        // The number of invalid bids to penalize a builder, 0 to disable
+	MaxFailedSimulations uint64        // The number of failed simulations to penalize a builder, 0 to disable
+	MaxRewardShortfalls  uint64        // The number of reward shortfalls to penalize a builder, 0 to disable
+	PenaltyDuration      time.Duration // How long a penalty lasts
+	DecayWindow          time.Duration // How long the misbehaviours are counted before the counters reset, 0 to never reset
+	Deprioritize         bool          // Whether to deprioritize the penalized builders instead of suspending them
+}
+
+// SentryConfig is the config of running geth as a mev sentry, which receives the
+// bids from the builders and forwards them to the upstream validator.
+type SentryConfig struct {
+	Enabled       bool
+	Upstreams     []SentryUpstreamConfig // The endpoints of the validator, tried in order on failure
+	PayBidAccount common.Address         // The unlocked account paying the builder fees on behalf of the validator
+}
+
+// SentryUpstreamConfig is an endpoint of the validator the sentry forwards the bids to.
+type SentryUpstreamConfig struct {
+	URL       string
+	JWTSecret string // The file of the hex-encoded JWT secret of the endpoint, optional
 }
 
 type MevConfig struct {
@@ -24,6 +63,15 @@ type MevConfig struct {
 	Builders              []BuilderConfig // The list of builders
 	ValidatorCommission   uint64          // 100 means the validator claims 1% from block reward
 	BidSimulationLeftOver time.Duration
+	MaxSimulatingBids     int    // The maximum number of bids of a block simulated concurrently
+	BidLogFile            string // The rotating log of the received bids, disabled if empty
+	BuilderPolicy         BuilderPolicy
+	BuilderReputationFile string  // The file persisting the builder reputations, disabled if empty
+	MaxBidsPerBlock       uint64  // The maximum number of bids per builder per block
+	BidRateLimit          float64 // The maximum number of bids per second per builder, 0 for no limit
+	BidRateBurst          int     // The burst of the bid rate limit, MaxBidsPerBlock if not set
+	BidServer             BidServerConfig
+	Sentry                SentryConfig // Run as a sentry of the upstream validator instead of simulating the bids
 }
 
 var DefaultMevConfig = MevConfig{
@@ -32,6 +80,8 @@ var DefaultMevConfig = MevConfig{
 	Builders:              nil,
 	ValidatorCommission:   100,
 	BidSimulationLeftOver: 50 * time.Millisecond,
+	MaxSimulatingBids:     defaultMaxSimulatingBids,
+	MaxBidsPerBlock:       defaultMaxBidsPerBlock,
 }
 
 // MevRunning return true if mev is running.
@@ -69,23 +119,21 @@ func (miner *Miner) SendBid(ctx context.Context, bidArgs *types.BidArgs) (common
 		return common.Hash{}, types.NewInvalidBidError("builder is not registered")
 	}
 
-	err = miner.bidSimulator.CheckPending(bidArgs.RawBid.BlockNumber, builder, bidArgs.RawBid.Hash())
+	miner.bidSimulator.notifyBidStatus(newBidStatus(builder, bidArgs.RawBid, types.BidStatusReceived), nil)
+	hash, err := miner.sendBid(ctx, builder, bidArgs)
 	if err != nil {
-		return common.Hash{}, err
-	}
-
-	signer := types.MakeSigner(miner.worker.chainConfig, big.NewInt(int64(bidArgs.RawBid.BlockNumber)), uint64(time.Now().Unix()))
-	bid, err := bidArgs.ToBid(builder, signer)
-	if err != nil {
-		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("fail to convert bidArgs to bid, %v", err))
+		miner.bidSimulator.notifyBidStatus(newBidStatus(builder, bidArgs.RawBid, types.BidStatusRejected), err)
 	}
+	return hash, err
+}
 
+// sendBid checks the bid of the registered builder against its quotas, submitting
+// the bid to the bid simulator.
+func (miner *Miner) sendBid(ctx context.Context, builder common.Address, bidArgs *types.BidArgs) (common.Hash, error) {
 	bidBetterBefore := miner.bidSimulator.bidBetterBefore(bidArgs.RawBid.ParentHash)
-	timeout := time.Until(bidBetterBefore)
-
-	if timeout <= 0 {
-		return common.Hash{}, fmt.Errorf("too late, expected befor %s, appeared %s later", bidBetterBefore,
-			common.PrettyDuration(timeout))
+	bid, err := miner.bidSimulator.admitBid(builder, bidArgs, time.Now(), bidBetterBefore)
+	if err != nil {
+		return common.Hash{}, err
 	}
 
 	err = miner.bidSimulator.sendBid(ctx, bid)
@@ -97,6 +145,43 @@ func (miner *Miner) SendBid(ctx context.Context, bidArgs *types.BidArgs) (common
 	return bid.Hash(), nil
 }
 
+// SubscribeBidStatus subscribes to the status events of the bids of the registered
+// builder, authorized by the given hash signed by the builder. The events are
+// dropped if the channel has no room for them.
+func (miner *Miner) SubscribeBidStatus(builder common.Address, auth common.Hash, ch chan<- *types.BidStatus) (event.Subscription, error) {
+	if !miner.bidSimulator.ExistBuilder(builder) {
+		return nil, types.NewInvalidBidError("builder is not registered")
+	}
+	return miner.bidSimulator.SubscribeBidStatus(builder, auth, ch)
+}
+
+// BidHistory returns the audit records of the bids received for the given block.
+func (miner *Miner) BidHistory(blockNumber uint64) ([]*types.BidRecord, error) {
+	return miner.bidSimulator.BidHistory(blockNumber)
+}
+
+// BlockDecision returns the choice between the local block and the best bid made
+// for the in-turn block with the given number, nil if unknown.
+func (miner *Miner) BlockDecision(number uint64) *types.BlockDecision {
+	return miner.worker.decisions.get(number)
+}
+
+// BuilderReputation returns the reputation of the builder.
+func (miner *Miner) BuilderReputation(builder common.Address) *types.BuilderReputation {
+	return miner.bidSimulator.BuilderReputation(builder)
+}
+
+// SuspendBuilder suspends the builder for the given duration, its bids are
+// rejected until the suspension ends or the builder is resumed.
+func (miner *Miner) SuspendBuilder(builder common.Address, duration time.Duration) {
+	miner.bidSimulator.SuspendBuilder(builder, duration)
+}
+
+// ResumeBuilder lifts the penalty of the builder and resets its reputation.
+func (miner *Miner) ResumeBuilder(builder common.Address) {
+	miner.bidSimulator.ResumeBuilder(builder)
+}
+
 func (miner *Miner) BestPackedBlockReward(parentHash common.Hash) *big.Int {
 	bidRuntime := miner.bidSimulator.GetBestBid(parentHash)
 	if bidRuntime == nil {
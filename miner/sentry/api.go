@@ -0,0 +1,97 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// API serves the mev namespace to the builders on behalf of the validator, the
+// queries are proxied to the validator as they are.
+type API struct {
+	s *Sentry
+}
+
+// NewAPI creates the mev API of the sentry.
+func NewAPI(s *Sentry) *API {
+	return &API{s}
+}
+
+// SendBid verifies the bid, attaches the payment of the builder fee and forwards
+// the bid to the validator.
+func (api *API) SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	return api.s.sendBid(ctx, args)
+}
+
+// ReportIssue forwards the issue reported by the validator to the builder.
+func (api *API) ReportIssue(ctx context.Context, issue types.BidIssue) error {
+	return api.s.reportIssue(ctx, &issue)
+}
+
+// BidStatus subscribes to the status events of the bids of the builder on the
+// validator, relaying the events as they are. A WebSocket upstream is required.
+func (api *API) BidStatus(ctx context.Context, builder common.Address, timestamp hexutil.Uint64, signature hexutil.Bytes) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	statuses := make(chan json.RawMessage, 128)
+	upstreamSub, err := api.s.subscribe(statuses, "bidStatus", builder, timestamp, signature)
+	if err != nil {
+		return &rpc.Subscription{}, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer upstreamSub.Unsubscribe()
+
+		for {
+			select {
+			case status := <-statuses:
+				notifier.Notify(rpcSub.ID, status)
+			case <-upstreamSub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Running returns true if the validator accepts bids.
+func (api *API) Running(ctx context.Context) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := api.s.call(ctx, &result, "mev_running")
+	return result, err
+}
+
+// Params returns the mev params of the validator.
+func (api *API) Params(ctx context.Context) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := api.s.call(ctx, &result, "mev_params")
+	return result, err
+}
+
+// BestBidGasFee returns the gas fee of the best bid of the validator for the given parent.
+func (api *API) BestBidGasFee(ctx context.Context, parentHash common.Hash) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := api.s.call(ctx, &result, "mev_bestBidGasFee", parentHash)
+	return result, err
+}
+
+// GetBuilderReputation returns the reputation of the builder kept by the validator.
+func (api *API) GetBuilderReputation(ctx context.Context, builder common.Address) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := api.s.call(ctx, &result, "mev_getBuilderReputation", builder)
+	return result, err
+}
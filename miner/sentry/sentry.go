@@ -0,0 +1,331 @@
+// Package sentry implements the mev sentry, which receives the bids from the
+// builders, attaches the payment of the builder fee and forwards the bids to the
+// upstream validator.
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/miner/builderclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// upstreamTimeout bounds a call to an upstream endpoint before failing over.
+const upstreamTimeout = 2 * time.Second
+
+// nonceCacheSize is the number of parent blocks the nonce of the pay bid account
+// is cached for.
+const nonceCacheSize = 16
+
+var (
+	bidForwardedCounter = metrics.NewRegisteredCounter("sentry/bid/forwarded", nil)
+	bidRejectedCounter  = metrics.NewRegisteredCounter("sentry/bid/rejected", nil)
+	failoverCounter     = metrics.NewRegisteredCounter("sentry/failover", nil)
+)
+
+// SignTxFn signs the payment of the builder fee with the pay bid account.
+type SignTxFn func(tx *types.Transaction) (*types.Transaction, error)
+
+// upstream is an endpoint of the validator.
+type upstream struct {
+	url    string
+	client *rpc.Client
+}
+
+// Sentry forwards the bids of the registered builders to the upstream validator,
+// failing over to the next endpoint if an endpoint is unreachable.
+type Sentry struct {
+	config      miner.SentryConfig
+	chainConfig *params.ChainConfig
+	signTx      SignTxFn
+
+	upstreams []*upstream
+	nonces    *lru.Cache[common.Hash, uint64] // nonce of the pay bid account by parent block
+
+	buildersMu sync.RWMutex
+	builders   map[common.Address]*builderclient.Client
+
+	mu     sync.Mutex
+	active int // index of the upstream endpoint last succeeded
+}
+
+// New creates a sentry forwarding the bids of the given builders to the upstream
+// endpoints of the validator.
+func New(config miner.SentryConfig, builders []miner.BuilderConfig, chainConfig *params.ChainConfig, signTx SignTxFn) (*Sentry, error) {
+	if len(config.Upstreams) == 0 {
+		return nil, errors.New("no upstream validator configured for the sentry")
+	}
+	s := &Sentry{
+		config:      config,
+		chainConfig: chainConfig,
+		signTx:      signTx,
+		nonces:      lru.NewCache[common.Hash, uint64](nonceCacheSize),
+		builders:    make(map[common.Address]*builderclient.Client),
+	}
+	for _, cfg := range config.Upstreams {
+		var opts []rpc.ClientOption
+		if cfg.JWTSecret != "" {
+			data, err := os.ReadFile(cfg.JWTSecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT secret of upstream %s: %v", cfg.URL, err)
+			}
+			secret := common.FromHex(strings.TrimSpace(string(data)))
+			if len(secret) != 32 {
+				return nil, fmt.Errorf("invalid JWT secret of upstream %s", cfg.URL)
+			}
+			opts = append(opts, rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(secret))))
+		}
+		client, err := rpc.DialOptions(context.Background(), cfg.URL, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial upstream %s: %v", cfg.URL, err)
+		}
+		s.upstreams = append(s.upstreams, &upstream{url: cfg.URL, client: client})
+	}
+	for _, builder := range builders {
+		var cli *builderclient.Client
+		if builder.URL != "" {
+			var err error
+			if cli, err = builderclient.DialOptions(context.Background(), builder.URL); err != nil {
+				log.Error("Sentry: failed to dial builder", "url", builder.URL, "err", err)
+			}
+		}
+		s.builders[builder.Address] = cli
+	}
+	return s, nil
+}
+
+// AddBuilder registers a builder on the sentry, url is the endpoint the issues of
+// its bids are reported to. The builder must be registered on the validator too,
+// otherwise the forwarded bids are rejected there.
+func (s *Sentry) AddBuilder(builder common.Address, url string) error {
+	var cli *builderclient.Client
+	if url != "" {
+		var err error
+		if cli, err = builderclient.DialOptions(context.Background(), url); err != nil {
+			log.Error("Sentry: failed to dial builder", "url", url, "err", err)
+			return err
+		}
+	}
+	s.buildersMu.Lock()
+	s.builders[builder] = cli
+	s.buildersMu.Unlock()
+	return nil
+}
+
+// RemoveBuilder unregisters a builder from the sentry.
+func (s *Sentry) RemoveBuilder(builder common.Address) error {
+	s.buildersMu.Lock()
+	delete(s.builders, builder)
+	s.buildersMu.Unlock()
+	return nil
+}
+
+// builder returns the client of a registered builder, nil if no url is known.
+func (s *Sentry) builder(builder common.Address) (*builderclient.Client, bool) {
+	s.buildersMu.RLock()
+	defer s.buildersMu.RUnlock()
+
+	cli, ok := s.builders[builder]
+	return cli, ok
+}
+
+// Start implements node.Lifecycle.
+func (s *Sentry) Start() error {
+	log.Info("Mev sentry started", "upstreams", len(s.upstreams), "builders", len(s.builders),
+		"payBidAccount", s.config.PayBidAccount)
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the connections to the upstream endpoints.
+func (s *Sentry) Stop() error {
+	for _, u := range s.upstreams {
+		u.client.Close()
+	}
+	log.Info("Mev sentry stopped")
+	return nil
+}
+
+// call invokes the method on the upstream endpoints, starting from the one last
+// succeeded. An error returned by the validator itself is final, only transport
+// errors fail over to the next endpoint.
+func (s *Sentry) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	s.mu.Lock()
+	start := s.active
+	s.mu.Unlock()
+
+	var err error
+	for i := 0; i < len(s.upstreams); i++ {
+		index := (start + i) % len(s.upstreams)
+		u := s.upstreams[index]
+
+		callCtx, cancel := context.WithTimeout(ctx, upstreamTimeout)
+		err = u.client.CallContext(callCtx, result, method, args...)
+		cancel()
+
+		var rpcErr rpc.Error
+		if err == nil || errors.As(err, &rpcErr) {
+			if index != start {
+				s.mu.Lock()
+				s.active = index
+				s.mu.Unlock()
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		log.Warn("Sentry: upstream unavailable, failing over", "url", u.url, "method", method, "err", err)
+		failoverCounter.Inc(1)
+	}
+	return fmt.Errorf("all upstream validators unavailable: %w", err)
+}
+
+// subscribe subscribes to the mev namespace on the upstream endpoints, starting
+// from the one last succeeded. The endpoints not serving subscriptions are skipped.
+func (s *Sentry) subscribe(ch chan<- json.RawMessage, args ...interface{}) (*rpc.ClientSubscription, error) {
+	s.mu.Lock()
+	start := s.active
+	s.mu.Unlock()
+
+	var err error
+	for i := 0; i < len(s.upstreams); i++ {
+		u := s.upstreams[(start+i)%len(s.upstreams)]
+
+		ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+		var sub *rpc.ClientSubscription
+		sub, err = u.client.Subscribe(ctx, "mev", ch, args...)
+		cancel()
+
+		var rpcErr rpc.Error
+		if err == nil || (errors.As(err, &rpcErr) && !errors.Is(err, rpc.ErrNotificationsUnsupported)) {
+			return sub, err
+		}
+		log.Debug("Sentry: upstream subscription unavailable", "url", u.url, "err", err)
+	}
+	return nil, fmt.Errorf("no upstream validator serving subscriptions: %w", err)
+}
+
+// sendBid verifies the bid of the builder, attaches the payment of the builder fee
+// and forwards the bid to the validator.
+func (s *Sentry) sendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	rawBid := args.RawBid
+	if rawBid == nil {
+		return common.Hash{}, types.NewInvalidBidError("rawBid should not be nil")
+	}
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("invalid signature:%v", err))
+	}
+	if _, ok := s.builder(builder); !ok {
+		return common.Hash{}, types.NewInvalidBidError("builder is not registered")
+	}
+	if rawBid.GasFee == nil || rawBid.GasFee.Cmp(common.Big0) == 0 || rawBid.GasUsed == 0 {
+		return common.Hash{}, types.NewInvalidBidError("empty gasFee or empty gasUsed")
+	}
+	builderFee := new(big.Int)
+	if rawBid.BuilderFee != nil {
+		if rawBid.BuilderFee.Cmp(common.Big0) < 0 {
+			return common.Hash{}, types.NewInvalidBidError("builder fee should not be less than 0")
+		}
+		if rawBid.BuilderFee.Cmp(rawBid.GasFee) >= 0 {
+			return common.Hash{}, types.NewInvalidBidError("builder fee must be less than gas fee")
+		}
+		builderFee.Set(rawBid.BuilderFee)
+	}
+	if len(args.PayBidTx) != 0 {
+		return common.Hash{}, types.NewInvalidPayBidTxError("payBidTx is attached by the sentry")
+	}
+
+	// verify the txs here so that the validator does not waste time on invalid ones
+	signer := types.MakeSigner(s.chainConfig, new(big.Int).SetUint64(rawBid.BlockNumber), uint64(time.Now().Unix()))
+	if _, err := rawBid.DecodeTxs(signer); err != nil {
+		return common.Hash{}, types.NewInvalidBidError(fmt.Sprintf("fail to decode txs, %v", err))
+	}
+
+	payBidTx, err := s.payBidTx(ctx, rawBid.ParentHash, builder, builderFee)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	args.PayBidTx, err = payBidTx.MarshalBinary()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	args.PayBidTxGasUsed = params.TxGas
+
+	var hash common.Hash
+	if err := s.call(ctx, &hash, "mev_sendBid", args); err != nil {
+		bidRejectedCounter.Inc(1)
+		log.Debug("Sentry: bid rejected", "builder", builder, "block", rawBid.BlockNumber, "err", err)
+		return common.Hash{}, err
+	}
+	bidForwardedCounter.Inc(1)
+	log.Debug("Sentry: bid forwarded", "builder", builder, "block", rawBid.BlockNumber, "hash", hash)
+	return hash, nil
+}
+
+// payBidTx creates the transaction paying the builder fee from the pay bid account,
+// executed on top of the bid in the block built by the validator.
+func (s *Sentry) payBidTx(ctx context.Context, parentHash common.Hash, builder common.Address, builderFee *big.Int) (*types.Transaction, error) {
+	nonce, err := s.payBidNonce(ctx, parentHash)
+	if err != nil {
+		return nil, err
+	}
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(0),
+		Gas:      params.PayBidTxGasLimit,
+		To:       &builder,
+		Value:    builderFee,
+	})
+	signed, err := s.signTx(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign pay bid tx: %w", err)
+	}
+	return signed, nil
+}
+
+// payBidNonce returns the nonce of the pay bid account on top of the parent block.
+// At most one bid is included per block, so the nonce is fetched from the validator
+// once per parent and shared by all the bids built on it.
+func (s *Sentry) payBidNonce(ctx context.Context, parentHash common.Hash) (uint64, error) {
+	if nonce, ok := s.nonces.Get(parentHash); ok {
+		return nonce, nil
+	}
+	var nonce hexutil.Uint64
+	block := rpc.BlockNumberOrHashWithHash(parentHash, false)
+	if err := s.call(ctx, &nonce, "eth_getTransactionCount", s.config.PayBidAccount, block); err != nil {
+		return 0, fmt.Errorf("failed to get nonce of pay bid account: %w", err)
+	}
+	s.nonces.Add(parentHash, uint64(nonce))
+	return uint64(nonce), nil
+}
+
+// reportIssue forwards the issue of a bid reported by the validator to the builder.
+func (s *Sentry) reportIssue(ctx context.Context, issue *types.BidIssue) error {
+	cli, ok := s.builder(issue.Builder)
+	if !ok {
+		return errors.New("builder is not registered")
+	}
+	if cli == nil {
+		log.Debug("Sentry: no url to report issue to builder", "builder", issue.Builder, "bidHash", issue.BidHash)
+		return nil
+	}
+	return cli.ReportIssue(ctx, issue)
+}
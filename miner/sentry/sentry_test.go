@@ -0,0 +1,159 @@
+package sentry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeValidator records the bids forwarded by the sentry.
+type fakeValidator struct {
+	bids   []types.BidArgs
+	reject bool
+}
+
+func (v *fakeValidator) SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	v.bids = append(v.bids, args)
+	if v.reject {
+		return common.Hash{}, types.ErrMevNotInTurn
+	}
+	return args.RawBid.Hash(), nil
+}
+
+// fakeEth counts the nonce queries of the sentry.
+type fakeEth struct {
+	queries int
+}
+
+func (e *fakeEth) GetTransactionCount(ctx context.Context, address common.Address, block rpc.BlockNumberOrHash) hexutil.Uint64 {
+	e.queries++
+	return 7
+}
+
+func newFakeValidator(t *testing.T) (*fakeValidator, *fakeEth, string) {
+	validator, eth := new(fakeValidator), new(fakeEth)
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("mev", validator); err != nil {
+		t.Fatalf("failed to register mev service: %v", err)
+	}
+	if err := srv.RegisterName("eth", eth); err != nil {
+		t.Fatalf("failed to register eth service: %v", err)
+	}
+	server := httptest.NewServer(srv)
+	t.Cleanup(server.Close)
+	return validator, eth, server.URL
+}
+
+func signedBid(t *testing.T, builderKey *ecdsa.PrivateKey, builderFee *big.Int) types.BidArgs {
+	userKey, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{0x1}, big.NewInt(1), params.TxGas, big.NewInt(1), nil),
+		types.LatestSigner(params.TestChainConfig), userKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	txBytes, _ := tx.MarshalBinary()
+	rawBid := &types.RawBid{
+		BlockNumber: 1,
+		ParentHash:  common.Hash{0x1},
+		Txs:         []hexutil.Bytes{txBytes},
+		GasUsed:     params.TxGas,
+		GasFee:      big.NewInt(params.GWei),
+		BuilderFee:  builderFee,
+	}
+	sig, err := crypto.Sign(rawBid.Hash().Bytes(), builderKey)
+	if err != nil {
+		t.Fatalf("failed to sign bid: %v", err)
+	}
+	return types.BidArgs{RawBid: rawBid, Signature: sig}
+}
+
+func TestSentryForwardBid(t *testing.T) {
+	var (
+		builderKey, _ = crypto.GenerateKey()
+		builder       = crypto.PubkeyToAddress(builderKey.PublicKey)
+		payKey, _     = crypto.GenerateKey()
+		payAccount    = crypto.PubkeyToAddress(payKey.PublicKey)
+		paySigner     = types.LatestSigner(params.TestChainConfig)
+		builderFee    = big.NewInt(1000)
+	)
+	validator, eth, url := newFakeValidator(t)
+
+	// The first upstream endpoint is unreachable, the bid fails over to the second one
+	s, err := New(miner.SentryConfig{
+		Enabled:       true,
+		Upstreams:     []miner.SentryUpstreamConfig{{URL: "http://127.0.0.1:1"}, {URL: url}},
+		PayBidAccount: payAccount,
+	}, []miner.BuilderConfig{{Address: builder}}, params.TestChainConfig, func(tx *types.Transaction) (*types.Transaction, error) {
+		return types.SignTx(tx, paySigner, payKey)
+	})
+	if err != nil {
+		t.Fatalf("failed to create sentry: %v", err)
+	}
+	defer s.Stop()
+
+	args := signedBid(t, builderKey, builderFee)
+	hash, err := s.sendBid(context.Background(), args)
+	if err != nil {
+		t.Fatalf("failed to send bid: %v", err)
+	}
+	if hash != args.RawBid.Hash() {
+		t.Fatalf("bid hash mismatch: have %x, want %x", hash, args.RawBid.Hash())
+	}
+	if s.active != 1 {
+		t.Fatalf("active upstream mismatch: have %d, want 1", s.active)
+	}
+	if len(validator.bids) != 1 {
+		t.Fatalf("forwarded bids mismatch: have %d, want 1", len(validator.bids))
+	}
+
+	// The payment of the builder fee is attached by the sentry
+	forwarded := validator.bids[0]
+	if forwarded.PayBidTxGasUsed != params.TxGas {
+		t.Fatalf("pay bid tx gas used mismatch: have %d, want %d", forwarded.PayBidTxGasUsed, params.TxGas)
+	}
+	payBidTx := new(types.Transaction)
+	if err := payBidTx.UnmarshalBinary(forwarded.PayBidTx); err != nil {
+		t.Fatalf("failed to decode pay bid tx: %v", err)
+	}
+	if from, _ := types.Sender(paySigner, payBidTx); from != payAccount {
+		t.Fatalf("pay bid tx sender mismatch: have %x, want %x", from, payAccount)
+	}
+	if payBidTx.Nonce() != 7 || *payBidTx.To() != builder || payBidTx.Value().Cmp(builderFee) != 0 {
+		t.Fatalf("unexpected pay bid tx: nonce %d, to %x, value %v", payBidTx.Nonce(), payBidTx.To(), payBidTx.Value())
+	}
+
+	// The errors of the validator are returned to the builder without failing over
+	validator.reject = true
+	_, err = s.sendBid(context.Background(), signedBid(t, builderKey, builderFee))
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != types.MevNotInTurnError {
+		t.Fatalf("validator error mismatch: have %v", err)
+	}
+	if len(validator.bids) != 2 || s.active != 1 {
+		t.Fatalf("validator error failed over: bids %d, active %d", len(validator.bids), s.active)
+	}
+	// The nonce of the pay bid account is queried once per parent block
+	if eth.queries != 1 {
+		t.Fatalf("nonce queries mismatch: have %d, want 1", eth.queries)
+	}
+
+	// The bids of unknown builders are rejected by the sentry
+	otherKey, _ := crypto.GenerateKey()
+	if _, err := s.sendBid(context.Background(), signedBid(t, otherKey, builderFee)); err == nil {
+		t.Fatalf("bid of unregistered builder accepted")
+	}
+	if len(validator.bids) != 2 {
+		t.Fatalf("bid of unregistered builder forwarded")
+	}
+}
@@ -93,6 +93,9 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars types.BlobSidecars
 	blobs    int
+
+	bidHash  common.Hash   // hash of the builder bid the environment is built from, empty for local work
+	accessed *bidAccessSet // state accessed by the bid the mempool txs are merged into, nil if not merging
 }
 
 // copy creates a deep copy of environment.
@@ -104,6 +107,7 @@ func (env *environment) copy() *environment {
 		coinbase: env.coinbase,
 		header:   types.CopyHeader(env.header),
 		receipts: copyReceipts(env.receipts),
+		bidHash:  env.bidHash,
 	}
 	if env.gasPool != nil {
 		gasPool := *env.gasPool
@@ -136,6 +140,8 @@ type task struct {
 	state     *state.StateDB
 	block     *types.Block
 	createdAt time.Time
+	bidHash   common.Hash          // hash of the winning builder bid, empty for local block
+	decision  *types.BlockDecision // the choice between the local work and the best bid, nil for out-turn block
 }
 
 const (
@@ -168,6 +174,7 @@ type getWorkReq struct {
 
 type bidFetcher interface {
 	GetBestBid(parentHash common.Hash) *BidRuntime
+	RecordSealedBid(bidHash common.Hash, block *types.Block)
 }
 
 // worker is the main object which takes care of submitting new work to consensus engine
@@ -239,6 +246,7 @@ type worker struct {
 	fullTaskHook      func()                             // Method to call before pushing the full sealing task.
 	resubmitHook      func(time.Duration, time.Duration) // Method to call upon updating resubmitting interval.
 	recentMinedBlocks *lru.Cache
+	decisions         *blockDecisions // decisions of the recent in-turn blocks between local work and bids
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, isLocalBlock func(header *types.Header) bool, init bool) *worker {
@@ -265,6 +273,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		exitCh:             make(chan struct{}),
 		resubmitIntervalCh: make(chan time.Duration),
 		recentMinedBlocks:  recentMinedBlocks,
+		decisions:          newBlockDecisions(),
 	}
 	// Subscribe events for blockchain
 	worker.chainHeadSub = eth.BlockChain().SubscribeChainHeadEvent(worker.chainHeadCh)
@@ -666,6 +675,13 @@ func (w *worker) resultLoop() {
 				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
 			w.mux.Post(core.NewMinedBlockEvent{Block: block})
 
+			if w.bidFetcher != nil && task.bidHash != (common.Hash{}) {
+				w.bidFetcher.RecordSealedBid(task.bidHash, block)
+			}
+			if task.decision != nil {
+				w.decisions.sealed(task.decision, block)
+			}
+
 		case <-w.exitCh:
 			return
 		}
@@ -758,11 +774,15 @@ func (w *worker) commitBlobTransaction(env *environment, tx *types.Transaction,
 // applyTransaction runs the transaction. If execution fails, state and gas pool are reverted.
 func (w *worker) applyTransaction(env *environment, tx *types.Transaction, receiptProcessors ...core.ReceiptProcessor) (*types.Receipt, error) {
 	var (
-		snap = env.state.Snapshot()
-		gp   = env.gasPool.Gas()
+		snap  = env.state.Snapshot()
+		gp    = env.gasPool.Gas()
+		check func(*state.StateDB) error
 	)
+	if env.accessed != nil {
+		check = env.accessed.check
+	}
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig(), receiptProcessors...)
+	receipt, err := core.ApplyTransactionWithCheck(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig(), check, receiptProcessors...)
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		env.gasPool.SetGas(gp)
@@ -903,6 +923,13 @@ LOOP:
 			log.Trace("Skipping transaction with low nonce", "hash", ltx.Hash, "sender", from, "nonce", tx.Nonce())
 			txs.Shift()
 
+		case errors.Is(err, errBidStateConflict):
+			// The transaction touches the state of the bid, skip the account as the
+			// subsequent transactions of the same sender depend on it
+			log.Trace("Skipping transaction conflicting with the bid", "hash", ltx.Hash, "sender", from)
+			bidMergeConflictCounter.Inc(1)
+			txs.Pop()
+
 		case errors.Is(err, nil):
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
@@ -1087,6 +1114,17 @@ func (w *worker) fillTransactions(interruptCh chan int32, env *environment, stop
 		filterBidTxs(pendingBlobTxs)
 	}
 
+	// Merge the bundles on top of the local block, not into the builder bids.
+	if bidTxs == nil {
+		minTip := new(big.Int)
+		if tip != nil {
+			minTip = tip.ToBig()
+		}
+		if err := w.commitBundles(env, minTip, pendingPlainTxs, interruptCh, stopTimer); err != nil {
+			return err
+		}
+	}
+
 	// Split the pending transactions into locals and remotes.
 	localPlainTxs, remotePlainTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingPlainTxs
 	localBlobTxs, remoteBlobTxs := make(map[common.Address][]*txpool.LazyTransaction), pendingBlobTxs
@@ -1325,9 +1363,13 @@ LOOP:
 
 	// when out-turn, use bestWork to prevent bundle leakage.
 	// when in-turn, compare with remote work.
+	var decision *types.BlockDecision
 	from := bestWork.coinbase
 	if w.bidFetcher != nil && bestWork.header.Difficulty.Cmp(diffInTurn) == 0 {
-		bestBid := w.bidFetcher.GetBestBid(bestWork.header.ParentHash)
+		var (
+			bestBid   = w.bidFetcher.GetBestBid(bestWork.header.ParentHash)
+			localWork = bestWork
+		)
 
 		if bestBid != nil {
 			log.Debug("BidSimulator: final compare", "block", bestWork.header.Number.Uint64(),
@@ -1352,11 +1394,13 @@ LOOP:
 				log.Debug("BidSimulator: bid win", "block", bestWork.header.Number.Uint64(), "bid", bestBid.bid.Hash())
 			}
 		}
+		// the env of the bid is shared with the bid simulator, keep the decision apart
+		decision = newBlockDecision(localWork, bestReward, w.config.Mev.ValidatorCommission, bestBid, bestWork != localWork)
 	}
 
 	metrics.GetOrRegisterCounter(fmt.Sprintf("block/from/%v", from), nil).Inc(1)
 
-	w.commit(bestWork, w.fullTaskHook, true, start)
+	w.commit(bestWork, decision, w.fullTaskHook, true, start)
 
 	// Swap out the old work with the new one, terminating any leftover
 	// prefetcher processes in the mean time and starting a new one.
@@ -1373,10 +1417,11 @@ func (w *worker) inTurn() bool {
 }
 
 // commit runs any post-transaction state modifications, assembles the final block
-// and commits new work if consensus engine is running.
+// and commits new work if consensus engine is running. decision is the choice made
+// between the local work and the best bid, nil for out-turn blocks.
 // Note the assumption is held that the mutation is allowed to the passed env, do
 // the deep copy first.
-func (w *worker) commit(env *environment, interval func(), update bool, start time.Time) error {
+func (w *worker) commit(env *environment, decision *types.BlockDecision, interval func(), update bool, start time.Time) error {
 	if w.isRunning() {
 		if interval != nil {
 			interval()
@@ -1418,7 +1463,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		// If we're post merge, just ignore
 		if !w.isTTDReached(block.Header()) {
 			select {
-			case w.taskCh <- &task{receipts: receipts, state: env.state, block: block, createdAt: time.Now()}:
+			case w.taskCh <- &task{receipts: receipts, state: env.state, block: block, createdAt: time.Now(), bidHash: env.bidHash, decision: decision}:
 				log.Info("Commit new sealing work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
 					"txs", env.tcount, "gas", block.GasUsed(), "fees", feesInEther, "elapsed", common.PrettyDuration(time.Since(start)))
 
@@ -0,0 +1,233 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// bundleSimulationBudget bounds the time spent simulating the bundles in a single
+// fill of the block, the rest of the sealing time is left to the mempool txs.
+const bundleSimulationBudget = 100 * time.Millisecond
+
+var (
+	bundleSimulatedCounter = metrics.NewRegisteredCounter("miner/bundle/simulated", nil)
+	bundleMergedCounter    = metrics.NewRegisteredCounter("miner/bundle/merged", nil)
+)
+
+// commitBundles merges the pending bundles into the block, in the order of their
+// price in the pool. A bundle is merged only if all of its transactions succeed,
+// except the ones allowed to revert, its effective gas price reaches the minimum
+// tip of the miner, and it pays the fee recipients at least as much as the pending
+// mempool transactions it displaces from the block. Every bundle is simulated once
+// on top of the ones merged before it, until the simulation budget is used up.
+func (w *worker) commitBundles(env *environment, minTip *big.Int, pending map[common.Address][]*txpool.LazyTransaction,
+	interruptCh chan int32, stopTimer *time.Timer) error {
+	bundles := w.eth.TxPool().PendingBundles(env.header.Number.Uint64(), env.header.Time)
+	if len(bundles) == 0 {
+		return nil
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+		env.gasPool.SubGas(params.SystemTxsGas)
+	}
+	var (
+		mempool  = newDisplacedTxs(env, pending)
+		deadline = time.Now().Add(bundleSimulationBudget)
+	)
+	for i, bundle := range bundles {
+		if interruptCh != nil {
+			select {
+			case signal := <-interruptCh:
+				return signalToErr(signal)
+			default:
+			}
+		}
+		if stopTimer != nil {
+			select {
+			case <-stopTimer.C:
+				log.Info("Not enough time for further bundles", "bundles", len(bundles)-i)
+				stopTimer.Reset(0) // re-active the timer, in case it will be used later.
+				return errBlockInterruptedByTimeout
+			default:
+			}
+		}
+		if time.Now().After(deadline) {
+			log.Debug("Bundle simulation budget used up", "skipped", len(bundles)-i)
+			break
+		}
+		if env.gasPool.Gas() < params.TxGas {
+			break
+		}
+		sim, err := w.simulateBundle(env, bundle)
+		if err == nil {
+			err = sim.profitable(minTip, mempool.value(env.gasPool.Gas(), sim.BundleGasUsed))
+		}
+		if err != nil {
+			log.Debug("Skipping bundle", "hash", bundle.Hash(), "err", err)
+			continue
+		}
+		if err := w.commitBundle(env, bundle); err != nil {
+			log.Error("Failed to commit simulated bundle", "hash", bundle.Hash(), "err", err)
+			return nil
+		}
+		bundleMergedCounter.Inc(1)
+		log.Debug("Bundle merged", "hash", bundle.Hash(), "txs", len(bundle.Txs),
+			"gasUsed", sim.BundleGasUsed, "price", sim.BundleGasPrice, "value", sim.value)
+	}
+	return nil
+}
+
+// bundleSimulation is the outcome of a bundle executed on top of a block.
+type bundleSimulation struct {
+	*types.SimulatedBundle
+	value *big.Int // balance increase of the fee recipients, direct payments to the coinbase included
+}
+
+// profitable checks the bundle pays at least minTip per gas, and at least displaced
+// to the fee recipients.
+func (sim *bundleSimulation) profitable(minTip, displaced *big.Int) error {
+	if sim.BundleGasPrice.Cmp(minTip) < 0 {
+		return fmt.Errorf("underpriced bundle: price %v, minTip %v", sim.BundleGasPrice, minTip)
+	}
+	if sim.value.Cmp(displaced) < 0 {
+		return fmt.Errorf("bundle value %v lower than displaced transactions %v", sim.value, displaced)
+	}
+	return nil
+}
+
+// simulateBundle executes the bundle on top of the environment. StateDB can't be
+// reverted across transactions, so the bundle runs on a copy of the state, while
+// the transactions and receipts of the block are not duplicated.
+func (w *worker) simulateBundle(env *environment, bundle *types.Bundle) (*bundleSimulation, error) {
+	bundleSimulatedCounter.Inc(1)
+
+	var (
+		scratch = &environment{
+			signer:   env.signer,
+			state:    env.state.Copy(),
+			tcount:   env.tcount,
+			gasPool:  new(core.GasPool).AddGas(env.gasPool.Gas()),
+			coinbase: env.coinbase,
+			header:   types.CopyHeader(env.header),
+		}
+		before  = feeRecipientsBalance(scratch)
+		gasUsed uint64
+		gasFees = new(big.Int)
+	)
+	for _, tx := range bundle.Txs {
+		if scratch.gasPool.Gas() < tx.Gas() {
+			return nil, core.ErrGasLimitReached
+		}
+		scratch.state.SetTxContext(tx.Hash(), scratch.tcount)
+
+		receipt, err := w.applyTransaction(scratch, tx)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s: %w", tx.Hash(), err)
+		}
+		if receipt.Status == types.ReceiptStatusFailed && !bundle.AllowReverting(tx.Hash()) {
+			return nil, fmt.Errorf("tx %s reverted", tx.Hash())
+		}
+		scratch.tcount++
+
+		gasUsed += receipt.GasUsed
+		gasFees.Add(gasFees, new(big.Int).Mul(tx.EffectiveGasTipValue(env.header.BaseFee), new(big.Int).SetUint64(receipt.GasUsed)))
+	}
+
+	sim := &bundleSimulation{
+		SimulatedBundle: &types.SimulatedBundle{
+			OriginalBundle: bundle,
+			BundleGasFees:  gasFees,
+			BundleGasPrice: new(big.Int),
+			BundleGasUsed:  gasUsed,
+		},
+		value: new(big.Int).Sub(feeRecipientsBalance(scratch), before),
+	}
+	if gasUsed > 0 {
+		sim.BundleGasPrice.Div(gasFees, new(big.Int).SetUint64(gasUsed))
+	}
+	return sim, nil
+}
+
+// feeRecipientsBalance returns the balance of the accounts receiving the value of
+// the block: the system address collecting the fees and the coinbase.
+func feeRecipientsBalance(env *environment) *big.Int {
+	balance := env.state.GetBalance(consensus.SystemAddress).ToBig()
+	if env.coinbase != consensus.SystemAddress {
+		balance.Add(balance, env.state.GetBalance(env.coinbase).ToBig())
+	}
+	return balance
+}
+
+// displacedTxs is the estimated packing of the pending mempool transactions, in
+// the order the block is filled with them.
+type displacedTxs struct {
+	gas  []uint64   // gas limit of the transactions
+	fees []*big.Int // fees paid to the fee recipients by the transactions
+}
+
+// newDisplacedTxs orders the pending transactions by price, up to the gas left
+// in the block.
+func newDisplacedTxs(env *environment, pending map[common.Address][]*txpool.LazyTransaction) *displacedTxs {
+	txs := make(map[common.Address][]*txpool.LazyTransaction, len(pending))
+	for addr, list := range pending {
+		txs[addr] = list
+	}
+	var (
+		ordered = newTransactionsByPriceAndNonce(env.signer, txs, env.header.BaseFee)
+		d       = new(displacedTxs)
+		total   uint64
+	)
+	for total < env.gasPool.Gas() {
+		tx, tip := ordered.Peek()
+		if tx == nil {
+			break
+		}
+		d.gas = append(d.gas, tx.Gas)
+		d.fees = append(d.fees, new(big.Int).Mul(tip.ToBig(), new(big.Int).SetUint64(tx.Gas)))
+		total += tx.Gas
+		ordered.Shift()
+	}
+	return d
+}
+
+// value returns the fees of the transactions no longer fitting into the available
+// gas of the block once a bundle using gas is merged.
+func (d *displacedTxs) value(available, gas uint64) *big.Int {
+	var (
+		value = new(big.Int)
+		total uint64
+	)
+	for i := range d.gas {
+		total += d.gas[i]
+		if total > available {
+			break // never packed anyway
+		}
+		if total+gas > available {
+			value.Add(value, d.fees[i])
+		}
+	}
+	return value
+}
+
+// commitBundle commits the transactions of the simulated bundle into the environment.
+func (w *worker) commitBundle(env *environment, bundle *types.Bundle) error {
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+
+		if _, err := w.commitTransaction(env, tx); err != nil {
+			return fmt.Errorf("tx %s: %w", tx.Hash(), err)
+		}
+		env.tcount++
+	}
+	return nil
+}
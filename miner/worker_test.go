@@ -17,6 +17,7 @@
 package miner // TOFIX
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/txpool/bundlepool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -134,7 +136,8 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 		t.Fatalf("core.NewBlockChain failed: %v", err)
 	}
 	pool := legacypool.New(testTxPoolConfig, chain)
-	txpool, _ := txpool.New(testTxPoolConfig.PriceLimit, chain, []txpool.SubPool{pool})
+	bundlePool := bundlepool.New(bundlepool.DefaultConfig, chain)
+	txpool, _ := txpool.New(testTxPoolConfig.PriceLimit, chain, []txpool.SubPool{pool, bundlePool})
 
 	return &testWorkerBackend{
 		db:      db,
@@ -411,3 +414,146 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+func TestCommitBundles(t *testing.T) {
+	t.Parallel()
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(nonce uint64, gasPrice int64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(gasPrice),
+		})
+	}
+	// The bundle pays more than the pending transaction with the same nonce
+	bundle := &types.Bundle{Txs: types.Transactions{newTx(0, 10*params.InitialBaseFee), newTx(1, 10*params.InitialBaseFee)}}
+	if err := b.txPool.AddBundle(bundle); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	// The bundle with a nonce gap fails and is never merged
+	invalid := &types.Bundle{Txs: types.Transactions{newTx(5, 20*params.InitialBaseFee)}}
+	if err := b.txPool.AddBundle(invalid); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	if err := b.txPool.AddBundle(bundle); err == nil {
+		t.Fatalf("duplicated bundle should be rejected")
+	}
+
+	r := w.getSealingBlock(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xc0ffee"), // the sender paying itself gains nothing
+		forceTime:  true,
+	})
+	if r.err != nil {
+		t.Fatalf("failed to generate block: %v", r.err)
+	}
+	txs := r.block.Transactions()
+	if len(txs) != len(bundle.Txs) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(bundle.Txs))
+	}
+	for i, tx := range bundle.Txs {
+		if txs[i].Hash() != tx.Hash() {
+			t.Fatalf("transaction %d mismatch: have %x, want %x", i, txs[i].Hash(), tx.Hash())
+		}
+	}
+}
+
+func TestCommitBundlesInterrupted(t *testing.T) {
+	t.Parallel()
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	tx := types.MustSignNewTx(testBankKey, types.LatestSigner(ethashChainConfig), &types.LegacyTx{
+		To:       &testUserAddress,
+		Value:    big.NewInt(1),
+		Gas:      params.TxGas,
+		GasPrice: big.NewInt(10 * params.InitialBaseFee),
+	})
+	if err := b.txPool.AddBundle(&types.Bundle{Txs: types.Transactions{tx}}); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+	env, err := w.prepareWork(&generateParams{
+		parentHash: b.chain.CurrentBlock().Hash(),
+		timestamp:  uint64(time.Now().Unix()),
+		coinbase:   common.HexToAddress("0xc0ffee"),
+		forceTime:  true,
+	})
+	if err != nil {
+		t.Fatalf("failed to prepare work: %v", err)
+	}
+	defer env.discard()
+
+	// No bundle is simulated once the block is interrupted or out of time
+	interruptCh := make(chan int32, 1)
+	interruptCh <- commitInterruptNewHead
+	if err := w.commitBundles(env, new(big.Int), nil, interruptCh, nil); !errors.Is(err, errBlockInterruptedByNewHead) {
+		t.Fatalf("interrupted bundles: have %v, want %v", err, errBlockInterruptedByNewHead)
+	}
+	stopTimer := time.NewTimer(0)
+	time.Sleep(10 * time.Millisecond)
+	if err := w.commitBundles(env, new(big.Int), nil, nil, stopTimer); !errors.Is(err, errBlockInterruptedByTimeout) {
+		t.Fatalf("timed out bundles: have %v, want %v", err, errBlockInterruptedByTimeout)
+	}
+	if len(env.txs) != 0 {
+		t.Fatalf("bundle merged after interruption: %d txs", len(env.txs))
+	}
+	// The timer is re-armed for the following transactions
+	select {
+	case <-stopTimer.C:
+	case <-time.After(time.Second):
+		t.Fatalf("stop timer not re-armed")
+	}
+	if err := w.commitBundles(env, new(big.Int), nil, nil, nil); err != nil {
+		t.Fatalf("failed to commit bundles: %v", err)
+	}
+	if len(env.txs) != 1 {
+		t.Fatalf("bundle not merged: %d txs", len(env.txs))
+	}
+}
+
+func TestBundleDisplacedValue(t *testing.T) {
+	// Pending transactions of 30000 gas each, paying 30, 20 and 10 per gas
+	displaced := &displacedTxs{
+		gas:  []uint64{30000, 30000, 30000},
+		fees: []*big.Int{big.NewInt(900000), big.NewInt(600000), big.NewInt(300000)},
+	}
+	for _, tt := range []struct {
+		available, gas uint64
+		want           int64
+	}{
+		{100000, 10000, 0},        // all of them still fit
+		{100000, 20000, 300000},   // the cheapest one is displaced
+		{90000, 40000, 900000},    // the two cheapest ones are displaced
+		{60000, 10000, 600000},    // the third one never fits anyway
+		{100000, 100000, 1800000}, // all of them are displaced
+	} {
+		if have := displaced.value(tt.available, tt.gas); have.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("available %d, gas %d: displaced value mismatch: have %v, want %d", tt.available, tt.gas, have, tt.want)
+		}
+	}
+	sim := &bundleSimulation{
+		SimulatedBundle: &types.SimulatedBundle{BundleGasPrice: big.NewInt(20)},
+		value:           big.NewInt(400000),
+	}
+	if err := sim.profitable(big.NewInt(10), big.NewInt(300000)); err != nil {
+		t.Errorf("profitable bundle rejected: %v", err)
+	}
+	if err := sim.profitable(big.NewInt(10), big.NewInt(600000)); err == nil {
+		t.Errorf("bundle paying less than the displaced transactions accepted")
+	}
+	if err := sim.profitable(big.NewInt(30), big.NewInt(0)); err == nil {
+		t.Errorf("underpriced bundle accepted")
+	}
+}
@@ -212,6 +212,14 @@ type Config struct {
 	// EnableDoubleSignMonitor is a flag that whether to enable the double signature checker
 	EnableDoubleSignMonitor bool `toml:",omitempty"`
 
+	// DoubleSignMonitorRetention is the number of recent heights whose headers are kept
+	// by the double sign monitor, a default is used if 0.
+	DoubleSignMonitorRetention uint64 `toml:",omitempty"`
+
+	// DoubleSignEvidenceSubmitter is the local account used to submit the evidence found
+	// by the double sign monitor to the SlashIndicator contract, disabled if empty.
+	DoubleSignEvidenceSubmitter string `toml:",omitempty"`
+
 	// EnableMaliciousVoteMonitor is a flag that whether to enable the malicious vote checker
 	EnableMaliciousVoteMonitor bool `toml:",omitempty"`
 
@@ -223,6 +231,14 @@ type Config struct {
 	// current directory.
 	BLSWalletDir string `toml:",omitempty"`
 
+	// BLSRemoteSigner is the url of a Web3Signer compatible remote signer holding
+	// the BLS key, the BLS wallet is used if empty.
+	BLSRemoteSigner string `toml:",omitempty"`
+
+	// BLSRemoteSignerPubKey is the BLS public key to sign with on the remote signer,
+	// the first key served by the remote signer is used if empty.
+	BLSRemoteSignerPubKey string `toml:",omitempty"`
+
 	// VoteJournalDir is the directory to store votes in the fast finality feature.
 	VoteJournalDir string `toml:",omitempty"`
 